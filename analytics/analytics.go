@@ -0,0 +1,112 @@
+/*
+ * package analytics sums up the hourly booking counts of the cities into the
+ * top cities, the trend and the heatmap of a window.
+ */
+
+package analytics
+
+import (
+	"sort"
+	"time"
+)
+
+//Count is the bookings of a cab type in a city in an hour.
+type Count struct {
+	CityID   string
+	CabType  string
+	Hour     time.Time //Start of the hour.
+	Bookings int
+}
+
+//Ranked ...
+type Ranked struct {
+	CityID   string
+	Bookings int
+}
+
+//Point is the bookings of a bucket of a trend.
+type Point struct {
+	At       time.Time //Start of the bucket.
+	Bookings int
+}
+
+//Total ...
+func Total(counts []*Count) int {
+	total := 0
+	for _, count := range counts {
+		total += count.Bookings
+	}
+	return total
+}
+
+//Top returns the n cities with the most bookings, the ones with the same
+//bookings by ID. Cities with no booking are left out.
+func Top(counts []*Count, n int) []*Ranked {
+	bookings := map[string]int{}
+	for _, count := range counts {
+		bookings[count.CityID] += count.Bookings
+	}
+	ranked := make([]*Ranked, 0, len(bookings))
+	for cityID, cityBookings := range bookings {
+		if cityBookings > 0 {
+			ranked = append(ranked, &Ranked{CityID: cityID, Bookings: cityBookings})
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Bookings != ranked[j].Bookings {
+			return ranked[i].Bookings > ranked[j].Bookings
+		}
+		return ranked[i].CityID < ranked[j].CityID
+	})
+	if len(ranked) > n {
+		ranked = ranked[:n]
+	}
+	return ranked
+}
+
+//Trend sums the counts from from till to in buckets of an hour, or of a day
+//in loc when daily is set. Every bucket of the window is returned, the ones
+//with no booking included.
+func Trend(counts []*Count, from, to time.Time, daily bool, loc *time.Location) []*Point {
+	points := []*Point{}
+	idx := map[int64]int{}
+	for at := bucketStart(from, daily, loc); at.Before(to); at = nextBucket(at, daily) {
+		idx[at.Unix()] = len(points)
+		points = append(points, &Point{At: at})
+	}
+	for _, count := range counts {
+		if pos, ok := idx[bucketStart(count.Hour, daily, loc).Unix()]; ok {
+			points[pos].Bookings += count.Bookings
+		}
+	}
+	return points
+}
+
+//Heatmap sums the counts by weekday, Sunday first, and hour of the day in loc.
+//The hours of a zone a fraction of an hour off UTC go to the local hour they
+//start in.
+func Heatmap(counts []*Count, loc *time.Location) [7][24]int {
+	heatmap := [7][24]int{}
+	for _, count := range counts {
+		local := count.Hour.In(loc)
+		heatmap[local.Weekday()][local.Hour()] += count.Bookings
+	}
+	return heatmap
+}
+
+//bucketStart is the start of the hour, or of the day in loc, of t.
+func bucketStart(t time.Time, daily bool, loc *time.Location) time.Time {
+	if !daily {
+		return t.Truncate(time.Hour).In(loc)
+	}
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+}
+
+//nextBucket follows the calendar for days, which are not always 24 hours long.
+func nextBucket(at time.Time, daily bool) time.Time {
+	if daily {
+		return at.AddDate(0, 0, 1)
+	}
+	return at.Add(time.Hour)
+}
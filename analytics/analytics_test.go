@@ -0,0 +1,105 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+)
+
+func hour(value string) time.Time {
+	t, _ := time.Parse(time.RFC3339, value)
+	return t
+}
+
+func testCounts() []*Count {
+	return []*Count{
+		{CityID: "city_1", CabType: "sedan", Hour: hour("2024-03-09T22:00:00Z"), Bookings: 4},
+		{CityID: "city_1", CabType: "suv", Hour: hour("2024-03-10T01:00:00Z"), Bookings: 1},
+		{CityID: "city_2", CabType: "sedan", Hour: hour("2024-03-10T01:00:00Z"), Bookings: 5},
+		{CityID: "city_3", CabType: "sedan", Hour: hour("2024-03-10T08:00:00Z"), Bookings: 2},
+		{CityID: "city_4", CabType: "sedan", Hour: hour("2024-03-10T08:00:00Z"), Bookings: 0},
+	}
+}
+
+func TestTop(t *testing.T) {
+	t.Log("TestTop")
+
+	top := Top(testCounts(), 5)
+	//city_1 and city_2 have 5 each, by ID. city_4 has none.
+	expected := []string{"city_1", "city_2", "city_3"}
+	if len(top) != len(expected) {
+		t.Fatalf("TestTop Expected %v cities. Actual: %v", len(expected), len(top))
+		return
+	}
+	for idx, ranked := range top {
+		if ranked.CityID != expected[idx] {
+			t.Fatalf("TestTop Expected %v at %v. Actual: %v", expected[idx], idx, ranked.CityID)
+			return
+		}
+	}
+	if top[0].Bookings != 5 || top[2].Bookings != 2 {
+		t.Fatalf("TestTop Expected 5 and 2 bookings. Actual: %v %v", top[0].Bookings, top[2].Bookings)
+		return
+	}
+
+	if top := Top(testCounts(), 1); len(top) != 1 || top[0].CityID != "city_1" {
+		t.Fatalf("TestTop Expected only city_1. Actual: %v", top)
+		return
+	}
+	if total := Total(testCounts()); total != 12 {
+		t.Fatalf("TestTop Expected 12 bookings in total. Actual: %v", total)
+		return
+	}
+}
+
+func TestTrend(t *testing.T) {
+	t.Log("TestTrend")
+
+	from, to := hour("2024-03-09T22:30:00Z"), hour("2024-03-10T08:30:00Z")
+	points := Trend(testCounts(), from, to, false, time.UTC)
+	expected := []int{4, 0, 0, 6, 0, 0, 0, 0, 0, 0, 2}
+	if len(points) != len(expected) {
+		t.Fatalf("TestTrend Expected %v hours. Actual: %v", len(expected), len(points))
+		return
+	}
+	for idx, point := range points {
+		if point.Bookings != expected[idx] {
+			t.Fatalf("TestTrend Expected %v bookings at %v. Actual: %v", expected[idx], point.At, point.Bookings)
+			return
+		}
+	}
+
+	//2024-03-10 is 23 hours long in New York, clocks going forward at 2 AM.
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("TestTrend No time zone data. Err: %v", err)
+		return
+	}
+	days := Trend(testCounts(), hour("2024-03-09T05:00:00Z"), hour("2024-03-11T04:00:00Z"), true, loc)
+	if len(days) != 2 || days[0].Bookings != 10 || days[1].Bookings != 2 {
+		t.Fatalf("TestTrend Expected 10 then 2 bookings. Actual: %v days %+v", len(days), days)
+		return
+	}
+	if days[1].At.Hour() != 0 || days[1].At.Day() != 10 {
+		t.Fatalf("TestTrend Expected the day to start at midnight. Actual: %v", days[1].At)
+		return
+	}
+}
+
+func TestHeatmap(t *testing.T) {
+	t.Log("TestHeatmap")
+
+	heatmap := Heatmap(testCounts(), time.UTC)
+	if heatmap[time.Saturday][22] != 4 || heatmap[time.Sunday][1] != 6 || heatmap[time.Sunday][8] != 2 {
+		t.Fatalf("TestHeatmap Unexpected UTC heatmap: %v %v %v",
+			heatmap[time.Saturday][22], heatmap[time.Sunday][1], heatmap[time.Sunday][8])
+		return
+	}
+
+	loc := time.FixedZone("IST", 5*3600+1800)
+	heatmap = Heatmap(testCounts(), loc)
+	//22:00 UTC is 03:30 on Sunday.
+	if heatmap[time.Sunday][3] != 4 || heatmap[time.Sunday][6] != 6 {
+		t.Fatalf("TestHeatmap Unexpected IST heatmap: %v %v", heatmap[time.Sunday][3], heatmap[time.Sunday][6])
+		return
+	}
+}
@@ -2,6 +2,8 @@ package db
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
@@ -17,6 +19,9 @@ const (
 
 	//RKeyName ...
 	RKeyName = "RKey"
+
+	batchRetries = 8
+	batchBackoff = 50 * time.Millisecond //Before the first retry, doubled for each next one.
 )
 
 var dbapi *dynamodb.DynamoDB
@@ -102,6 +107,23 @@ func CreateTable(tableName string, readCapacityUnits, writeCapacityUnits int64)
 	return nil
 }
 
+//EnableTTL makes the table delete the items once the unix time in attr has
+//passed. It does nothing if that is already the case.
+func EnableTTL(tableName, attr string) error {
+	_, err := dbapi.UpdateTimeToLive(&dynamodb.UpdateTimeToLiveInput{
+		TableName: aws.String(tableName),
+		TimeToLiveSpecification: &dynamodb.TimeToLiveSpecification{
+			AttributeName: aws.String(attr),
+			Enabled:       aws.Bool(true),
+		},
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "ValidationException" &&
+		strings.Contains(aerr.Message(), "already enabled") {
+		return nil
+	}
+	return err
+}
+
 //Put ...
 //TODO: Improvise to take Item only
 func Put(tableName string, item map[string]*dynamodb.AttributeValue) error {
@@ -116,6 +138,20 @@ func Put(tableName string, item map[string]*dynamodb.AttributeValue) error {
 	return nil
 }
 
+//PutIfNew stores the item only if there is none with its key yet.
+func PutIfNew(tableName string, item map[string]*dynamodb.AttributeValue) error {
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(tableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(#k)"),
+		ExpressionAttributeNames: map[string]*string{
+			"#k": aws.String(HKeyName),
+		},
+	}
+	_, err := dbapi.PutItem(input)
+	return err
+}
+
 //Get ...
 func Get(tableName string, key map[string]*dynamodb.AttributeValue) (map[string]*dynamodb.AttributeValue, error) {
 	input := &dynamodb.GetItemInput{
@@ -154,6 +190,57 @@ func Increment(tableName string, key map[string]*dynamodb.AttributeValue, attr s
 	return retVal, nil
 }
 
+//IncrementBelow increments attr by one only if it is still below limit, and
+//returns its new value. A missing attr counts as 0.
+func IncrementBelow(tableName string, key map[string]*dynamodb.AttributeValue, attr string, limit int) (int, error) {
+	input := &dynamodb.UpdateItemInput{
+		TableName:           aws.String(tableName),
+		Key:                 key,
+		UpdateExpression:    aws.String("ADD #a :one"),
+		ConditionExpression: aws.String("attribute_not_exists(#a) OR #a < :l"),
+		ExpressionAttributeNames: map[string]*string{
+			"#a": aws.String(attr),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":one": NumToAttr(1),
+			":l":   NumToAttr(limit),
+		},
+		ReturnValues: aws.String("UPDATED_NEW"),
+	}
+	updateRes, err := dbapi.UpdateItem(input)
+	if err != nil {
+		return -1, err
+	}
+	return AttrToNum(updateRes.Attributes[attr])
+}
+
+//IncrementWithin adds incrementBy to attr only if the sum stays at or below
+//limit, and returns its new value. A missing attr counts as 0.
+func IncrementWithin(tableName string, key map[string]*dynamodb.AttributeValue, attr string, incrementBy, limit int64) (int64, error) {
+	if incrementBy > limit {
+		return -1, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "increment is over the limit", nil)
+	}
+	input := &dynamodb.UpdateItemInput{
+		TableName:           aws.String(tableName),
+		Key:                 key,
+		UpdateExpression:    aws.String("ADD #a :by"),
+		ConditionExpression: aws.String("attribute_not_exists(#a) OR #a <= :l"),
+		ExpressionAttributeNames: map[string]*string{
+			"#a": aws.String(attr),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":by": Num64ToAttr(incrementBy),
+			":l":  Num64ToAttr(limit - incrementBy),
+		},
+		ReturnValues: aws.String("UPDATED_NEW"),
+	}
+	updateRes, err := dbapi.UpdateItem(input)
+	if err != nil {
+		return -1, err
+	}
+	return AttrToNum64(updateRes.Attributes[attr])
+}
+
 //Query ...
 func Query(tableName string, hkeyVal string, filter map[string]*dynamodb.Condition) (res []map[string]*dynamodb.AttributeValue, err error) {
 	keyCond := map[string]*dynamodb.Condition{
@@ -177,6 +264,95 @@ func Query(tableName string, hkeyVal string, filter map[string]*dynamodb.Conditi
 	return op.Items, nil
 }
 
+//Page is the part of a query QueryPage reads.
+type Page struct {
+	After    string   //RKey the page starts after, from the start when empty.
+	Limit    int      //Items at most, all of them when 0.
+	Backward bool     //In descending RKey order.
+	Attrs    []string //Attributes read, all when empty. RKey is always read.
+}
+
+//QueryPage is Query reading one page of the items, in RKey order. It returns
+//the RKey to read the next page after, empty when there is none.
+func QueryPage(tableName string, hkeyVal string, filter map[string]*dynamodb.Condition, page *Page) (res []map[string]*dynamodb.AttributeValue, next string, err error) {
+	keyCond := map[string]*dynamodb.Condition{
+		HKeyName: &dynamodb.Condition{
+			ComparisonOperator: aws.String("EQ"),
+			AttributeValueList: []*dynamodb.AttributeValue{StrToAttr(hkeyVal)},
+		},
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:        aws.String(tableName),
+		ConsistentRead:   aws.Bool(true),
+		KeyConditions:    keyCond,
+		QueryFilter:      filter,
+		ScanIndexForward: aws.Bool(!page.Backward),
+	}
+	if len(page.Attrs) > 0 {
+		input.Select = aws.String(dynamodb.SelectSpecificAttributes)
+		input.AttributesToGet = aws.StringSlice(append([]string{RKeyName}, page.Attrs...))
+	}
+	if page.After != "" {
+		input.ExclusiveStartKey = map[string]*dynamodb.AttributeValue{
+			HKeyName: StrToAttr(hkeyVal),
+			RKeyName: StrToAttr(page.After),
+		}
+	}
+
+	//The filter is applied after the Limit, so reads go on until the page is full.
+	for {
+		if page.Limit > 0 {
+			input.Limit = aws.Int64(int64(page.Limit - len(res)))
+		}
+		op, err := dbapi.Query(input)
+		if err != nil {
+			return nil, "", err
+		}
+		res = append(res, op.Items...)
+		if len(op.LastEvaluatedKey) == 0 {
+			return res, "", nil
+		}
+		if page.Limit > 0 && len(res) >= page.Limit {
+			return res, AttrToStr(op.LastEvaluatedKey[RKeyName]), nil
+		}
+		input.ExclusiveStartKey = op.LastEvaluatedKey
+	}
+}
+
+//QueryBetween is Query restricted to the items with RKey between from and to, both included.
+//Unlike Query it reads all the pages of the items.
+func QueryBetween(tableName string, hkeyVal, from, to string) (res []map[string]*dynamodb.AttributeValue, err error) {
+	keyCond := map[string]*dynamodb.Condition{
+		HKeyName: &dynamodb.Condition{
+			ComparisonOperator: aws.String("EQ"),
+			AttributeValueList: []*dynamodb.AttributeValue{StrToAttr(hkeyVal)},
+		},
+		RKeyName: &dynamodb.Condition{
+			ComparisonOperator: aws.String("BETWEEN"),
+			AttributeValueList: []*dynamodb.AttributeValue{StrToAttr(from), StrToAttr(to)},
+		},
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:      aws.String(tableName),
+		ConsistentRead: aws.Bool(true),
+		KeyConditions:  keyCond,
+	}
+
+	for {
+		op, err := dbapi.Query(input)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, op.Items...)
+		if len(op.LastEvaluatedKey) == 0 {
+			return res, nil
+		}
+		input.ExclusiveStartKey = op.LastEvaluatedKey
+	}
+}
+
 //Update ...
 func Update(tableName string, key map[string]*dynamodb.AttributeValue, updateInfo map[string]*dynamodb.AttributeValue) (err error) {
 	updates := make(map[string]*dynamodb.AttributeValueUpdate)
@@ -217,6 +393,12 @@ func Delete(tableName string, key, cond map[string]*dynamodb.AttributeValue) (er
 
 //UpdateExclusive ....
 func UpdateExclusive(tableName string, key map[string]*dynamodb.AttributeValue, updateInfo, cond map[string]*dynamodb.AttributeValue) (err error) {
+	return UpdateExclusiveAdding(tableName, key, updateInfo, nil, cond)
+}
+
+//UpdateExclusiveAdding is UpdateExclusive also adding adds to the attributes,
+//numbers to numbers and elements to sets.
+func UpdateExclusiveAdding(tableName string, key map[string]*dynamodb.AttributeValue, updateInfo, adds, cond map[string]*dynamodb.AttributeValue) (err error) {
 	updates := make(map[string]*dynamodb.AttributeValueUpdate)
 	for attr, attrVal := range updateInfo {
 		updates[attr] = &dynamodb.AttributeValueUpdate{
@@ -224,6 +406,12 @@ func UpdateExclusive(tableName string, key map[string]*dynamodb.AttributeValue,
 			Value:  attrVal,
 		}
 	}
+	for attr, attrVal := range adds {
+		updates[attr] = &dynamodb.AttributeValueUpdate{
+			Action: aws.String("ADD"),
+			Value:  attrVal,
+		}
+	}
 	var expected map[string]*dynamodb.ExpectedAttributeValue
 	if cond != nil {
 		expected = make(map[string]*dynamodb.ExpectedAttributeValue)
@@ -243,3 +431,297 @@ func UpdateExclusive(tableName string, key map[string]*dynamodb.AttributeValue,
 	return err
 
 }
+
+//UpdateIfNewer updates only the given attributes of an existing item, and only
+//if the number in timeAttr is older than timeVal or not set yet. It is meant
+//for frequent updates which may arrive late or out of order.
+func UpdateIfNewer(tableName string, key map[string]*dynamodb.AttributeValue, updateInfo map[string]*dynamodb.AttributeValue, timeAttr string, timeVal int64) (err error) {
+	names := map[string]*string{
+		"#k": aws.String(HKeyName),
+		"#t": aws.String(timeAttr),
+	}
+	values := map[string]*dynamodb.AttributeValue{
+		":t": Num64ToAttr(timeVal),
+	}
+	sets := make([]string, 0, len(updateInfo))
+	idx := 0
+	for attr, attrVal := range updateInfo {
+		name := fmt.Sprintf("#a%d", idx)
+		value := fmt.Sprintf(":a%d", idx)
+		names[name] = aws.String(attr)
+		values[value] = attrVal
+		sets = append(sets, name+" = "+value)
+		idx++
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(tableName),
+		Key:                       key,
+		UpdateExpression:          aws.String("SET " + strings.Join(sets, ", ")),
+		ConditionExpression:       aws.String("attribute_exists(#k) AND (attribute_not_exists(#t) OR #t < :t)"),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+	}
+	_, err = dbapi.UpdateItem(input)
+	return err
+}
+
+//UpdateIfEmpty updates an existing item only if attr is not set or is empty.
+func UpdateIfEmpty(tableName string, key map[string]*dynamodb.AttributeValue, updateInfo map[string]*dynamodb.AttributeValue, attr string) (err error) {
+	names := map[string]*string{
+		"#k": aws.String(HKeyName),
+		"#e": aws.String(attr),
+	}
+	values := map[string]*dynamodb.AttributeValue{
+		":e": StrToAttr(""),
+	}
+	sets := make([]string, 0, len(updateInfo))
+	idx := 0
+	for attr, attrVal := range updateInfo {
+		name := fmt.Sprintf("#a%d", idx)
+		value := fmt.Sprintf(":a%d", idx)
+		names[name] = aws.String(attr)
+		values[value] = attrVal
+		sets = append(sets, name+" = "+value)
+		idx++
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(tableName),
+		Key:                       key,
+		UpdateExpression:          aws.String("SET " + strings.Join(sets, ", ")),
+		ConditionExpression:       aws.String("attribute_exists(#k) AND (attribute_not_exists(#e) OR #e = :e)"),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+	}
+	_, err = dbapi.UpdateItem(input)
+	return err
+}
+
+//BatchPut writes the items, replacing the ones with the same keys. Items the
+//db leaves unprocessed are retried, backing off, batchRetries times at most.
+//On an error the items written stay written, and a *BatchPutError tells the
+//ones which were not.
+func BatchPut(tableName string, items []map[string]*dynamodb.AttributeValue) error {
+	//BatchWriteItem takes at most 25 items per call.
+	for start := 0; start < len(items); start += 25 {
+		end := start + 25
+		if end > len(items) {
+			end = len(items)
+		}
+		requests := make([]*dynamodb.WriteRequest, 0, end-start)
+		for _, item := range items[start:end] {
+			requests = append(requests, &dynamodb.WriteRequest{
+				PutRequest: &dynamodb.PutRequest{Item: item},
+			})
+		}
+		request := map[string][]*dynamodb.WriteRequest{
+			tableName: requests,
+		}
+		backoff := batchBackoff
+		for retry := 0; len(request) > 0; retry++ {
+			if retry > batchRetries {
+				err := fmt.Errorf("%v items still unprocessed after %v retries", len(request[tableName]), batchRetries)
+				return newBatchPutError(request[tableName], items[end:], err)
+			}
+			if retry > 0 {
+				time.Sleep(backoff)
+				backoff *= 2
+			}
+			op, err := dbapi.BatchWriteItem(&dynamodb.BatchWriteItemInput{RequestItems: request})
+			if err != nil {
+				return newBatchPutError(request[tableName], items[end:], err)
+			}
+			request = op.UnprocessedItems
+		}
+	}
+	return nil
+}
+
+//BatchPutError is the error of a BatchPut which could not write all the items.
+type BatchPutError struct {
+	Unwritten []map[string]*dynamodb.AttributeValue
+	Err       error
+}
+
+func (e *BatchPutError) Error() string {
+	return fmt.Sprintf("%v items not written: %v", len(e.Unwritten), e.Err)
+}
+
+//newBatchPutError is the error of the requests of a batch which failed, with
+//the items of the next batches.
+func newBatchPutError(requests []*dynamodb.WriteRequest, rest []map[string]*dynamodb.AttributeValue, err error) *BatchPutError {
+	unwritten := make([]map[string]*dynamodb.AttributeValue, 0, len(requests)+len(rest))
+	for _, request := range requests {
+		unwritten = append(unwritten, request.PutRequest.Item)
+	}
+	return &BatchPutError{Unwritten: append(unwritten, rest...), Err: err}
+}
+
+//TxWrite is a write of a Transact. It puts Put, or deletes the item of Delete,
+//or sets Updates and adds Adds to the item of Update. Cond holds the values
+//the item must have, Absent the attributes it must not have and Empty the
+//ones it must not have but as empty strings. New requires that there is no
+//item with the key yet.
+type TxWrite struct {
+	Put     map[string]*dynamodb.AttributeValue
+	Delete  map[string]*dynamodb.AttributeValue
+	Update  map[string]*dynamodb.AttributeValue
+	Updates map[string]*dynamodb.AttributeValue
+	Adds    map[string]*dynamodb.AttributeValue
+	Cond    map[string]*dynamodb.AttributeValue
+	Absent  []string
+	Empty   []string
+	New     bool
+}
+
+//Transact runs the writes, at most 25, all or none. IsConditionFailed tells
+//when it failed on one of their conditions.
+func Transact(tableName string, writes []*TxWrite) error {
+	items := make([]*dynamodb.TransactWriteItem, 0, len(writes))
+	for _, write := range writes {
+		expr := &txExpr{names: map[string]*string{}, values: map[string]*dynamodb.AttributeValue{}}
+		conds := []string{}
+		if write.New {
+			expr.names["#k"] = aws.String(HKeyName)
+			conds = append(conds, "attribute_not_exists(#k)")
+		}
+		for attr, attrVal := range write.Cond {
+			conds = append(conds, expr.add(attr, attrVal, " = "))
+		}
+		for idx, attr := range write.Absent {
+			name := fmt.Sprintf("#n%d", idx)
+			expr.names[name] = aws.String(attr)
+			conds = append(conds, "attribute_not_exists("+name+")")
+		}
+		for idx, attr := range write.Empty {
+			name, value := fmt.Sprintf("#m%d", idx), fmt.Sprintf(":m%d", idx)
+			expr.names[name] = aws.String(attr)
+			expr.values[value] = StrToAttr("")
+			conds = append(conds, "(attribute_not_exists("+name+") OR "+name+" = "+value+")")
+		}
+		var cond *string
+		if len(conds) > 0 {
+			cond = aws.String(strings.Join(conds, " AND "))
+		}
+
+		item := &dynamodb.TransactWriteItem{}
+		switch {
+		case write.Put != nil:
+			item.Put = &dynamodb.Put{
+				TableName:                 aws.String(tableName),
+				Item:                      write.Put,
+				ConditionExpression:       cond,
+				ExpressionAttributeNames:  expr.namesOrNil(),
+				ExpressionAttributeValues: expr.valuesOrNil(),
+			}
+		case write.Delete != nil:
+			item.Delete = &dynamodb.Delete{
+				TableName:                 aws.String(tableName),
+				Key:                       write.Delete,
+				ConditionExpression:       cond,
+				ExpressionAttributeNames:  expr.namesOrNil(),
+				ExpressionAttributeValues: expr.valuesOrNil(),
+			}
+		default:
+			sets := []string{}
+			for attr, attrVal := range write.Updates {
+				sets = append(sets, expr.add(attr, attrVal, " = "))
+			}
+			adds := []string{}
+			for attr, attrVal := range write.Adds {
+				adds = append(adds, expr.add(attr, attrVal, " "))
+			}
+			update := []string{}
+			if len(sets) > 0 {
+				update = append(update, "SET "+strings.Join(sets, ", "))
+			}
+			if len(adds) > 0 {
+				update = append(update, "ADD "+strings.Join(adds, ", "))
+			}
+			item.Update = &dynamodb.Update{
+				TableName:                 aws.String(tableName),
+				Key:                       write.Update,
+				UpdateExpression:          aws.String(strings.Join(update, " ")),
+				ConditionExpression:       cond,
+				ExpressionAttributeNames:  expr.namesOrNil(),
+				ExpressionAttributeValues: expr.valuesOrNil(),
+			}
+		}
+		items = append(items, item)
+	}
+
+	_, err := dbapi.TransactWriteItems(&dynamodb.TransactWriteItemsInput{TransactItems: items})
+	return err
+}
+
+//txExpr gathers the placeholders of the expressions of a TxWrite.
+type txExpr struct {
+	names  map[string]*string
+	values map[string]*dynamodb.AttributeValue
+}
+
+//add returns "#aN<op>:aN" for attr and its value.
+func (expr *txExpr) add(attr string, attrVal *dynamodb.AttributeValue, op string) string {
+	idx := len(expr.values)
+	name := fmt.Sprintf("#a%d", idx)
+	value := fmt.Sprintf(":a%d", idx)
+	expr.names[name] = aws.String(attr)
+	expr.values[value] = attrVal
+	return name + op + value
+}
+
+//namesOrNil ...
+func (expr *txExpr) namesOrNil() map[string]*string {
+	if len(expr.names) == 0 {
+		return nil
+	}
+	return expr.names
+}
+
+//valuesOrNil ...
+func (expr *txExpr) valuesOrNil() map[string]*dynamodb.AttributeValue {
+	if len(expr.values) == 0 {
+		return nil
+	}
+	return expr.values
+}
+
+//BatchGet reads the items of keys, with only the attrs in projection, all of
+//them when it is empty. Keys which are not found are missing from the result.
+func BatchGet(tableName string, keys []map[string]*dynamodb.AttributeValue, projection []string) (res []map[string]*dynamodb.AttributeValue, err error) {
+	names := map[string]*string{}
+	projected := make([]string, 0, len(projection))
+	for idx, attr := range projection {
+		name := fmt.Sprintf("#p%d", idx)
+		names[name] = aws.String(attr)
+		projected = append(projected, name)
+	}
+
+	//BatchGetItem takes at most 100 keys per call.
+	for start := 0; start < len(keys); start += 100 {
+		end := start + 100
+		if end > len(keys) {
+			end = len(keys)
+		}
+		request := map[string]*dynamodb.KeysAndAttributes{
+			tableName: &dynamodb.KeysAndAttributes{
+				Keys:           keys[start:end],
+				ConsistentRead: aws.Bool(true),
+			},
+		}
+		if len(projected) > 0 {
+			request[tableName].ProjectionExpression = aws.String(strings.Join(projected, ", "))
+			request[tableName].ExpressionAttributeNames = names
+		}
+		for len(request) > 0 {
+			op, err := dbapi.BatchGetItem(&dynamodb.BatchGetItemInput{RequestItems: request})
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, op.Responses[tableName]...)
+			request = op.UnprocessedKeys
+		}
+	}
+	return res, nil
+}
@@ -4,6 +4,7 @@ import (
 	"strconv"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 )
 
@@ -47,3 +48,38 @@ func StrSetToAttr(val []string) *dynamodb.AttributeValue {
 func AttrToStrSet(attrVal *dynamodb.AttributeValue) []string {
 	return aws.StringValueSlice(attrVal.SS)
 }
+
+//IsConditionFailed reports whether err is the failure of a conditional write
+//such as UpdateExclusive or a Delete with cond, or of a condition of Transact.
+func IsConditionFailed(err error) bool {
+	if cancelled, ok := err.(*dynamodb.TransactionCanceledException); ok {
+		for _, reason := range cancelled.CancellationReasons {
+			if aws.StringValue(reason.Code) == "ConditionalCheckFailed" {
+				return true
+			}
+		}
+		return false
+	}
+	aerr, ok := err.(awserr.Error)
+	return ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException
+}
+
+//FloatToAttr ...
+func FloatToAttr(val float64) *dynamodb.AttributeValue {
+	return &dynamodb.AttributeValue{N: aws.String(strconv.FormatFloat(val, 'f', -1, 64))}
+}
+
+//AttrToFloat ...
+func AttrToFloat(attrVal *dynamodb.AttributeValue) (float64, error) {
+	return strconv.ParseFloat(*attrVal.N, 64)
+}
+
+//BoolToAttr ...
+func BoolToAttr(val bool) *dynamodb.AttributeValue {
+	return &dynamodb.AttributeValue{BOOL: aws.Bool(val)}
+}
+
+//AttrToBool ...
+func AttrToBool(attrVal *dynamodb.AttributeValue) bool {
+	return aws.BoolValue(attrVal.BOOL)
+}
@@ -0,0 +1,127 @@
+/*
+ * package fare computes what a trip costs from the tariff of its city and cab type.
+ * All the amounts are in the minor units of the currency (paise, cents).
+ */
+
+package fare
+
+import (
+	"math"
+	"time"
+)
+
+//Tariff ...
+type Tariff struct {
+	Currency    string
+	BaseFare    int64
+	PerKm       int64
+	PerMinute   int64
+	MinimumFare int64            //Floor of the base, distance and time charges.
+	InterCity   int64            //Surcharge for a trip ending in another city.
+	InterCityTo map[string]int64 //Surcharge per destination city, overriding InterCity.
+	TaxName     string           //Tax included in the fares, ex: GST.
+	TaxRate     float64          //0.05 for 5%.
+	CancelFee   int64            //Charged to a rider cancelling late, none when 0.
+}
+
+//Trip is what the fare is charged on.
+type Trip struct {
+	From     string
+	To       string
+	Meters   float64
+	Duration time.Duration
+	Surge    float64 //Multiplier locked at booking, none when below 1.
+	Discount *Discount
+}
+
+//Discount of a promo code, either Percent of the fare up to MaxOff or Flat.
+type Discount struct {
+	Code    string
+	Percent float64 //10 for 10% off.
+	MaxOff  int64   //Cap of a Percent discount, none when 0.
+	Flat    int64
+}
+
+//Fare is the breakdown of the charges, Total being their sum.
+type Fare struct {
+	Currency   string
+	Base       int64
+	Distance   int64
+	Time       int64
+	Minimum    int64 //Added to reach MinimumFare.
+	Surge      int64 //Added by the surge multiplier, the inter-city surcharge is not surged.
+	Multiplier float64
+	InterCity  int64
+	Discount   int64 //Taken off by the promo code.
+	PromoCode  string
+	Total      int64
+}
+
+//Compute returns the fare of the trip.
+func (t *Tariff) Compute(trip *Trip) *Fare {
+	f := &Fare{
+		Currency:  t.Currency,
+		Base:      t.BaseFare,
+		Distance:  round(float64(t.PerKm) * trip.Meters / 1000),
+		Time:      round(float64(t.PerMinute) * trip.Duration.Minutes()),
+		InterCity: t.Surcharge(trip.From, trip.To),
+	}
+	subTotal := f.Base + f.Distance + f.Time
+	if subTotal < t.MinimumFare {
+		f.Minimum = t.MinimumFare - subTotal
+		subTotal = t.MinimumFare
+	}
+	f.Multiplier = 1
+	if trip.Surge > 1 {
+		f.Multiplier = trip.Surge
+		f.Surge = round(float64(subTotal) * (trip.Surge - 1))
+	}
+	f.Total = subTotal + f.Surge + f.InterCity
+	if trip.Discount != nil {
+		f.PromoCode = trip.Discount.Code
+		f.Discount = trip.Discount.Off(f.Total)
+		f.Total -= f.Discount
+	}
+	return f
+}
+
+//Off is what the discount takes off total, never more than total.
+func (d *Discount) Off(total int64) int64 {
+	off := d.Flat
+	if d.Percent > 0 {
+		off = round(float64(total) * d.Percent / 100)
+		if d.MaxOff > 0 && off > d.MaxOff {
+			off = d.MaxOff
+		}
+	}
+	if off > total {
+		off = total
+	}
+	if off < 0 {
+		off = 0
+	}
+	return off
+}
+
+//Surcharge returns the inter-city surcharge from city from to city to.
+func (t *Tariff) Surcharge(from, to string) int64 {
+	if to == "" || to == from {
+		return 0
+	}
+	if surcharge, ok := t.InterCityTo[to]; ok {
+		return surcharge
+	}
+	return t.InterCity
+}
+
+//IncludedTax is the part of total which is tax at rate, total including it.
+func IncludedTax(total int64, rate float64) int64 {
+	if rate <= 0 {
+		return 0
+	}
+	return total - round(float64(total)/(1+rate))
+}
+
+func round(amount float64) int64 {
+	return int64(math.Round(amount))
+}
@@ -0,0 +1,109 @@
+package fare
+
+import (
+	"testing"
+	"time"
+)
+
+func testTariff() *Tariff {
+	return &Tariff{
+		Currency:    "INR",
+		BaseFare:    5000,
+		PerKm:       1200,
+		PerMinute:   150,
+		MinimumFare: 8000,
+		InterCity:   30000,
+		InterCityTo: map[string]int64{"city_3": 50000},
+	}
+}
+
+func TestCompute(t *testing.T) {
+	t.Log("TestCompute")
+
+	trip := &Trip{From: "city_1", To: "city_1", Meters: 10500, Duration: 20 * time.Minute}
+	f := testTariff().Compute(trip)
+	//5000 + 10.5 * 1200 + 20 * 150
+	if f.Distance != 12600 || f.Time != 3000 || f.Minimum != 0 || f.InterCity != 0 {
+		t.Fatalf("TestCompute Unexpected breakdown: %+v", f)
+		return
+	}
+	if f.Total != 20600 {
+		t.Fatalf("TestCompute Expected: 20600. Actual: %v", f.Total)
+		return
+	}
+}
+
+func TestComputeMinimum(t *testing.T) {
+	t.Log("TestComputeMinimum")
+
+	trip := &Trip{From: "city_1", To: "city_1", Meters: 1000, Duration: 2 * time.Minute}
+	f := testTariff().Compute(trip)
+	if f.Minimum != 1500 || f.Total != 8000 {
+		t.Fatalf("TestComputeMinimum Expected minimum 1500 and total 8000. Actual: %+v", f)
+		return
+	}
+}
+
+func TestComputeSurge(t *testing.T) {
+	t.Log("TestComputeSurge")
+
+	trip := &Trip{From: "city_1", To: "city_2", Meters: 10500, Duration: 20 * time.Minute, Surge: 1.5}
+	f := testTariff().Compute(trip)
+	//Half of 20600 on top, the inter-city surcharge as is.
+	if f.Surge != 10300 || f.Total != 20600+10300+30000 {
+		t.Fatalf("TestComputeSurge Unexpected breakdown: %+v", f)
+		return
+	}
+}
+
+func TestSurcharge(t *testing.T) {
+	t.Log("TestSurcharge")
+
+	tariff := testTariff()
+	if s := tariff.Surcharge("city_1", "city_2"); s != 30000 {
+		t.Fatalf("TestSurcharge Expected: 30000. Actual: %v", s)
+		return
+	}
+	if s := tariff.Surcharge("city_1", "city_3"); s != 50000 {
+		t.Fatalf("TestSurcharge Expected: 50000. Actual: %v", s)
+		return
+	}
+	if s := tariff.Surcharge("city_1", "city_1"); s != 0 {
+		t.Fatalf("TestSurcharge Expected: 0. Actual: %v", s)
+		return
+	}
+}
+
+func TestIncludedTax(t *testing.T) {
+	t.Log("TestIncludedTax")
+
+	//21000 is 20000 plus 5%.
+	if tax := IncludedTax(21000, 0.05); tax != 1000 {
+		t.Fatalf("TestIncludedTax Expected: 1000. Actual: %v", tax)
+		return
+	}
+	if tax := IncludedTax(21000, 0); tax != 0 {
+		t.Fatalf("TestIncludedTax Expected no tax. Actual: %v", tax)
+		return
+	}
+}
+
+func TestComputeDiscount(t *testing.T) {
+	t.Log("TestComputeDiscount")
+
+	trip := &Trip{From: "city_1", To: "city_2", Meters: 10500, Duration: 20 * time.Minute,
+		Discount: &Discount{Code: "LAUNCH", Percent: 10, MaxOff: 4000}}
+	f := testTariff().Compute(trip)
+	//10% of 50600, capped at 4000.
+	if f.Discount != 4000 || f.Total != 46600 || f.PromoCode != "LAUNCH" {
+		t.Fatalf("TestComputeDiscount Unexpected breakdown: %+v", f)
+		return
+	}
+
+	trip.Discount = &Discount{Code: "FREE", Flat: 100000}
+	f = testTariff().Compute(trip)
+	if f.Discount != 50600 || f.Total != 0 {
+		t.Fatalf("TestComputeDiscount Expected a free trip. Actual: %+v", f)
+		return
+	}
+}
@@ -0,0 +1,164 @@
+/*
+ * package geo has the location helpers used for dispatching cabs by distance.
+ */
+
+package geo
+
+import (
+	"math"
+)
+
+const (
+	base32      = "0123456789bcdefghjkmnpqrstuvwxyz"
+	earthRadius = 6371000.0 //In meters.
+)
+
+//Encode returns the geohash of the point with precision characters. Points
+//sharing a geohash prefix are close to each other.
+func Encode(lat, lon float64, precision int) string {
+	latRange := [2]float64{-90, 90}
+	lonRange := [2]float64{-180, 180}
+
+	hash := make([]byte, 0, precision)
+	bit, ch := 0, 0
+	evenBit := true
+	for len(hash) < precision {
+		if evenBit {
+			mid := (lonRange[0] + lonRange[1]) / 2
+			if lon >= mid {
+				ch |= 1 << uint(4-bit)
+				lonRange[0] = mid
+			} else {
+				lonRange[1] = mid
+			}
+		} else {
+			mid := (latRange[0] + latRange[1]) / 2
+			if lat >= mid {
+				ch |= 1 << uint(4-bit)
+				latRange[0] = mid
+			} else {
+				latRange[1] = mid
+			}
+		}
+		evenBit = !evenBit
+
+		if bit < 4 {
+			bit++
+		} else {
+			hash = append(hash, base32[ch])
+			bit, ch = 0, 0
+		}
+	}
+	return string(hash)
+}
+
+//Bounds returns the south west and north east corners of the geohash cell.
+func Bounds(hash string) (minLat, minLon, maxLat, maxLon float64) {
+	minLat, maxLat = -90, 90
+	minLon, maxLon = -180, 180
+
+	evenBit := true
+	for i := 0; i < len(hash); i++ {
+		ch := indexOf(hash[i])
+		for bit := 4; bit >= 0; bit-- {
+			set := ch&(1<<uint(bit)) != 0
+			if evenBit {
+				mid := (minLon + maxLon) / 2
+				if set {
+					minLon = mid
+				} else {
+					maxLon = mid
+				}
+			} else {
+				mid := (minLat + maxLat) / 2
+				if set {
+					minLat = mid
+				} else {
+					maxLat = mid
+				}
+			}
+			evenBit = !evenBit
+		}
+	}
+	return minLat, minLon, maxLat, maxLon
+}
+
+//Neighbours returns the geohash cell and the eight cells around it.
+func Neighbours(hash string) []string {
+	minLat, minLon, maxLat, maxLon := Bounds(hash)
+	latStep := maxLat - minLat
+	lonStep := maxLon - minLon
+	centerLat := (minLat + maxLat) / 2
+	centerLon := (minLon + maxLon) / 2
+
+	cells := make([]string, 0, 9)
+	seen := map[string]bool{}
+	for i := -1; i <= 1; i++ {
+		lat := centerLat + float64(i)*latStep
+		if lat > 90 || lat < -90 {
+			continue
+		}
+		for j := -1; j <= 1; j++ {
+			lon := centerLon + float64(j)*lonStep
+			if lon > 180 {
+				lon -= 360
+			} else if lon < -180 {
+				lon += 360
+			}
+			cell := Encode(lat, lon, len(hash))
+			if !seen[cell] {
+				seen[cell] = true
+				cells = append(cells, cell)
+			}
+		}
+	}
+	return cells
+}
+
+//Distance returns the great circle distance between two points in meters.
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	dPhi := (lat2 - lat1) * math.Pi / 180
+	dLambda := (lon2 - lon1) * math.Pi / 180
+
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
+
+//ValidPoint ...
+func ValidPoint(lat, lon float64) bool {
+	return lat >= -90 && lat <= 90 && lon >= -180 && lon <= 180
+}
+
+//Point ...
+type Point struct {
+	Lat float64
+	Lon float64
+}
+
+//InPolygon tells if the point is inside the polygon, given by its vertices in
+//order. Points on the edges may fall either way. The polygon is taken as flat,
+//which is close enough for a city.
+func InPolygon(lat, lon float64, polygon []Point) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		//Counts the edges crossed by a ray going east from the point.
+		if (a.Lat > lat) != (b.Lat > lat) &&
+			lon < (b.Lon-a.Lon)*(lat-a.Lat)/(b.Lat-a.Lat)+a.Lon {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func indexOf(ch byte) int {
+	for i := 0; i < len(base32); i++ {
+		if base32[i] == ch {
+			return i
+		}
+	}
+	return 0
+}
@@ -0,0 +1,98 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	t.Log("TestEncode")
+
+	hash := Encode(57.64911, 10.40744, 11)
+	if hash != "u4pruydqqvj" {
+		t.Fatalf("TestEncode Expected: u4pruydqqvj. Actual: %v", hash)
+		return
+	}
+}
+
+func TestBounds(t *testing.T) {
+	t.Log("TestBounds")
+
+	minLat, minLon, maxLat, maxLon := Bounds("u4pruydqqvj")
+	if minLat > 57.64911 || maxLat < 57.64911 || minLon > 10.40744 || maxLon < 10.40744 {
+		t.Fatalf("TestBounds Point outside its cell: %v %v %v %v", minLat, minLon, maxLat, maxLon)
+		return
+	}
+}
+
+func TestNeighbours(t *testing.T) {
+	t.Log("TestNeighbours")
+
+	hash := Encode(12.9716, 77.5946, 5)
+	cells := Neighbours(hash)
+	if len(cells) != 9 {
+		t.Fatalf("TestNeighbours Expected: 9 cells. Actual: %v", len(cells))
+		return
+	}
+	if cells[4] != hash {
+		t.Fatalf("TestNeighbours Expected center: %v. Actual: %v", hash, cells[4])
+		return
+	}
+
+	//A point just across the cell edge must be in one of the neighbours.
+	_, _, maxLat, _ := Bounds(hash)
+	across := Encode(maxLat+0.001, 77.5946, 5)
+	found := false
+	for _, cell := range cells {
+		if cell == across {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("TestNeighbours %v not found in %v", across, cells)
+		return
+	}
+}
+
+func TestDistance(t *testing.T) {
+	t.Log("TestDistance")
+
+	//One degree of longitude at the equator.
+	dist := Distance(0, 0, 0, 1)
+	if math.Abs(dist-111195) > 10 {
+		t.Fatalf("TestDistance Expected: ~111195. Actual: %v", dist)
+		return
+	}
+	if Distance(12.9716, 77.5946, 12.9716, 77.5946) != 0 {
+		t.Fatalf("TestDistance Expected 0 for the same point")
+		return
+	}
+}
+
+func TestInPolygon(t *testing.T) {
+	t.Log("TestInPolygon")
+
+	//An L shape, the square from (1, 1) to (2, 2) cut out.
+	polygon := []Point{{0, 0}, {0, 2}, {1, 2}, {1, 1}, {2, 1}, {2, 0}}
+	tests := []struct {
+		lat, lon float64
+		inside   bool
+	}{
+		{0.5, 0.5, true},
+		{0.5, 1.5, true},
+		{1.5, 0.5, true},
+		{1.5, 1.5, false},
+		{3, 3, false},
+		{-0.5, 0.5, false},
+	}
+	for _, test := range tests {
+		if InPolygon(test.lat, test.lon, polygon) != test.inside {
+			t.Fatalf("TestInPolygon (%v, %v) Expected inside: %v", test.lat, test.lon, test.inside)
+			return
+		}
+	}
+	if InPolygon(0.5, 0.5, nil) {
+		t.Fatalf("TestInPolygon Expected nothing inside an empty polygon")
+		return
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"mycabs/mycabsservice"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//runImport is the import command, which imports a fleet file straight into
+//the store, ex: mycabs import -dryrun cabs.csv
+//It returns the exit code, 1 when some rows were not imported.
+func runImport(args []string) int {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := flags.String("format", "", "csv or jsonl, by the file extension when not given")
+	dryRun := flags.Bool("dryrun", false, "check the rows without importing them")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %v import [-format csv|jsonl] [-dryrun] <file>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	path := flags.Arg(0)
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+		if *format == "json" {
+			*format = "jsonl"
+		}
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import: %v\n", err)
+		return 1
+	}
+	defer file.Close()
+
+	//The service logs to stdout, it goes to stderr so the report is alone there.
+	reportOut := os.Stdout
+	os.Stdout = os.Stderr
+	report, err := mycabsservice.ImportFleet(file, *format, *dryRun)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import: %v\n", err)
+		return 1
+	}
+	out, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Fprintln(reportOut, string(out))
+	if report.Failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -13,6 +13,9 @@ const (
 	renewInterval = 90
 )
 
+//ErrBusy is returned by Load when the record is leased by someone else.
+var ErrBusy = errors.New("lease.Load: Record Busy")
+
 //Lease ...
 type Lease struct {
 	tableName string
@@ -36,7 +39,7 @@ func Load(tableName string, key map[string]*dynamodb.AttributeValue) (ls *Lease,
 	curTime := time.Now().Unix()
 
 	if curTime-leaseTime <= minGap {
-		return nil, errors.New("lease.Load: Record Busy")
+		return nil, ErrBusy
 	}
 
 	updateInfo := map[string]*dynamodb.AttributeValue{
@@ -47,6 +50,10 @@ func Load(tableName string, key map[string]*dynamodb.AttributeValue) (ls *Lease,
 	}
 
 	err = db.UpdateExclusive(tableName, key, updateInfo, cond)
+	if db.IsConditionFailed(err) {
+		//Taken by someone else since it was read.
+		return nil, ErrBusy
+	}
 	if err != nil {
 		return nil, err
 	}
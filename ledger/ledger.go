@@ -0,0 +1,158 @@
+/*
+ * package ledger builds the double-entry journals recording the money of the trips.
+ * A journal moves money between accounts in one currency: its postings debit
+ * (positive amounts) and credit (negative amounts) the accounts and always sum
+ * up to zero. Journals are never changed, a mistake is undone by a new journal.
+ * All the amounts are in the minor units of the currency (paise, cents).
+ */
+
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+//Journal kinds.
+const (
+	KindCharge  = "CHARGE"  //Fare owed by the rider, shared by the driver and the platform.
+	KindPayment = "PAYMENT" //Fare paid by the rider, to the provider or in cash to the driver.
+	KindPayout  = "PAYOUT"  //Share of the driver paid out through the provider.
+	KindRefund  = "REFUND"  //Part of a charge given back to the rider.
+)
+
+//CommissionAccount is the account of the platform earnings.
+const CommissionAccount = "platform/commission"
+
+//Posting debits the account by Amount, or credits it when Amount is negative.
+type Posting struct {
+	Account string
+	Amount  int64
+}
+
+//Journal ...
+type Journal struct {
+	ID       string
+	Kind     string
+	TripID   string
+	Currency string
+	Postings []Posting
+	Ref      string //Transaction of the payment provider, if any.
+	Memo     string //Why the journal was posted, if told.
+	Time     int64  //Unix time.
+}
+
+//RiderAccount ...
+func RiderAccount(riderID string) string {
+	return "rider/" + riderID
+}
+
+//DriverAccount ...
+func DriverAccount(driverID string) string {
+	return "driver/" + driverID
+}
+
+//CorporateAccount is billed for the trips of its members, it is paid on the
+//monthly statement.
+func CorporateAccount(corporateID string) string {
+	return "corporate/" + corporateID
+}
+
+//ProviderAccount is the money held at a payment provider.
+func ProviderAccount(provider string) string {
+	return "provider/" + provider
+}
+
+//Validate checks that the journal balances.
+func (j *Journal) Validate() error {
+	if j.Currency == "" {
+		return errors.New("ledger: journal without currency")
+	}
+	if len(j.Postings) < 2 {
+		return errors.New("ledger: journal needs two postings at least")
+	}
+	sum := int64(0)
+	for _, p := range j.Postings {
+		if p.Account == "" || p.Amount == 0 {
+			return fmt.Errorf("ledger: invalid posting %+v", p)
+		}
+		sum += p.Amount
+	}
+	if sum != 0 {
+		return fmt.Errorf("ledger: journal is off balance by %v", sum)
+	}
+	return nil
+}
+
+//Commission is the platform share of total at rate, rounded to the nearest unit.
+func Commission(total int64, rate float64) int64 {
+	return int64(math.Round(float64(total) * rate))
+}
+
+//Charge records the fare the rider owes: the driver earns it but the commission.
+func Charge(rider, driver string, total, commission int64) []Posting {
+	postings := []Posting{
+		{Account: rider, Amount: total},
+		{Account: driver, Amount: commission - total},
+		{Account: CommissionAccount, Amount: -commission},
+	}
+	return dropEmpty(postings)
+}
+
+//Payment records amount paid by the rider into the payee account, the
+//provider or, for cash, the driver.
+func Payment(rider, payee string, amount int64) []Posting {
+	return []Posting{
+		{Account: payee, Amount: amount},
+		{Account: rider, Amount: -amount},
+	}
+}
+
+//Payout records amount paid out to the driver from the provider.
+func Payout(driver, provider string, amount int64) []Posting {
+	return []Posting{
+		{Account: driver, Amount: amount},
+		{Account: provider, Amount: -amount},
+	}
+}
+
+//Refund takes back amount of a charge of total with the commission, from the
+//driver and the platform in proportion of their shares.
+func Refund(rider, driver string, amount, total, commission int64) []Posting {
+	fromPlatform := int64(0)
+	if total > 0 {
+		fromPlatform = int64(math.Round(float64(amount) * float64(commission) / float64(total)))
+	}
+	postings := []Posting{
+		{Account: driver, Amount: amount - fromPlatform},
+		{Account: CommissionAccount, Amount: fromPlatform},
+		{Account: rider, Amount: -amount},
+	}
+	return dropEmpty(postings)
+}
+
+//Balances sums up the postings of the journals per account and currency.
+func Balances(journals []*Journal) map[string]map[string]int64 {
+	balances := map[string]map[string]int64{}
+	for _, j := range journals {
+		for _, p := range j.Postings {
+			if _, ok := balances[p.Account]; !ok {
+				balances[p.Account] = map[string]int64{}
+			}
+			balances[p.Account][j.Currency] += p.Amount
+		}
+	}
+	return balances
+}
+
+//dropEmpty leaves out the postings of no amount.
+func dropEmpty(postings []Posting) []Posting {
+	kept := postings[:0]
+	for _, p := range postings {
+		if p.Amount != 0 {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,93 @@
+package ledger
+
+import (
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	t.Log("TestValidate")
+
+	j := &Journal{Currency: "INR", Postings: Charge("rider/rider_1", "driver/driver_1", 20600, 4120)}
+	if err := j.Validate(); err != nil {
+		t.Fatalf("TestValidate Failed. Err: %v", err)
+		return
+	}
+
+	j.Postings[0].Amount++
+	if err := j.Validate(); err == nil {
+		t.Fatalf("TestValidate Expected an off balance journal to fail")
+		return
+	}
+
+	j = &Journal{Currency: "INR", Postings: []Posting{{Account: "rider/rider_1", Amount: 100}}}
+	if err := j.Validate(); err == nil {
+		t.Fatalf("TestValidate Expected a single posting journal to fail")
+		return
+	}
+}
+
+func TestCommission(t *testing.T) {
+	t.Log("TestCommission")
+
+	if c := Commission(20600, 0.2); c != 4120 {
+		t.Fatalf("TestCommission Expected: 4120. Actual: %v", c)
+		return
+	}
+	if c := Commission(1003, 0.2); c != 201 {
+		t.Fatalf("TestCommission Expected: 201. Actual: %v", c)
+		return
+	}
+}
+
+func TestCardTrip(t *testing.T) {
+	t.Log("TestCardTrip")
+
+	rider, driver, provider := RiderAccount("rider_1"), DriverAccount("driver_1"), ProviderAccount("fake")
+	journals := []*Journal{
+		{Currency: "INR", Postings: Charge(rider, driver, 20600, 4120)},
+		{Currency: "INR", Postings: Payment(rider, provider, 20600)},
+		{Currency: "INR", Postings: Payout(driver, provider, 16480)},
+	}
+	balances := Balances(journals)
+	if balances[rider]["INR"] != 0 || balances[driver]["INR"] != 0 {
+		t.Fatalf("TestCardTrip Expected the rider and driver settled. Actual: %v", balances)
+		return
+	}
+	if balances[provider]["INR"] != 4120 || balances[CommissionAccount]["INR"] != -4120 {
+		t.Fatalf("TestCardTrip Expected the commission at the provider. Actual: %v", balances)
+		return
+	}
+}
+
+func TestCashTrip(t *testing.T) {
+	t.Log("TestCashTrip")
+
+	rider, driver := RiderAccount("rider_1"), DriverAccount("driver_1")
+	journals := []*Journal{
+		{Currency: "INR", Postings: Charge(rider, driver, 20600, 4120)},
+		{Currency: "INR", Postings: Payment(rider, driver, 20600)},
+	}
+	balances := Balances(journals)
+	//The driver holds the cash and owes the commission.
+	if balances[rider]["INR"] != 0 || balances[driver]["INR"] != 4120 {
+		t.Fatalf("TestCashTrip Unexpected balances: %v", balances)
+		return
+	}
+}
+
+func TestRefund(t *testing.T) {
+	t.Log("TestRefund")
+
+	rider, driver := RiderAccount("rider_1"), DriverAccount("driver_1")
+	postings := Refund(rider, driver, 10300, 20600, 4120)
+	j := &Journal{Currency: "INR", Postings: postings}
+	if err := j.Validate(); err != nil {
+		t.Fatalf("TestRefund Failed. Err: %v", err)
+		return
+	}
+	balances := Balances([]*Journal{j})
+	if balances[CommissionAccount]["INR"] != 2060 || balances[driver]["INR"] != 8240 {
+		t.Fatalf("TestRefund Expected half of each share back. Actual: %v", balances)
+		return
+	}
+}
@@ -8,22 +8,123 @@ package mycabsapi
 
 //City ...
 type City struct {
-	ID   string `json:"id,omitempty"`
-	Name string `json:"name"`
+	ID            string        `json:"id,omitempty"`
+	Name          string        `json:"name"`
+	Active        bool          `json:"active"`
+	Bookings      int64         `json:"bookings"`
+	Dispatch      string        `json:"dispatch,omitempty"`
+	DeactivatedAt string        `json:"deactivatedat,omitempty"` //RFC3339
+	Schedule      *CitySchedule `json:"schedule,omitempty"`
+	Zones         []*Zone       `json:"zones,omitempty"`
+}
+
+//CityRequest ...
+type CityRequest struct {
+	CityID string `json:"cityid"`
+}
+
+//ListCitiesRequest ...
+type ListCitiesRequest struct {
+	State string `json:"state,omitempty"` //active or inactive, all when empty
+}
+
+//ListCitiesResponse ...
+type ListCitiesResponse struct {
+	Cities []*City `json:"cities"`
+}
+
+//RenameCityRequest ...
+type RenameCityRequest struct {
+	CityID string `json:"cityid"`
+	Name   string `json:"name"`
+}
+
+//CitySchedule is when the city is in operation.
+type CitySchedule struct {
+	TimeZone string   `json:"timezone,omitempty"` //Of the hours and holidays, ex: Asia/Kolkata. UTC when empty.
+	FromHour int      `json:"fromhour,omitempty"` //In operation from this hour till ToHour, all day
+	ToHour   int      `json:"tohour,omitempty"`   //when both are 0. Can go past midnight.
+	Holidays []string `json:"holidays,omitempty"` //Dates, ex: 2026-01-26, with no service.
+}
+
+//SetCityScheduleRequest ...
+type SetCityScheduleRequest struct {
+	CityID   string        `json:"cityid"`
+	Schedule *CitySchedule `json:"schedule,omitempty"` //In operation at all times when nil.
+}
+
+//Zone is an area of the city with service.
+type Zone struct {
+	Name    string      `json:"name"`
+	Polygon []*Location `json:"polygon"` //Vertices in order, at least 3.
+}
+
+//SetCityZonesRequest ...
+type SetCityZonesRequest struct {
+	CityID string  `json:"cityid"`
+	Zones  []*Zone `json:"zones"` //Replace the zones of the city. Service everywhere when empty.
+}
+
+//ServiceAvailableRequest ...
+type ServiceAvailableRequest struct {
+	CityID string    `json:"cityid"`
+	Pickup *Location `json:"pickup,omitempty"`
+	At     string    `json:"at,omitempty"` //RFC3339, now when empty.
+}
+
+//ServiceAvailableResponse ...
+type ServiceAvailableResponse struct {
+	Available bool   `json:"available"`
+	Reason    string `json:"reason,omitempty"` //Why not, when not available.
+	Zone      string `json:"zone,omitempty"`   //Of the pickup.
+}
+
+//Location ...
+type Location struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
 }
 
 //Cab ...
 type Cab struct {
-	ID    string `json:"id,omitempty"`
-	Name  string `json:"name"`
-	Type  string `json:"type"`
-	City  string `json:"city"`
-	State string `json:"state,omitempty"`
+	ID        string    `json:"id,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	Type      string    `json:"type,omitempty"`
+	City      string    `json:"city,omitempty"`
+	State     string    `json:"state,omitempty"`
+	Distance  float64   `json:"distance,omitempty"` //Meters to the pickup, when booked with one.
+	TripID    string    `json:"tripid,omitempty"`
+	DriverID  string    `json:"driverid,omitempty"`
+	IdleSince string    `json:"idlesince,omitempty"` //RFC3339, when IDLE.
+	Location  *Location `json:"location,omitempty"`
+	Plate     string    `json:"plate,omitempty"`
+	Seats     int       `json:"seats,omitempty"`
+}
+
+//SearchCabsRequest finds the cabs matching all the filters given.
+type SearchCabsRequest struct {
+	CityID      string   `json:"cityid,omitempty"`
+	Type        string   `json:"type,omitempty"`
+	State       string   `json:"state,omitempty"`
+	IdleMinutes int      `json:"idleminutes,omitempty"` //IDLE for longer than this.
+	NamePrefix  string   `json:"nameprefix,omitempty"`
+	Sort        string   `json:"sort,omitempty"` //id (default), name or idlesince (IDLE cabs only)
+	Desc        bool     `json:"desc,omitempty"`
+	Limit       int      `json:"limit,omitempty"`  //Cabs looked at per page, 50 by default.
+	Cursor      string   `json:"cursor,omitempty"` //Of the previous page, for the next one.
+	Fields      []string `json:"fields,omitempty"` //Of the cabs returned, all when empty.
+}
+
+//SearchCabsResponse ...
+type SearchCabsResponse struct {
+	Cabs   []*Cab `json:"cabs"`
+	Cursor string `json:"cursor,omitempty"` //Of the next page, empty on the last one.
 }
 
 //OnboardCityRequest ...
 type OnboardCityRequest struct {
-	Name string `json:"name"`
+	Name        string `json:"name"`
+	LaunchPromo *Promo `json:"launchpromo,omitempty"` //Created for the new city only.
 }
 
 //OnboardCityResponse ...
@@ -31,11 +132,64 @@ type OnboardCityResponse struct {
 	ID string `json:"id,omitempty"`
 }
 
+//CityDispatchRequest ...
+type CityDispatchRequest struct {
+	CityID   string `json:"cityid"`
+	Strategy string `json:"strategy"` //idle, roundrobin, leasttrips, rating or lottery
+}
+
 //RegisterCabRequest ...
 type RegisterCabRequest struct {
-	Name   string `json:"name"`
-	Type   string `json:"type"`
-	CityID string `json:"cityid"`
+	Name     string    `json:"name"`
+	Type     string    `json:"type"`
+	CityID   string    `json:"cityid"`
+	Location *Location `json:"location,omitempty"`
+	Plate    string    `json:"plate,omitempty"` //Registration plate.
+	Seats    int       `json:"seats,omitempty"` //For riders.
+}
+
+//UpdateCabRequest changes the fields given, the others are kept.
+type UpdateCabRequest struct {
+	CabID string `json:"cabid"`
+	Name  string `json:"name,omitempty"`
+	Type  string `json:"type,omitempty"`
+	Plate string `json:"plate,omitempty"`
+	Seats int    `json:"seats,omitempty"`
+}
+
+//DecommissionCabRequest ...
+type DecommissionCabRequest struct {
+	CabID  string `json:"cabid"`
+	Reason string `json:"reason,omitempty"`
+}
+
+//ImportRow is a city or a cab of a fleet import. In CSV files the columns are
+//named after the json fields, with lat and lon for the location.
+type ImportRow struct {
+	Kind     string    `json:"kind,omitempty"` //city or cab (default).
+	Name     string    `json:"name"`
+	Type     string    `json:"type,omitempty"`
+	CityID   string    `json:"cityid,omitempty"` //Or the name of a city of the same import.
+	Plate    string    `json:"plate,omitempty"`
+	Seats    int       `json:"seats,omitempty"`
+	Location *Location `json:"location,omitempty"`
+}
+
+//ImportRowResult ...
+type ImportRowResult struct {
+	Line  int    `json:"line"`
+	Kind  string `json:"kind"`
+	Name  string `json:"name,omitempty"`
+	ID    string `json:"id,omitempty"`    //Of the city or cab created, none on a dry run.
+	Error string `json:"error,omitempty"` //Why the row was not imported.
+}
+
+//ImportReport ...
+type ImportReport struct {
+	DryRun   bool               `json:"dryrun,omitempty"`
+	Imported int                `json:"imported"` //Rows which were, or on a dry run would be, imported.
+	Failed   int                `json:"failed"`
+	Rows     []*ImportRowResult `json:"rows"`
 }
 
 //RegisterCabResponse ...
@@ -45,21 +199,294 @@ type RegisterCabResponse struct {
 
 //BookingRequest ...
 type BookingRequest struct {
-	From    string `json:"from"`
-	To      string `json:"to"`
-	CabType string `json:"cabtype"`
+	RiderID      string    `json:"riderid"`
+	From         string    `json:"from"`
+	To           string    `json:"to"`
+	CabType      string    `json:"cabtype"`
+	Pickup       *Location `json:"pickup,omitempty"`
+	PromoCode    string    `json:"promocode,omitempty"`
+	CorporateID  string    `json:"corporateid,omitempty"`  //Bills the trip to the corporate account of the rider.
+	AllowUpgrade bool      `json:"allowupgrade,omitempty"` //To a higher class at the fare of cabtype, when no cab of cabtype is idle.
 }
 
 //BookingResponse ...
 type BookingResponse struct {
-	CabID   string `json:"cabid,omitempty"`
-	CabName string `json:"cabname,omitempty"`
+	TripID   string  `json:"tripid,omitempty"`
+	CabID    string  `json:"cabid,omitempty"`
+	DriverID string  `json:"driverid,omitempty"`
+	CabName  string  `json:"cabname,omitempty"`
+	CabType  string  `json:"cabtype,omitempty"`  //Of the cab, higher than asked for when upgraded.
+	Distance float64 `json:"distance,omitempty"` //Meters from the cab to the pickup.
+	TicketID string  `json:"ticketid,omitempty"` //Set instead of the cab when the booking is waitlisted.
+}
+
+//BookingTicketRequest ...
+type BookingTicketRequest struct {
+	TicketID string `json:"ticketid"`
+}
+
+//BookingTicketResponse ...
+type BookingTicketResponse struct {
+	TicketID string `json:"ticketid"`
+	State    string `json:"state"` //WAITING, ASSIGNED or CANCELLED
+	TripID   string `json:"tripid,omitempty"`
+	CabID    string `json:"cabid,omitempty"`
+	CabName  string `json:"cabname,omitempty"`
+	Reason   string `json:"reason,omitempty"` //Why the ticket was cancelled when it could not be served.
+}
+
+//Reservation ...
+type Reservation struct {
+	ID         string    `json:"id"`
+	RiderID    string    `json:"riderid"`
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	CabType    string    `json:"cabtype"`
+	Pickup     *Location `json:"pickup,omitempty"`
+	PickupTime string    `json:"pickuptime"` //RFC3339
+	State      string    `json:"state"`      //SCHEDULED, DISPATCHED, FAILED or CANCELLED
+	TripID     string    `json:"tripid,omitempty"`
+	CabID      string    `json:"cabid,omitempty"`
+	CabName    string    `json:"cabname,omitempty"`
+	PromoCode  string    `json:"promocode,omitempty"`
+}
+
+//ReserveCabRequest ...
+type ReserveCabRequest struct {
+	RiderID    string    `json:"riderid"`
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	CabType    string    `json:"cabtype"`
+	Pickup     *Location `json:"pickup,omitempty"`
+	PickupTime string    `json:"pickuptime"` //RFC3339, ex: 2020-09-06T18:30:00+05:30
+	PromoCode  string    `json:"promocode,omitempty"`
+}
+
+//ReserveCabResponse ...
+type ReserveCabResponse struct {
+	ID string `json:"id,omitempty"`
+}
+
+//ListReservationsRequest ...
+type ListReservationsRequest struct {
+	RiderID string `json:"riderid"`
+	From    string `json:"from,omitempty"`
+	State   string `json:"state,omitempty"` //SCHEDULED when not given.
+}
+
+//ListReservationsResponse ...
+type ListReservationsResponse struct {
+	Reservations []Reservation `json:"reservations"`
+}
+
+//ModifyReservationRequest ... Empty fields are left unchanged.
+type ModifyReservationRequest struct {
+	ID         string    `json:"id"`
+	To         string    `json:"to,omitempty"`
+	CabType    string    `json:"cabtype,omitempty"`
+	PickupTime string    `json:"pickuptime,omitempty"`
+	Pickup     *Location `json:"pickup,omitempty"`
+}
+
+//CancelReservationRequest ...
+type CancelReservationRequest struct {
+	ID string `json:"id"`
+}
+
+//UpdateCabLocationRequest ...
+type UpdateCabLocationRequest struct {
+	CabID string  `json:"cabid"`
+	Lat   float64 `json:"lat"`
+	Lon   float64 `json:"lon"`
+}
+
+//Ping is a GPS fix pushed by the device of a cab.
+type Ping struct {
+	CabID   string  `json:"cabid"`
+	Lat     float64 `json:"lat"`
+	Lon     float64 `json:"lon"`
+	Heading float64 `json:"heading"` //Degrees clockwise from north.
+	Time    int64   `json:"time"`    //Unix milliseconds of the fix.
+}
+
+//CabLocationsRequest ...
+type CabLocationsRequest struct {
+	Pings []Ping `json:"pings"`
+}
+
+//PingResult tells whether a ping was taken, in the order of the request.
+type PingResult struct {
+	CabID    string `json:"cabid"`
+	Time     int64  `json:"time"`
+	Accepted bool   `json:"accepted"`
+	Error    string `json:"error,omitempty"`
+}
+
+//CabLocationsResponse ...
+type CabLocationsResponse struct {
+	Results []PingResult `json:"results"`
+}
+
+//TripTrailRequest ...
+type TripTrailRequest struct {
+	TripID string `json:"tripid"`
+}
+
+//TripTrailResponse ...
+type TripTrailResponse struct {
+	TripID string `json:"tripid"`
+	Trail  []Ping `json:"trail"`
 }
 
 //EndTripRequest ...
 type EndTripRequest struct {
+	CabID    string  `json:"cabid"`
+	CityID   string  `json:"cityid,omitempty"`
+	Distance float64 `json:"distance,omitempty"` //Meters from the odometer, the trail is used when not given.
+}
+
+//EndTripResponse ...
+type EndTripResponse struct {
+	TripID   string  `json:"tripid,omitempty"`
+	Distance float64 `json:"distance"` //Meters.
+	Duration int64   `json:"duration"` //Seconds.
+	Fare     *Fare   `json:"fare,omitempty"`
+}
+
+//Tariff of a city and cab type. Amounts are in the minor units of the currency.
+type Tariff struct {
+	CityID      string           `json:"cityid"`
+	CabType     string           `json:"cabtype"`
+	Currency    string           `json:"currency"`
+	BaseFare    int64            `json:"basefare"`
+	PerKm       int64            `json:"perkm"`
+	PerMinute   int64            `json:"perminute"`
+	MinimumFare int64            `json:"minimumfare"`
+	InterCity   int64            `json:"intercity"`
+	InterCityTo map[string]int64 `json:"intercityto,omitempty"` //Surcharge per destination city.
+	TaxName     string           `json:"taxname,omitempty"`     //Tax included in the fares, ex: GST.
+	TaxRate     float64          `json:"taxrate,omitempty"`     //0.05 for 5%.
+	CancelFee   int64            `json:"cancelfee,omitempty"`   //Charged to a rider cancelling late.
+}
+
+//TariffRequest ...
+type TariffRequest struct {
+	CityID  string `json:"cityid"`
+	CabType string `json:"cabtype"`
+}
+
+//Fare is the breakdown of what a trip costs.
+type Fare struct {
+	Currency   string  `json:"currency"`
+	Base       int64   `json:"base"`
+	Distance   int64   `json:"distance"`
+	Time       int64   `json:"time"`
+	Minimum    int64   `json:"minimum"`
+	Surge      int64   `json:"surge"`
+	InterCity  int64   `json:"intercity"`
+	Discount   int64   `json:"discount,omitempty"` //Taken off by the promo code.
+	PromoCode  string  `json:"promocode,omitempty"`
+	Total      int64   `json:"total"`
+	Multiplier float64 `json:"multiplier"` //Surge multiplier, 1 when there is no surge.
+}
+
+//FareEstimateRequest ...
+type FareEstimateRequest struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	CabType   string    `json:"cabtype"`
+	Pickup    *Location `json:"pickup,omitempty"`
+	Drop      *Location `json:"drop,omitempty"`
+	Distance  float64   `json:"distance,omitempty"` //Meters, estimated from pickup and drop when not given.
+	Duration  int64     `json:"duration,omitempty"` //Seconds, estimated from the distance when not given.
+	PromoCode string    `json:"promocode,omitempty"`
+	RiderID   string    `json:"riderid,omitempty"` //Checks the per rider limit of the promo code.
+}
+
+//FareEstimateResponse ...
+type FareEstimateResponse struct {
+	Distance float64 `json:"distance"`
+	Duration int64   `json:"duration"`
+	Fare     *Fare   `json:"fare"`
+}
+
+//SurgeRequest ...
+type SurgeRequest struct {
+	CityID  string `json:"cityid"`
+	CabType string `json:"cabtype"`
+}
+
+//SurgeResponse ...
+type SurgeResponse struct {
+	CityID     string  `json:"cityid"`
+	CabType    string  `json:"cabtype"`
+	Multiplier float64 `json:"multiplier"`
+	Demand     int     `json:"demand"` //Booking requests in the window.
+	Supply     int     `json:"supply"` //Idle cabs.
+	UpdatedAt  string  `json:"updatedat"`
+}
+
+//CitySurgeRequest ...
+type CitySurgeRequest struct {
+	CityID string  `json:"cityid"`
+	Cap    float64 `json:"cap"` //Highest surge multiplier of the city, 1 turns surge off.
+}
+
+//TripRequest ...
+type TripRequest struct {
+	TripID string `json:"tripid"`
+}
+
+//Trip ...
+type Trip struct {
+	ID          string  `json:"id"`
+	State       string  `json:"state"` //BOOKED, ON_TRIP, COMPLETED or CANCELLED
+	RiderID     string  `json:"riderid,omitempty"`
+	DriverID    string  `json:"driverid,omitempty"`
+	CabID       string  `json:"cabid"`
+	CabType     string  `json:"cabtype"`
+	BookedType  string  `json:"bookedtype,omitempty"` //Charged for, when upgraded to cabtype.
+	From        string  `json:"from"`
+	To          string  `json:"to"`
+	BookedAt    string  `json:"bookedat"`
+	PickedUpAt  string  `json:"pickedupat,omitempty"`
+	EndedAt     string  `json:"endedat,omitempty"`
+	Distance    float64 `json:"distance,omitempty"`
+	Duration    int64   `json:"duration,omitempty"`
+	Fare        *Fare   `json:"fare,omitempty"`
+	PromoCode   string  `json:"promocode,omitempty"`   //Given at booking.
+	CorporateID string  `json:"corporateid,omitempty"` //Billed to.
+	RiderStars  int     `json:"riderstars,omitempty"`  //Given by the rider to the driver.
+	DriverStars int     `json:"driverstars,omitempty"` //Given by the driver to the rider.
+}
+
+//CabArrivingRequest ...
+type CabArrivingRequest struct {
+	CabID string `json:"cabid"`
+}
+
+//PickupRequest ...
+type PickupRequest struct {
+	CabID string `json:"cabid"`
+}
+
+//MaintenanceRequest ...
+type MaintenanceRequest struct {
+	CabID  string `json:"cabid"`
+	Reason string `json:"reason,omitempty"`
+}
+
+//ReturnFromMaintenanceRequest ...
+type ReturnFromMaintenanceRequest struct {
+	CabID string `json:"cabid"`
+	State string `json:"state,omitempty"` //IDLE (default), IN_ACTIVE or RETIRED
+}
+
+//CancelTripRequest ...
+type CancelTripRequest struct {
 	CabID  string `json:"cabid"`
-	CityID string `json:"cityid,omitempty"`
+	Party  string `json:"party"` //rider or driver
+	Reason string `json:"reason,omitempty"`
 }
 
 //DeActivateCabRequest ...
@@ -82,6 +509,88 @@ type ChangeCityRequest struct {
 type DemandCityResonse struct {
 	CityID   string `json:"cityid"`
 	CityName string `json:"cityname"`
+	Window   string `json:"window"`   //hour, day or week, up to now.
+	Bookings int    `json:"bookings"` //In the window.
+}
+
+//DemandAnalyticsRequest ...
+type DemandAnalyticsRequest struct {
+	From     string `json:"from,omitempty"`     //RFC3339, a day before To by default.
+	To       string `json:"to,omitempty"`       //RFC3339, now by default.
+	CityID   string `json:"cityid,omitempty"`   //All cities when empty.
+	CabType  string `json:"cabtype,omitempty"`  //All types when empty.
+	Top      int    `json:"top,omitempty"`      //Cities ranked, 5 by default.
+	Bucket   string `json:"bucket,omitempty"`   //Of the trend, hour (default) or day.
+	TimeZone string `json:"timezone,omitempty"` //Of the trend days and the heatmap, UTC by default.
+}
+
+//DemandAnalyticsResponse sums up the bookings of a window. Bookings are counted
+//by the hour, the hours the window starts and ends in are counted whole.
+type DemandAnalyticsResponse struct {
+	From     string         `json:"from"`
+	To       string         `json:"to"`
+	Bookings int            `json:"bookings"`
+	Top      []*CityDemand  `json:"top"`
+	Trend    []*DemandPoint `json:"trend"`
+	Heatmap  [][]int        `json:"heatmap"` //By weekday, Sunday first, and hour of the day.
+}
+
+//CityDemand ...
+type CityDemand struct {
+	CityID   string  `json:"cityid"`
+	CityName string  `json:"cityname"`
+	Bookings int     `json:"bookings"`
+	Share    float64 `json:"share"` //Of all the bookings of the window, 0 to 1.
+}
+
+//DemandPoint ...
+type DemandPoint struct {
+	At       string `json:"at"` //Start of the hour or day.
+	Bookings int    `json:"bookings"`
+}
+
+//RecommendRebalanceRequest ...
+type RecommendRebalanceRequest struct {
+	CabType       string  `json:"cabtype,omitempty"`       //All types when empty.
+	WindowMinutes int     `json:"windowminutes,omitempty"` //Of recent bookings, 60 by default, at most 360.
+	Ratio         float64 `json:"ratio,omitempty"`         //Cabs wanted per booking, 1 by default.
+	MaxMoves      int     `json:"maxmoves,omitempty"`      //20 by default.
+}
+
+//RebalancePlanRequest ...
+type RebalancePlanRequest struct {
+	PlanID string `json:"planid"`
+}
+
+//RebalancePlan ...
+type RebalancePlan struct {
+	ID        string             `json:"id,omitempty"` //None when there is nothing to move.
+	State     string             `json:"state"`        //PROPOSED, EXECUTING or EXECUTED
+	CreatedAt string             `json:"createdat"`
+	Moves     []*RebalanceMove   `json:"moves"`
+	Impact    []*RebalanceImpact `json:"impact,omitempty"` //Only when recommended.
+}
+
+//RebalanceMove ...
+type RebalanceMove struct {
+	CabID   string `json:"cabid"`
+	CabType string `json:"cabtype"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Result  string `json:"result,omitempty"` //moved, or why not, once approved.
+}
+
+//RebalanceImpact is the expected effect of a plan on a cab type in a city.
+type RebalanceImpact struct {
+	CityID        string `json:"cityid"`
+	CabType       string `json:"cabtype"`
+	Demand        int    `json:"demand"`   //Bookings in the window.
+	Supply        int    `json:"supply"`   //Idle cabs with a driver on shift.
+	Wanted        int    `json:"wanted"`   //Cabs for the demand.
+	Shortage      int    `json:"shortage"` //Before the moves.
+	In            int    `json:"in,omitempty"`
+	Out           int    `json:"out,omitempty"`
+	ShortageAfter int    `json:"shortageafter"`
 }
 
 //CabHistoryRequest ...
@@ -95,3 +604,384 @@ type CabHistoryResonse struct {
 }
 
 //////////////////////////////////////////////////////////////////////////////////
+
+//RegisterRiderRequest ...
+type RegisterRiderRequest struct {
+	Name           string `json:"name"`
+	Phone          string `json:"phone"`
+	Email          string `json:"email,omitempty"`
+	DefaultPayment string `json:"defaultpayment"` //cash, card or wallet
+}
+
+//RegisterRiderResponse ...
+type RegisterRiderResponse struct {
+	ID string `json:"id,omitempty"`
+}
+
+//RiderRequest ...
+type RiderRequest struct {
+	RiderID string `json:"riderid"`
+}
+
+//Rider ...
+type Rider struct {
+	ID             string  `json:"id"`
+	Name           string  `json:"name"`
+	Phone          string  `json:"phone"`
+	Email          string  `json:"email,omitempty"`
+	DefaultPayment string  `json:"defaultpayment"`
+	ActiveTripID   string  `json:"activetripid,omitempty"` //Trip, or waitlist ticket, the rider is on.
+	CorporateID    string  `json:"corporateid,omitempty"`
+	Rating         float64 `json:"rating,omitempty"`
+	Ratings        int64   `json:"ratings,omitempty"`
+}
+
+//UpdateRiderRequest ...
+type UpdateRiderRequest struct {
+	RiderID        string `json:"riderid"`
+	Name           string `json:"name,omitempty"`
+	Phone          string `json:"phone,omitempty"`
+	Email          string `json:"email,omitempty"`
+	DefaultPayment string `json:"defaultpayment,omitempty"`
+}
+
+//RiderTripsRequest ...
+type RiderTripsRequest struct {
+	RiderID string `json:"riderid"`
+	State   string `json:"state,omitempty"` //active or past, all trips when empty
+}
+
+//RiderTripsResponse ...
+type RiderTripsResponse struct {
+	Trips []*Trip `json:"trips"`
+}
+
+//RegisterDriverRequest ...
+type RegisterDriverRequest struct {
+	Name          string `json:"name"`
+	Phone         string `json:"phone"`
+	CityID        string `json:"cityid"`
+	LicenseNo     string `json:"licenseno"`
+	LicenseExpiry string `json:"licenseexpiry"` //YYYY-MM-DD
+}
+
+//RegisterDriverResponse ...
+type RegisterDriverResponse struct {
+	ID string `json:"id,omitempty"`
+}
+
+//DriverRequest ...
+type DriverRequest struct {
+	DriverID string `json:"driverid"`
+}
+
+//Driver ...
+type Driver struct {
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	Phone         string  `json:"phone"`
+	CityID        string  `json:"cityid"`
+	LicenseNo     string  `json:"licenseno"`
+	LicenseExpiry string  `json:"licenseexpiry"`
+	CabID         string  `json:"cabid,omitempty"`      //Cab of the current shift.
+	ShiftStart    string  `json:"shiftstart,omitempty"` //RFC3339
+	Rating        float64 `json:"rating,omitempty"`
+	Ratings       int64   `json:"ratings,omitempty"`
+}
+
+//ClockInRequest ...
+type ClockInRequest struct {
+	DriverID string `json:"driverid"`
+	CabID    string `json:"cabid"`
+}
+
+//ClockOutRequest ...
+type ClockOutRequest struct {
+	DriverID string `json:"driverid"`
+}
+
+//DriverReportRequest ...
+type DriverReportRequest struct {
+	DriverID string `json:"driverid"`
+	From     string `json:"from"` //YYYY-MM-DD
+	To       string `json:"to"`   //YYYY-MM-DD, included
+}
+
+//DriverDay is the work of a driver on a day.
+type DriverDay struct {
+	Day       string           `json:"day"`
+	Trips     int              `json:"trips"` //Completed trips.
+	Cancelled int              `json:"cancelled"`
+	Distance  float64          `json:"distance"` //Meters.
+	Earnings  map[string]int64 `json:"earnings"` //Fares per currency, in minor units.
+	OnShift   int64            `json:"onshift"`  //Seconds.
+}
+
+//DriverReportResponse ...
+type DriverReportResponse struct {
+	DriverID string       `json:"driverid"`
+	Days     []*DriverDay `json:"days"`
+}
+
+//RateDriverRequest ...
+type RateDriverRequest struct {
+	TripID  string `json:"tripid"`
+	RiderID string `json:"riderid"`
+	Stars   int    `json:"stars"` //1 to 5
+	Comment string `json:"comment,omitempty"`
+}
+
+//RateRiderRequest ...
+type RateRiderRequest struct {
+	TripID   string `json:"tripid"`
+	DriverID string `json:"driverid"`
+	Stars    int    `json:"stars"` //1 to 5
+	Comment  string `json:"comment,omitempty"`
+}
+
+//CabRatingRequest ...
+type CabRatingRequest struct {
+	CabID string `json:"cabid"`
+}
+
+//CabRatingResponse ...
+type CabRatingResponse struct {
+	CabID   string  `json:"cabid"`
+	Rating  float64 `json:"rating"`
+	Ratings int64   `json:"ratings"`
+	Status  string  `json:"status"` //OK, DEPRIORITIZED or SUSPENDED in its city.
+}
+
+//CityRatingRequest ...
+type CityRatingRequest struct {
+	CityID       string  `json:"cityid"`
+	Deprioritize float64 `json:"deprioritize"` //Cabs rated lower are dispatched last, 0 for none.
+	Suspend      float64 `json:"suspend"`      //Cabs rated lower are not dispatched, 0 for none.
+	MinRatings   int64   `json:"minratings"`   //Ratings a cab needs before the thresholds apply.
+}
+
+//SettleTripRequest ...
+type SettleTripRequest struct {
+	TripID string `json:"tripid"`
+}
+
+//SettleTripResponse ...
+type SettleTripResponse struct {
+	TripID      string   `json:"tripid"`
+	Method      string   `json:"method"` //Payment method of the rider.
+	Currency    string   `json:"currency"`
+	Total       int64    `json:"total"`
+	Commission  int64    `json:"commission"`
+	DriverShare int64    `json:"drivershare"`
+	Journals    []string `json:"journals"`
+}
+
+//RefundTripRequest ...
+type RefundTripRequest struct {
+	TripID string `json:"tripid"`
+	Amount int64  `json:"amount,omitempty"` //All that is left of the fare if not given.
+	Reason string `json:"reason,omitempty"`
+	Key    string `json:"key,omitempty"` //Retries with the same key refund once, the amount and reason when not given.
+}
+
+//RefundTripResponse ...
+type RefundTripResponse struct {
+	TripID        string   `json:"tripid"`
+	Currency      string   `json:"currency"`
+	Refunded      int64    `json:"refunded"`
+	TotalRefunded int64    `json:"totalrefunded"`
+	Journals      []string `json:"journals"`
+}
+
+//BalanceRequest ...
+type BalanceRequest struct {
+	Account string `json:"account"` //rider/<id>, driver/<id>, corporate/<id>, provider/<name> or platform/commission
+}
+
+//BalanceResponse ...
+type BalanceResponse struct {
+	Account  string           `json:"account"`
+	Balances map[string]int64 `json:"balances"` //Per currency, positive when the account owes.
+}
+
+//ReceiptRequest ...
+type ReceiptRequest struct {
+	TripID string `json:"tripid"`
+	Format string `json:"format,omitempty"` //json (default), html or pdf
+}
+
+//Receipt of a completed trip.
+type Receipt struct {
+	ReceiptNo  string  `json:"receiptno"`
+	TripID     string  `json:"tripid"`
+	RiderID    string  `json:"riderid,omitempty"`
+	RiderName  string  `json:"ridername,omitempty"`
+	CabID      string  `json:"cabid"`
+	CabName    string  `json:"cabname"`
+	CabType    string  `json:"cabtype"`
+	DriverID   string  `json:"driverid,omitempty"`
+	From       string  `json:"from"`
+	FromName   string  `json:"fromname"`
+	To         string  `json:"to"`
+	ToName     string  `json:"toname"`
+	BookedAt   string  `json:"bookedat"`
+	PickedUpAt string  `json:"pickedupat,omitempty"`
+	EndedAt    string  `json:"endedat"`
+	Distance   float64 `json:"distance"` //Meters.
+	Duration   int64   `json:"duration"` //Seconds.
+	Fare       *Fare   `json:"fare,omitempty"`
+	TaxName    string  `json:"taxname,omitempty"`
+	TaxRate    float64 `json:"taxrate,omitempty"`
+	Tax        int64   `json:"tax,omitempty"` //Included in the fare total.
+	Refunded   int64   `json:"refunded,omitempty"`
+}
+
+//InvoiceRequest ...
+type InvoiceRequest struct {
+	RiderID string `json:"riderid"`
+	Month   string `json:"month"`            //YYYY-MM, in UTC.
+	Format  string `json:"format,omitempty"` //json (default), html or pdf
+}
+
+//Invoice gathers the receipts of the trips of a rider ended in a month.
+type Invoice struct {
+	InvoiceNo string          `json:"invoiceno"`
+	RiderID   string          `json:"riderid"`
+	RiderName string          `json:"ridername"`
+	Month     string          `json:"month"`
+	Receipts  []*Receipt      `json:"receipts"`
+	Totals    []*InvoiceTotal `json:"totals"`
+}
+
+//InvoiceTotal sums up the receipts of an invoice in one currency.
+type InvoiceTotal struct {
+	Currency string `json:"currency"`
+	Trips    int    `json:"trips"`
+	Fare     int64  `json:"fare"`
+	Tax      int64  `json:"tax"`
+	Refunded int64  `json:"refunded"`
+	Due      int64  `json:"due"`
+}
+
+//Promo is a discount code. It takes Percent off the fare, up to MaxOff, or a
+//Flat amount.
+type Promo struct {
+	Code            string   `json:"code"`                      //3 to 20 letters or digits.
+	Percent         float64  `json:"percent,omitempty"`         //10 for 10% off.
+	MaxOff          int64    `json:"maxoff,omitempty"`          //Cap of a percent discount, in minor units.
+	Flat            int64    `json:"flat,omitempty"`            //In minor units of the fare currency.
+	Cities          []string `json:"cities,omitempty"`          //Any city when empty.
+	CabTypes        []string `json:"cabtypes,omitempty"`        //Any cab type when empty.
+	ValidFrom       string   `json:"validfrom"`                 //RFC3339, bookings from then.
+	ValidTo         string   `json:"validto"`                   //RFC3339, bookings before then.
+	MaxUses         int      `json:"maxuses,omitempty"`         //All riders together, no limit when 0.
+	MaxUsesPerRider int      `json:"maxusesperrider,omitempty"` //No limit when 0.
+	Uses            int      `json:"uses,omitempty"`            //Trips charged with the code so far.
+}
+
+//PromoRequest ...
+type PromoRequest struct {
+	Code string `json:"code"`
+}
+
+//CorporatePolicy limits what the members of a corporate account can book on it.
+type CorporatePolicy struct {
+	CabTypes   []string `json:"cabtypes,omitempty"`   //Any cab type when empty.
+	Cities     []string `json:"cities,omitempty"`     //Any city when empty.
+	FromHour   int      `json:"fromhour,omitempty"`   //Bookings from this hour till ToHour, at any
+	ToHour     int      `json:"tohour,omitempty"`     //time when both are 0. Can go past midnight.
+	TimeZone   string   `json:"timezone,omitempty"`   //Of the hours, ex: Asia/Kolkata. UTC when empty.
+	MonthlyCap int64    `json:"monthlycap,omitempty"` //Fares of a month in minor units, no cap when 0.
+}
+
+//CreateCorporateRequest ...
+type CreateCorporateRequest struct {
+	Name   string           `json:"name"`
+	Policy *CorporatePolicy `json:"policy,omitempty"`
+}
+
+//CreateCorporateResponse ...
+type CreateCorporateResponse struct {
+	ID string `json:"id"`
+}
+
+//CorporateRequest ...
+type CorporateRequest struct {
+	CorporateID string `json:"corporateid"`
+}
+
+//Corporate ...
+type Corporate struct {
+	ID      string           `json:"id"`
+	Name    string           `json:"name"`
+	Policy  *CorporatePolicy `json:"policy"`
+	Members []string         `json:"members"`
+	Spent   int64            `json:"spent"` //Fares of the trips ended this month (UTC).
+}
+
+//SetCorporatePolicyRequest ...
+type SetCorporatePolicyRequest struct {
+	CorporateID string           `json:"corporateid"`
+	Policy      *CorporatePolicy `json:"policy"`
+}
+
+//CorporateMemberRequest ...
+type CorporateMemberRequest struct {
+	CorporateID string `json:"corporateid"`
+	RiderID     string `json:"riderid"`
+}
+
+//StatementRequest ...
+type StatementRequest struct {
+	CorporateID string `json:"corporateid"`
+	Month       string `json:"month"`            //YYYY-MM, in UTC.
+	Format      string `json:"format,omitempty"` //json (default), html or pdf
+}
+
+//Statement gathers the receipts of the trips billed to a corporate account
+//which ended in a month.
+type Statement struct {
+	StatementNo   string             `json:"statementno"`
+	CorporateID   string             `json:"corporateid"`
+	CorporateName string             `json:"corporatename"`
+	Month         string             `json:"month"`
+	Receipts      []*Receipt         `json:"receipts"`
+	Members       []*StatementMember `json:"members"`
+	Totals        []*InvoiceTotal    `json:"totals"`
+}
+
+//StatementMember sums up the trips of a member in a statement.
+type StatementMember struct {
+	RiderID   string           `json:"riderid"`
+	RiderName string           `json:"ridername"`
+	Trips     int              `json:"trips"`
+	Due       map[string]int64 `json:"due"` //Per currency, fares less refunds.
+}
+
+//CabType is an entry of the catalog of cab types.
+type CabType struct {
+	Name       string `json:"name"`    //Lower case letters, digits or '-', ex: sedan.
+	Seats      int    `json:"seats"`   //For riders.
+	Luggage    int    `json:"luggage"` //Bags which fit in.
+	Wheelchair bool   `json:"wheelchair,omitempty"`
+	ChildSeat  bool   `json:"childseat,omitempty"`
+	Class      int    `json:"class"` //Upgrade order, bookings are upgraded to a higher class.
+}
+
+//CabTypeRequest ...
+type CabTypeRequest struct {
+	Name string `json:"name"`
+}
+
+//ListCabTypesRequest lists the cab types with at least the capabilities asked for.
+type ListCabTypesRequest struct {
+	Seats      int  `json:"seats,omitempty"`
+	Luggage    int  `json:"luggage,omitempty"`
+	Wheelchair bool `json:"wheelchair,omitempty"`
+	ChildSeat  bool `json:"childseat,omitempty"`
+}
+
+//ListCabTypesResponse ...
+type ListCabTypesResponse struct {
+	CabTypes []*CabType `json:"cabtypes"` //In upgrade order.
+}
@@ -0,0 +1,168 @@
+package mycabsservice
+
+import (
+	"fmt"
+	"mycabs/analytics"
+	"mycabs/db"
+	"mycabs/mycabsapi"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+//Every booking is counted in an hourly bucket of the bookings partition of its
+//city, keyed by the hour and the cab type, in the hour the cab was booked. A
+//cancelled booking is taken back from the same bucket. A window is read with
+//a query of the buckets of each city in it.
+
+const (
+	hkeyValBookings     = "bookings/"
+	analyticsBucketHour = "hour"
+	analyticsBucketDay  = "day"
+	analyticsTop        = 5
+	maxAnalyticsTop     = 100
+	maxAnalyticsWindow  = 92 * 24 * time.Hour
+)
+
+//demandWindows are the windows DemandedCity looks back on.
+var demandWindows = map[string]time.Duration{
+	"hour": time.Hour,
+	"day":  24 * time.Hour,
+	"week": 7 * 24 * time.Hour,
+}
+
+//DemandAnalytics returns the top cities, the trend and the heatmap of the
+//bookings of the window.
+func DemandAnalytics(req *mycabsapi.DemandAnalyticsRequest) (*mycabsapi.DemandAnalyticsResponse, error) {
+	from, _ := time.Parse(time.RFC3339, req.From)
+	to, _ := time.Parse(time.RFC3339, req.To)
+	loc := time.UTC
+	if req.TimeZone != "" {
+		loc, _ = time.LoadLocation(req.TimeZone)
+	}
+
+	cityNames, err := analyticsCities(req.CityID)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := windowBookings(cityNames, req.CabType, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &mycabsapi.DemandAnalyticsResponse{
+		From:     req.From,
+		To:       req.To,
+		Bookings: analytics.Total(counts),
+		Top:      []*mycabsapi.CityDemand{},
+		Trend:    []*mycabsapi.DemandPoint{},
+		Heatmap:  make([][]int, 0, 7),
+	}
+	for _, ranked := range analytics.Top(counts, req.Top) {
+		resp.Top = append(resp.Top, &mycabsapi.CityDemand{
+			CityID:   ranked.CityID,
+			CityName: cityNames[ranked.CityID],
+			Bookings: ranked.Bookings,
+			Share:    float64(ranked.Bookings) / float64(resp.Bookings),
+		})
+	}
+	for _, point := range analytics.Trend(counts, from, to, req.Bucket == analyticsBucketDay, loc) {
+		resp.Trend = append(resp.Trend, &mycabsapi.DemandPoint{
+			At:       point.At.Format(time.RFC3339),
+			Bookings: point.Bookings,
+		})
+	}
+	heatmap := analytics.Heatmap(counts, loc)
+	for weekday := range heatmap {
+		resp.Heatmap = append(resp.Heatmap, heatmap[weekday][:])
+	}
+	return resp, nil
+}
+
+//analyticsCities returns the names of the cities by ID, only the given one when
+//there is one.
+func analyticsCities(cityID string) (map[string]string, error) {
+	if cityID != "" {
+		cityRec, err := loadCity(cityID)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{cityID: db.AttrToStr(cityRec["Name"])}, nil
+	}
+
+	cityRecords, err := db.Query(tableName, hkeyValCities, nil)
+	if err != nil {
+		fmt.Printf("analyticsCities: db.Query Failed. Err: %v\n", err)
+		return nil, err
+	}
+	cityNames := make(map[string]string, len(cityRecords))
+	for _, cityRec := range cityRecords {
+		cityNames[db.AttrToStr(cityRec["Id"])] = db.AttrToStr(cityRec["Name"])
+	}
+	return cityNames, nil
+}
+
+//windowBookings returns the hourly bookings of the cities from from till to,
+//of the cab type when one is given.
+func windowBookings(cityNames map[string]string, cabType string, from, to time.Time) ([]*analytics.Count, error) {
+	counts := []*analytics.Count{}
+	for cityID := range cityNames {
+		bookingRecords, err := db.QueryBetween(tableName, hkeyValBookings+cityID+"/",
+			bookingHour(from), bookingHour(to.Add(-time.Second))+"/~")
+		if err != nil {
+			fmt.Printf("windowBookings: db.QueryBetween of %v Failed. Err: %v\n", cityID, err)
+			return nil, err
+		}
+		for _, bookingRec := range bookingRecords {
+			hour, bucketType := splitBookingKey(db.AttrToStr(bookingRec[db.RKeyName]))
+			if cabType != "" && bucketType != cabType {
+				continue
+			}
+			count := &analytics.Count{CityID: cityID, CabType: bucketType, Hour: hour}
+			count.Bookings, _ = db.AttrToNum(bookingRec["Bookings"])
+			counts = append(counts, count)
+		}
+	}
+	return counts, nil
+}
+
+//countHourlyBooking adds delta to the bookings of the cab type in the city in
+//the hour the cab was booked, now when it isn't known.
+func countHourlyBooking(cabRec map[string]*dynamodb.AttributeValue, delta int) {
+	bookedAt := time.Now()
+	if attrVal, ok := cabRec["BookedAt"]; ok {
+		if unix, _ := db.AttrToNum64(attrVal); unix > 0 {
+			bookedAt = time.Unix(unix, 0)
+		}
+	}
+	cabType := ""
+	if attrVal, ok := cabRec["Type"]; ok {
+		cabType = db.AttrToStr(attrVal)
+	}
+
+	bookingKeys := map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(hkeyValBookings + db.AttrToStr(cabRec["CityID"]) + "/"),
+		db.RKeyName: db.StrToAttr(bookingHour(bookedAt) + "/" + cabType),
+	}
+	_, err := db.Increment(tableName, bookingKeys, "Bookings", delta)
+	if err != nil {
+		fmt.Printf("countHourlyBooking Failed: %v\n", err)
+	}
+}
+
+//bookingHour is the hour of t in the keys of the bookings partition.
+func bookingHour(t time.Time) string {
+	return fmt.Sprintf("%010d", t.Unix()/3600)
+}
+
+//splitBookingKey returns the hour and the cab type of the bucket key.
+func splitBookingKey(key string) (time.Time, string) {
+	sep := strings.Index(key, "/")
+	if sep < 0 {
+		sep = len(key)
+	}
+	hour, _ := strconv.ParseInt(key[:sep], 10, 64)
+	return time.Unix(hour*3600, 0).UTC(), strings.TrimPrefix(key[sep:], "/")
+}
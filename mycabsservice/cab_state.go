@@ -0,0 +1,219 @@
+package mycabsservice
+
+import (
+	"fmt"
+	"mycabs/db"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+//transitionEffect is run once a transition has been stored, with the cab
+//record as it is after the transition.
+type transitionEffect func(cabRec map[string]*dynamodb.AttributeValue)
+
+//cabTransitions is the cab lifecycle. For every state it lists the states a cab
+//can move to and the side effects of the move. Any other move is rejected.
+//RETIRED is final, a retired cab never moves again.
+var cabTransitions map[string]map[string][]transitionEffect
+
+//The lifecycle is set up in init as the effects themselves move cabs through it.
+func init() {
+	cabTransitions = map[string]map[string][]transitionEffect{
+		stateIdle: {
+			stateAssigned:    {countCityBooking},
+			stateInActive:    nil,
+			stateMaintenance: nil,
+			stateRetired:     nil, //Decommissioned.
+		},
+		stateAssigned: {
+			stateArriving: nil,
+			stateOnTrip:   nil,
+			stateIdle:     {uncountCityBooking, serveWaitlist}, //Trip cancelled.
+		},
+		stateArriving: {
+			stateOnTrip: nil,
+			stateIdle:   {uncountCityBooking, serveWaitlist}, //Trip cancelled.
+		},
+		stateOnTrip: {
+			stateIdle: {serveWaitlist},
+		},
+		stateInActive: {
+			stateIdle:        {serveWaitlist},
+			stateInActive:    nil, //City change of an inactive cab.
+			stateMaintenance: nil,
+			stateRetired:     nil, //Decommissioned.
+		},
+		stateMaintenance: {
+			stateIdle:     {serveWaitlist},
+			stateInActive: nil,
+			stateRetired:  nil,
+		},
+		stateRetired: {},
+	}
+}
+
+//cabTransition describes a state change requested on a cab.
+type cabTransition struct {
+	To      string
+	History string                              //History entry, without the sequence number.
+	Updates map[string]*dynamodb.AttributeValue //Attributes to store along with the state.
+	Writes  []*db.TxWrite                       //Other writes made along with the state, all or none.
+	Free    []string                            //Attributes the cab must have empty, ex: DriverID.
+	Expect  map[string]*dynamodb.AttributeValue //Values the cab must still have, besides its state.
+}
+
+//transitionCab moves the cab in cabRec to tr.To. Idle time accounting, the cab
+//history and its entries in the fleet sort partitions are updated as part of
+//the same write, which only succeeds if the cab is still in the state cabRec
+//was read in, with the attributes of tr.Free empty and those of tr.Expect as
+//given. A retired cab is no longer counted for its type.
+func transitionCab(cabRec map[string]*dynamodb.AttributeValue, tr *cabTransition) error {
+	cabID := db.AttrToStr(cabRec["Id"])
+	from := db.AttrToStr(cabRec["State"])
+	effects, ok := cabTransitions[from][tr.To]
+	if !ok {
+		return &invalidTransitionError{from: from, to: tr.To}
+	}
+
+	curTime := time.Now().Unix()
+	updateInfo := map[string]*dynamodb.AttributeValue{
+		"State": db.StrToAttr(tr.To),
+	}
+	adds := map[string]*dynamodb.AttributeValue{
+		"History": historyEntry(cabRec, tr.History),
+	}
+
+	//Idle time only runs in IDLE state. It is banked into PrevIdleWaiting when
+	//the cab leaves IDLE and restarted when the cab comes back to IDLE, so time
+	//spent assigned, on trip, inactive or in maintenance earns no idle credit.
+	if from == stateIdle && tr.To != stateIdle {
+		updateInfo["PrevIdleWaiting"] = db.Num64ToAttr(totalIdleWaiting(cabRec, curTime))
+		updateInfo["IdleSince"] = db.Num64ToAttr(0)
+	} else if from != stateIdle && tr.To == stateIdle {
+		updateInfo["IdleSince"] = db.Num64ToAttr(curTime)
+	}
+
+	for attr, attrVal := range tr.Updates {
+		updateInfo[attr] = attrVal
+	}
+	cond := map[string]*dynamodb.AttributeValue{
+		"State": db.StrToAttr(from),
+	}
+	for attr, attrVal := range tr.Expect {
+		cond[attr] = attrVal
+	}
+
+	newRec := make(map[string]*dynamodb.AttributeValue, len(cabRec))
+	for attr, attrVal := range cabRec {
+		newRec[attr] = attrVal
+	}
+	for attr, attrVal := range updateInfo {
+		newRec[attr] = attrVal
+	}
+	newRec["History"] = db.StrSetToAttr(append(db.AttrToStrSet(cabRec["History"]), db.AttrToStrSet(adds["History"])...))
+
+	writes := append(tr.Writes, fleetIndexWrites(cabRec, newRec)...)
+	if tr.To == stateRetired {
+		writes = append(writes, countCabType(db.AttrToStr(cabRec["Type"]), -1))
+	}
+	var err error
+	if len(writes) == 0 && len(tr.Free) == 0 {
+		err = db.UpdateExclusiveAdding(tableName, cabKeys(cabID), updateInfo, adds, cond)
+	} else {
+		write := &db.TxWrite{Update: cabKeys(cabID), Updates: updateInfo, Adds: adds, Cond: cond, Empty: tr.Free}
+		err = db.Transact(tableName, append([]*db.TxWrite{write}, writes...))
+	}
+	if db.IsConditionFailed(err) {
+		//The cab has moved on since it was read, report the state it is in now.
+		curRec, getErr := loadCab(cabID)
+		if getErr != nil {
+			return getErr
+		}
+		if db.AttrToStr(curRec["State"]) == from {
+			for _, attr := range tr.Free {
+				if attrVal, ok := curRec[attr]; ok && db.AttrToStr(attrVal) != "" {
+					return &busyError{kind: "cab", id: cabID, with: attr + " " + db.AttrToStr(attrVal)}
+				}
+			}
+			for attr, attrVal := range tr.Expect {
+				if db.AttrToStr(curRec[attr]) != db.AttrToStr(attrVal) {
+					return &conflictError{kind: "cab", id: cabID}
+				}
+			}
+		}
+		return &invalidTransitionError{from: db.AttrToStr(curRec["State"]), to: tr.To}
+	}
+	if err != nil {
+		fmt.Printf("transitionCab: write of %v failed. Err: %v\n", tr.To, err)
+		return err
+	}
+
+	for _, effect := range effects {
+		effect(newRec)
+	}
+	return nil
+}
+
+//totalIdleWaiting returns the idle time of the cab till curTime, including the
+//idle time banked from its earlier IDLE periods.
+func totalIdleWaiting(cabRec map[string]*dynamodb.AttributeValue, curTime int64) int64 {
+	prevIdleWaiting, err := db.AttrToNum64(cabRec["PrevIdleWaiting"])
+	if err != nil {
+		fmt.Printf("totalIdleWaiting: db.AttrToNum64 of PrevIdleWaiting Failed. Err: %v\n", err)
+	}
+
+	idleSince, err := db.AttrToNum64(cabRec["IdleSince"])
+	if err != nil || idleSince == 0 {
+		return prevIdleWaiting
+	}
+	return prevIdleWaiting + (curTime - idleSince)
+}
+
+//historyEntry is the numbered entry to add to the cab history. It is added to
+//the set as it is in the db, so entries written meanwhile are kept.
+func historyEntry(cabRec map[string]*dynamodb.AttributeValue, entry string) *dynamodb.AttributeValue {
+	history := db.AttrToStrSet(cabRec["History"])
+	return db.StrSetToAttr([]string{fmt.Sprintf("%v. %v", len(history), entry)})
+}
+
+//countCityBooking increments the Bookings counter of the city the cab is booked
+//from, and its bookings of the hour.
+func countCityBooking(cabRec map[string]*dynamodb.AttributeValue) {
+	_, err := db.Increment(tableName, cityKeys(db.AttrToStr(cabRec["CityID"])), "Bookings", 1)
+	if err != nil {
+		//Just log the error, the booking itself is done.
+		fmt.Printf("countCityBooking Failed: %v\n", err)
+	}
+	countHourlyBooking(cabRec, 1)
+}
+
+//uncountCityBooking takes back the booking counted by countCityBooking.
+func uncountCityBooking(cabRec map[string]*dynamodb.AttributeValue) {
+	_, err := db.Increment(tableName, cityKeys(db.AttrToStr(cabRec["CityID"])), "Bookings", -1)
+	if err != nil {
+		fmt.Printf("uncountCityBooking Failed: %v\n", err)
+	}
+	countHourlyBooking(cabRec, -1)
+}
+
+//cabKeys ...
+func cabKeys(cabID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(hkeyValCabs),
+		db.RKeyName: db.StrToAttr(cabID),
+	}
+}
+
+//loadCab reads the cab record, failing if the cab is not registered.
+func loadCab(cabID string) (map[string]*dynamodb.AttributeValue, error) {
+	cabRec, err := db.Get(tableName, cabKeys(cabID))
+	if err != nil {
+		fmt.Printf("loadCab: db.Get Failed. Err: %v\n", err)
+		return nil, err
+	}
+	if len(cabRec) == 0 {
+		return nil, &notFoundError{kind: "cab", id: cabID}
+	}
+	return cabRec, nil
+}
@@ -0,0 +1,214 @@
+package mycabsservice
+
+import (
+	"fmt"
+	"mycabs/db"
+	"mycabs/mycabsapi"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+//The catalog of cab types is kept under hkeyValCabTypes, keyed by the lower
+//case name. Cabs can only be registered, and booked, with a type of the
+//catalog. A booking which allows it is upgraded, when no cab of its type is
+//idle, to the next class up which has at least its capabilities, and charged
+//the fare of the type it booked. Each type counts in Cabs its cabs which are
+//not retired, it is only taken out of the catalog while it counts none.
+
+const (
+	hkeyValCabTypes = "cabtypes/"
+)
+
+//SetCabType adds the cab type to the catalog, or replaces it. The cabs it
+//counts are kept.
+func SetCabType(req *mycabsapi.CabType) error {
+	updateInfo := map[string]*dynamodb.AttributeValue{
+		"Name":       db.StrToAttr(req.Name),
+		"Seats":      db.NumToAttr(req.Seats),
+		"Luggage":    db.NumToAttr(req.Luggage),
+		"Wheelchair": db.BoolToAttr(req.Wheelchair),
+		"ChildSeat":  db.BoolToAttr(req.ChildSeat),
+		"Class":      db.NumToAttr(req.Class),
+	}
+	err := db.Transact(tableName, []*db.TxWrite{{
+		Update:  cabTypeKeys(req.Name),
+		Updates: updateInfo,
+		Adds:    map[string]*dynamodb.AttributeValue{"Cabs": db.NumToAttr(0)},
+	}})
+	if err != nil {
+		fmt.Printf("SetCabType: db.Transact Failed. Err: %v\n", err)
+	}
+	return err
+}
+
+//CabType ...
+func CabType(req *mycabsapi.CabTypeRequest) (*mycabsapi.CabType, error) {
+	cabTypeRec, err := db.Get(tableName, cabTypeKeys(req.Name))
+	if err != nil {
+		fmt.Printf("CabType: db.Get Failed. Err: %v\n", err)
+		return nil, err
+	}
+	if len(cabTypeRec) == 0 {
+		return nil, &notFoundError{kind: "cab type", id: req.Name}
+	}
+	return toCabType(cabTypeRec), nil
+}
+
+//ListCabTypes ...
+func ListCabTypes(req *mycabsapi.ListCabTypesRequest) (*mycabsapi.ListCabTypesResponse, error) {
+	catalog, err := loadCabTypes()
+	if err != nil {
+		return nil, err
+	}
+	wanted := &mycabsapi.CabType{
+		Seats:      req.Seats,
+		Luggage:    req.Luggage,
+		Wheelchair: req.Wheelchair,
+		ChildSeat:  req.ChildSeat,
+	}
+	resp := &mycabsapi.ListCabTypesResponse{
+		CabTypes: []*mycabsapi.CabType{},
+	}
+	for _, cabType := range catalog {
+		if covers(cabType, wanted) {
+			resp.CabTypes = append(resp.CabTypes, cabType)
+		}
+	}
+	return resp, nil
+}
+
+//DeleteCabType takes the cab type out of the catalog, once it has no cab left
+//but retired ones.
+func DeleteCabType(req *mycabsapi.CabTypeRequest) error {
+	cond := map[string]*dynamodb.AttributeValue{
+		"Name": db.StrToAttr(req.Name),
+		"Cabs": db.NumToAttr(0),
+	}
+	err := db.Delete(tableName, cabTypeKeys(req.Name), cond)
+	if !db.IsConditionFailed(err) {
+		return err
+	}
+
+	cabTypeRec, err := db.Get(tableName, cabTypeKeys(req.Name))
+	if err != nil {
+		fmt.Printf("DeleteCabType: db.Get Failed. Err: %v\n", err)
+		return err
+	}
+	if len(cabTypeRec) == 0 {
+		return &notFoundError{kind: "cab type", id: req.Name}
+	}
+	cabs, _ := db.AttrToNum(cabTypeRec["Cabs"])
+	return &rejectedError{reason: fmt.Sprintf("%v cabs are still %v", cabs, req.Name)}
+}
+
+//checkCabType makes sure the cab type is in the catalog.
+func checkCabType(name string) error {
+	cabTypeRec, err := db.Get(tableName, cabTypeKeys(name))
+	if err != nil {
+		fmt.Printf("checkCabType: db.Get Failed. Err: %v\n", err)
+		return err
+	}
+	if len(cabTypeRec) == 0 {
+		return &rejectedError{reason: fmt.Sprintf("unknown cab type %q", name)}
+	}
+	return nil
+}
+
+//countCabType is the write counting by cabs more, or less, of the cab type.
+//Cabs are only counted up for a type of the catalog.
+func countCabType(name string, by int) *db.TxWrite {
+	write := &db.TxWrite{
+		Update: cabTypeKeys(name),
+		Adds:   map[string]*dynamodb.AttributeValue{"Cabs": db.NumToAttr(by)},
+	}
+	if by > 0 {
+		write.Cond = map[string]*dynamodb.AttributeValue{"Name": db.StrToAttr(name)}
+	}
+	return write
+}
+
+//upgradeCab books a cab of a higher class than the one asked for.
+func upgradeCab(req *mycabsapi.BookingRequest) (cab *mycabsapi.Cab, err error) {
+	catalog, err := loadCabTypes()
+	if err != nil {
+		return nil, err
+	}
+	for _, cabType := range upgradeTypes(req.CabType, catalog) {
+		cab, err = dispatchCab(req, cabType)
+		if err != nil || cab != nil {
+			return cab, err
+		}
+	}
+	return nil, nil
+}
+
+//upgradeTypes are the types of the catalog a booking of the cab type can be
+//upgraded to, the closest class first.
+func upgradeTypes(name string, catalog []*mycabsapi.CabType) []string {
+	var booked *mycabsapi.CabType
+	for _, cabType := range catalog {
+		if cabType.Name == name {
+			booked = cabType
+		}
+	}
+	if booked == nil {
+		return nil
+	}
+	types := []string{}
+	for _, cabType := range catalog {
+		if cabType.Class > booked.Class && covers(cabType, booked) {
+			types = append(types, cabType.Name)
+		}
+	}
+	return types
+}
+
+//covers tells if the cab type has at least the capabilities of wanted.
+func covers(cabType, wanted *mycabsapi.CabType) bool {
+	return cabType.Seats >= wanted.Seats &&
+		cabType.Luggage >= wanted.Luggage &&
+		(cabType.Wheelchair || !wanted.Wheelchair) &&
+		(cabType.ChildSeat || !wanted.ChildSeat)
+}
+
+//loadCabTypes returns the catalog in upgrade order.
+func loadCabTypes() ([]*mycabsapi.CabType, error) {
+	cabTypeRecords, err := db.Query(tableName, hkeyValCabTypes, nil)
+	if err != nil {
+		fmt.Printf("loadCabTypes: db.Query Failed. Err: %v\n", err)
+		return nil, err
+	}
+	catalog := make([]*mycabsapi.CabType, 0, len(cabTypeRecords))
+	for _, cabTypeRec := range cabTypeRecords {
+		catalog = append(catalog, toCabType(cabTypeRec))
+	}
+	sort.Slice(catalog, func(i, j int) bool {
+		if catalog[i].Class != catalog[j].Class {
+			return catalog[i].Class < catalog[j].Class
+		}
+		return catalog[i].Name < catalog[j].Name
+	})
+	return catalog, nil
+}
+
+//toCabType ...
+func toCabType(cabTypeRec map[string]*dynamodb.AttributeValue) *mycabsapi.CabType {
+	cabType := &mycabsapi.CabType{
+		Name: db.AttrToStr(cabTypeRec["Name"]),
+	}
+	cabType.Seats, _ = db.AttrToNum(cabTypeRec["Seats"])
+	cabType.Luggage, _ = db.AttrToNum(cabTypeRec["Luggage"])
+	cabType.Class, _ = db.AttrToNum(cabTypeRec["Class"])
+	cabType.Wheelchair = db.AttrToBool(cabTypeRec["Wheelchair"])
+	cabType.ChildSeat = db.AttrToBool(cabTypeRec["ChildSeat"])
+	return cabType
+}
+
+//cabTypeKeys ...
+func cabTypeKeys(name string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(hkeyValCabTypes),
+		db.RKeyName: db.StrToAttr(name),
+	}
+}
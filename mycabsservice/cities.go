@@ -0,0 +1,189 @@
+package mycabsservice
+
+import (
+	"fmt"
+	"mycabs/db"
+	"mycabs/mycabsapi"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+//A city is active from its onboarding, cities onboarded before the Active
+//attribute existed included. A deactivated city takes no new booking, nor
+//new cab, but the trips already running, the reservations already scheduled
+//and the tickets already waitlisted in it are still served.
+
+const (
+	cityStateActive   = "active"
+	cityStateInactive = "inactive"
+)
+
+//ListCities ...
+func ListCities(req *mycabsapi.ListCitiesRequest) (*mycabsapi.ListCitiesResponse, error) {
+	cityRecords, err := db.Query(tableName, hkeyValCities, nil)
+	if err != nil {
+		fmt.Printf("ListCities: db.Query Failed. Err: %v\n", err)
+		return nil, err
+	}
+
+	resp := &mycabsapi.ListCitiesResponse{
+		Cities: []*mycabsapi.City{},
+	}
+	for _, cityRec := range cityRecords {
+		city := toCity(cityRec)
+		if req.State == cityStateActive && !city.Active ||
+			req.State == cityStateInactive && city.Active {
+			continue
+		}
+		resp.Cities = append(resp.Cities, city)
+	}
+	sort.Slice(resp.Cities, func(i, j int) bool {
+		return resp.Cities[i].Name < resp.Cities[j].Name
+	})
+	return resp, nil
+}
+
+//City returns the city with its schedule and zones.
+func City(req *mycabsapi.CityRequest) (*mycabsapi.City, error) {
+	cityRec, err := loadCity(req.CityID)
+	if err != nil {
+		return nil, err
+	}
+	city := toCity(cityRec)
+
+	schedule, err := loadSchedule(req.CityID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule != nil {
+		city.Schedule = &schedule.CitySchedule
+	}
+	city.Zones, err = loadZones(req.CityID)
+	if err != nil {
+		return nil, err
+	}
+	return city, nil
+}
+
+//RenameCity ...
+func RenameCity(req *mycabsapi.RenameCityRequest) error {
+	updateInfo := map[string]*dynamodb.AttributeValue{
+		"Name": db.StrToAttr(req.Name),
+	}
+	cond := map[string]*dynamodb.AttributeValue{
+		"Id": db.StrToAttr(req.CityID),
+	}
+	err := db.UpdateExclusive(tableName, cityKeys(req.CityID), updateInfo, cond)
+	if db.IsConditionFailed(err) {
+		return &notFoundError{kind: "city", id: req.CityID}
+	}
+	return err
+}
+
+//DeactivateCity stops taking bookings in the city.
+func DeactivateCity(req *mycabsapi.CityRequest) error {
+	return setCityActive(req.CityID, false)
+}
+
+//ActivateCity takes bookings in a deactivated city again.
+func ActivateCity(req *mycabsapi.CityRequest) error {
+	return setCityActive(req.CityID, true)
+}
+
+//setCityActive ...
+func setCityActive(cityID string, active bool) error {
+	cityRec, err := loadCity(cityID)
+	if err != nil {
+		return err
+	}
+	if from := toCity(cityRec); from.Active == active {
+		return &invalidTransitionError{from: cityState(from.Active), to: cityState(active)}
+	}
+
+	updateInfo := map[string]*dynamodb.AttributeValue{
+		"Active":        db.BoolToAttr(active),
+		"DeactivatedAt": db.Num64ToAttr(0),
+	}
+	if !active {
+		updateInfo["DeactivatedAt"] = db.Num64ToAttr(time.Now().Unix())
+	}
+	cond := map[string]*dynamodb.AttributeValue{
+		"Id": db.StrToAttr(cityID),
+	}
+	err = db.UpdateExclusive(tableName, cityKeys(cityID), updateInfo, cond)
+	if db.IsConditionFailed(err) {
+		return &notFoundError{kind: "city", id: cityID}
+	}
+	return err
+}
+
+//checkCity makes sure the city exists and, when active is set, that it is
+//still in operation.
+func checkCity(cityID string, active bool) error {
+	cityRec, err := db.Get(tableName, cityKeys(cityID))
+	if err != nil {
+		fmt.Printf("checkCity: db.Get Failed. Err: %v\n", err)
+		return err
+	}
+	if len(cityRec) == 0 {
+		return &rejectedError{reason: fmt.Sprintf("unknown city %q", cityID)}
+	}
+	if active && !toCity(cityRec).Active {
+		return &rejectedError{reason: fmt.Sprintf("city %v is not in operation", cityID)}
+	}
+	return nil
+}
+
+//checkTripCities makes sure both ends of a new trip are in operation.
+func checkTripCities(from, to string) error {
+	err := checkCity(from, true)
+	if err != nil || to == from {
+		return err
+	}
+	return checkCity(to, true)
+}
+
+//loadCity ...
+func loadCity(cityID string) (map[string]*dynamodb.AttributeValue, error) {
+	cityRec, err := db.Get(tableName, cityKeys(cityID))
+	if err != nil {
+		fmt.Printf("loadCity: db.Get Failed. Err: %v\n", err)
+		return nil, err
+	}
+	if len(cityRec) == 0 {
+		return nil, &notFoundError{kind: "city", id: cityID}
+	}
+	return cityRec, nil
+}
+
+//cityState ...
+func cityState(active bool) string {
+	if active {
+		return cityStateActive
+	}
+	return cityStateInactive
+}
+
+//toCity ...
+func toCity(cityRec map[string]*dynamodb.AttributeValue) *mycabsapi.City {
+	city := &mycabsapi.City{
+		ID:     db.AttrToStr(cityRec["Id"]),
+		Name:   db.AttrToStr(cityRec["Name"]),
+		Active: true,
+	}
+	city.Bookings, _ = db.AttrToNum64(cityRec["Bookings"])
+	if attrVal, ok := cityRec["Active"]; ok {
+		city.Active = db.AttrToBool(attrVal)
+	}
+	if attrVal, ok := cityRec["Dispatch"]; ok {
+		city.Dispatch = db.AttrToStr(attrVal)
+	}
+	if attrVal, ok := cityRec["DeactivatedAt"]; ok {
+		if deactivatedAt, _ := db.AttrToNum64(attrVal); deactivatedAt > 0 {
+			city.DeactivatedAt = time.Unix(deactivatedAt, 0).UTC().Format(time.RFC3339)
+		}
+	}
+	return city
+}
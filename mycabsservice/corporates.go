@@ -0,0 +1,474 @@
+package mycabsservice
+
+import (
+	"fmt"
+	"mycabs/db"
+	"mycabs/fare"
+	"mycabs/mycabsapi"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+//Corporate accounts are kept under hkeyValCorporates with their spending
+//policy. A rider is a member of one account at most, held in the CorporateID
+//of the rider and listed under the members partition of the account. A
+//booking made on the account is checked against the policy in BookCab, and
+//again when a waitlisted one is served, and the trip carries the CorporateID
+//so that it is charged to the account, not to the rider, when it is settled.
+//
+//When a cab is assigned to a booking on an account with a monthly cap, the
+//estimated fare is held on the spend of the month, only if it fits under the
+//cap, so that bookings made together can't go over it. The hold is kept on
+//the trip and swapped for the fare when the trip ends.
+
+const (
+	hkeyValCorporates       = "corporates/"
+	hkeyValCorporateSpend   = "corporatespend/"
+	hkeyValCorporateMembers = "corporatemembers/"
+)
+
+//corporatePolicy is the policy of a corporate record.
+type corporatePolicy struct {
+	CabTypes   []string
+	Cities     []string
+	FromHour   int
+	ToHour     int
+	Location   *time.Location
+	MonthlyCap int64
+}
+
+//CreateCorporate ...
+func CreateCorporate(req *mycabsapi.CreateCorporateRequest) (corporateID string, err error) {
+	seq, err := db.Increment(tableName, counterKeys("corporate"), "Counter", 1)
+	if err != nil {
+		fmt.Printf("CreateCorporate: db.Increment Failed. Err: %v\n", err)
+		return "", err
+	}
+	corporateID = "corporate_" + strconv.Itoa(seq)
+
+	corporateRecord := corporateKeys(corporateID)
+	corporateRecord["Id"] = db.StrToAttr(corporateID)
+	corporateRecord["Name"] = db.StrToAttr(req.Name)
+	corporateRecord["CreatedAt"] = db.Num64ToAttr(time.Now().Unix())
+	for attr, attrVal := range policyAttrs(req.Policy) {
+		corporateRecord[attr] = attrVal
+	}
+
+	err = db.Put(tableName, corporateRecord)
+	if err != nil {
+		fmt.Printf("CreateCorporate: db.Put Failed. Err: %v\n", err)
+		return "", err
+	}
+	return corporateID, nil
+}
+
+//Corporate ...
+func Corporate(req *mycabsapi.CorporateRequest) (*mycabsapi.Corporate, error) {
+	corporateRec, err := loadCorporate(req.CorporateID)
+	if err != nil {
+		return nil, err
+	}
+	corporate := &mycabsapi.Corporate{
+		ID:      req.CorporateID,
+		Name:    db.AttrToStr(corporateRec["Name"]),
+		Policy:  toCorporatePolicy(corporateRec),
+		Members: []string{},
+	}
+	corporate.Spent, err = corporateSpend(req.CorporateID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	memberRecords, _, err := db.QueryPage(tableName, corporateMembersHKey(req.CorporateID), nil, &db.Page{})
+	if err != nil {
+		fmt.Printf("Corporate: db.QueryPage Failed. Err: %v\n", err)
+		return nil, err
+	}
+	for _, memberRec := range memberRecords {
+		corporate.Members = append(corporate.Members, db.AttrToStr(memberRec[db.RKeyName]))
+	}
+	sort.Strings(corporate.Members)
+	return corporate, nil
+}
+
+//SetCorporatePolicy replaces the policy of the account, for the bookings made from now.
+func SetCorporatePolicy(req *mycabsapi.SetCorporatePolicyRequest) error {
+	corporateRec, err := loadCorporate(req.CorporateID)
+	if err != nil {
+		return err
+	}
+	//Written over as a whole, limits left out of the new policy are lifted.
+	delete(corporateRec, "CabTypes")
+	delete(corporateRec, "Cities")
+	for attr, attrVal := range policyAttrs(req.Policy) {
+		corporateRec[attr] = attrVal
+	}
+	//Only over the policy read, not over one set meanwhile.
+	write := &db.TxWrite{Put: corporateRec}
+	version := int64(0)
+	if attrVal, ok := corporateRec["PolicyVersion"]; ok {
+		version, _ = db.AttrToNum64(attrVal)
+		write.Cond = map[string]*dynamodb.AttributeValue{"PolicyVersion": attrVal}
+	} else {
+		write.Absent = []string{"PolicyVersion"}
+	}
+	corporateRec["PolicyVersion"] = db.Num64ToAttr(version + 1)
+
+	err = db.Transact(tableName, []*db.TxWrite{write})
+	if db.IsConditionFailed(err) {
+		return &conflictError{kind: "corporate", id: req.CorporateID}
+	}
+	if err != nil {
+		fmt.Printf("SetCorporatePolicy: db.Transact Failed. Err: %v\n", err)
+	}
+	return err
+}
+
+//AddCorporateMember lets the rider book on the account.
+func AddCorporateMember(req *mycabsapi.CorporateMemberRequest) error {
+	_, err := loadCorporate(req.CorporateID)
+	if err != nil {
+		return err
+	}
+	updateInfo := map[string]*dynamodb.AttributeValue{
+		"CorporateID": db.StrToAttr(req.CorporateID),
+	}
+	err = db.UpdateIfEmpty(tableName, riderKeys(req.RiderID), updateInfo, "CorporateID")
+	if db.IsConditionFailed(err) {
+		riderRec, err := loadRider(req.RiderID)
+		if err != nil {
+			return err
+		}
+		//Added already, maybe by a call which failed to list the member.
+		if db.AttrToStr(riderRec["CorporateID"]) != req.CorporateID {
+			return &rejectedError{reason: fmt.Sprintf("%v is a member of %v", req.RiderID, db.AttrToStr(riderRec["CorporateID"]))}
+		}
+	} else if err != nil {
+		fmt.Printf("AddCorporateMember: db.UpdateIfEmpty Failed. Err: %v\n", err)
+		return err
+	}
+
+	err = db.Put(tableName, corporateMemberRecord(req.CorporateID, req.RiderID))
+	if err != nil {
+		fmt.Printf("AddCorporateMember: db.Put of member Failed. Err: %v\n", err)
+	}
+	return err
+}
+
+//RemoveCorporateMember stops the rider from booking on the account. Trips
+//already booked on it are still billed to it.
+func RemoveCorporateMember(req *mycabsapi.CorporateMemberRequest) error {
+	updateInfo := map[string]*dynamodb.AttributeValue{
+		"CorporateID": db.StrToAttr(""),
+	}
+	cond := map[string]*dynamodb.AttributeValue{
+		"CorporateID": db.StrToAttr(req.CorporateID),
+	}
+	err := db.UpdateExclusive(tableName, riderKeys(req.RiderID), updateInfo, cond)
+	if db.IsConditionFailed(err) {
+		return &notFoundError{kind: "member", id: req.CorporateID + "/" + req.RiderID}
+	}
+	if err != nil {
+		return err
+	}
+
+	err = db.Delete(tableName, corporateMemberRecord(req.CorporateID, req.RiderID), nil)
+	if err != nil {
+		fmt.Printf("RemoveCorporateMember: db.Delete of member Failed. Err: %v\n", err)
+	}
+	return err
+}
+
+//CorporateStatement gathers the receipts of the trips billed to the account
+//which ended in the month.
+func CorporateStatement(req *mycabsapi.StatementRequest) (*mycabsapi.Statement, error) {
+	corporateRec, err := loadCorporate(req.CorporateID)
+	if err != nil {
+		return nil, err
+	}
+	month, _ := time.Parse(invoiceMonthLayout, req.Month)
+	tripRecords, err := monthTrips(req.CorporateID, month)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := &mycabsapi.Statement{
+		StatementNo:   "STMT-" + req.CorporateID + "-" + month.Format("200601"),
+		CorporateID:   req.CorporateID,
+		CorporateName: db.AttrToStr(corporateRec["Name"]),
+		Month:         req.Month,
+		Receipts:      []*mycabsapi.Receipt{},
+		Members:       []*mycabsapi.StatementMember{},
+		Totals:        []*mycabsapi.InvoiceTotal{},
+	}
+	members := map[string]*mycabsapi.StatementMember{}
+	names := newReceiptNames()
+	for _, tripRec := range tripRecords {
+		rcpt := names.receipt(tripRec)
+		rcpt.RiderName = names.lookup(names.riders, riderKeys(rcpt.RiderID))
+		stmt.Receipts = append(stmt.Receipts, rcpt)
+
+		member, ok := members[rcpt.RiderID]
+		if !ok {
+			member = &mycabsapi.StatementMember{RiderID: rcpt.RiderID, RiderName: rcpt.RiderName, Due: map[string]int64{}}
+			members[rcpt.RiderID] = member
+			stmt.Members = append(stmt.Members, member)
+		}
+		member.Trips++
+		if rcpt.Fare != nil {
+			member.Due[rcpt.Fare.Currency] += rcpt.Fare.Total - rcpt.Refunded
+		}
+		stmt.Totals = addToTotals(stmt.Totals, rcpt)
+	}
+	sort.Slice(stmt.Members, func(i, j int) bool {
+		return stmt.Members[i].RiderID < stmt.Members[j].RiderID
+	})
+	return stmt, nil
+}
+
+//checkCorporateBooking makes sure the rider can book the trip on the account
+//at the given time. The monthly cap is checked when the cab is assigned.
+func checkCorporateBooking(req *mycabsapi.BookingRequest, at time.Time) error {
+	riderRec, err := loadRider(req.RiderID)
+	if err != nil {
+		return err
+	}
+	if attrVal, ok := riderRec["CorporateID"]; !ok || db.AttrToStr(attrVal) != req.CorporateID {
+		return &rejectedError{reason: fmt.Sprintf("%v is not a member of %v", req.RiderID, req.CorporateID)}
+	}
+	corporateRec, err := loadCorporate(req.CorporateID)
+	if err != nil {
+		return err
+	}
+
+	return attrsToPolicy(corporateRec).allows(req.From, req.CabType, at)
+}
+
+//holdCorporateSpend holds the estimated fare of the booking on the spend of
+//the month, if the account has a cap and the fare fits under it, and returns
+//the amount held.
+func holdCorporateSpend(req *mycabsapi.BookingRequest, at time.Time) (int64, error) {
+	if req.CorporateID == "" {
+		return 0, nil
+	}
+	corporateRec, err := loadCorporate(req.CorporateID)
+	if err != nil {
+		return 0, err
+	}
+	policy := attrsToPolicy(corporateRec)
+	if policy.MonthlyCap == 0 {
+		return 0, nil
+	}
+	tariff, err := loadTariff(req.From, req.CabType)
+	if err != nil {
+		return 0, err
+	}
+	//The least the trip costs, the drop is not known yet.
+	estimate := tariff.Compute(&fare.Trip{
+		From:  req.From,
+		To:    req.To,
+		Surge: surgeMultiplier(req.From, req.CabType),
+	}).Total
+
+	_, err = db.IncrementWithin(tableName, corporateSpendKeys(req.CorporateID, at), "Spent", estimate, policy.MonthlyCap)
+	if db.IsConditionFailed(err) {
+		return 0, &rejectedError{reason: fmt.Sprintf("%v has spent its monthly cap of %v", req.CorporateID, policy.MonthlyCap)}
+	}
+	if err != nil {
+		fmt.Printf("holdCorporateSpend: db.IncrementWithin for %v Failed. Err: %v\n", req.CorporateID, err)
+		return 0, err
+	}
+	return estimate, nil
+}
+
+//allows checks the city, cab type and hour of a booking against the policy.
+func (policy *corporatePolicy) allows(cityID, cabType string, at time.Time) error {
+	if len(policy.Cities) > 0 && !contains(policy.Cities, cityID) {
+		return &rejectedError{reason: fmt.Sprintf("the corporate policy does not allow bookings in %v", cityID)}
+	}
+	if len(policy.CabTypes) > 0 && !contains(policy.CabTypes, cabType) {
+		return &rejectedError{reason: fmt.Sprintf("the corporate policy does not allow %v cabs", cabType)}
+	}
+	if !inHours(at.In(policy.Location).Hour(), policy.FromHour, policy.ToHour) {
+		return &rejectedError{reason: fmt.Sprintf("the corporate policy allows bookings from %d:00 to %d:00 only", policy.FromHour, policy.ToHour)}
+	}
+	return nil
+}
+
+//settleCorporateSpend swaps the amount held at booking for the amount the
+//trip cost, in the month of at. Nothing is held on accounts without a cap.
+func settleCorporateSpend(corporateID string, held int64, bookedAt, at time.Time, amount int64) {
+	if corporateID == "" {
+		return
+	}
+	months, deltas := []map[string]*dynamodb.AttributeValue{}, map[string]int64{}
+	for _, change := range []struct {
+		at     time.Time
+		amount int64
+	}{{bookedAt, -held}, {at, amount}} {
+		keys := corporateSpendKeys(corporateID, change.at)
+		month := db.AttrToStr(keys[db.RKeyName])
+		if _, ok := deltas[month]; !ok {
+			months = append(months, keys)
+		}
+		deltas[month] += change.amount
+	}
+
+	writes := []*db.TxWrite{}
+	for _, keys := range months {
+		delta := deltas[db.AttrToStr(keys[db.RKeyName])]
+		if delta == 0 {
+			continue
+		}
+		writes = append(writes, &db.TxWrite{
+			Update: keys,
+			Adds: map[string]*dynamodb.AttributeValue{
+				"Spent": db.Num64ToAttr(delta),
+			},
+		})
+	}
+	if len(writes) == 0 {
+		return
+	}
+	err := db.Transact(tableName, writes)
+	if err != nil {
+		fmt.Printf("settleCorporateSpend: db.Transact for %v Failed. Err: %v\n", corporateID, err)
+	}
+}
+
+//corporateSpend is what the account spent in the month of the given time.
+func corporateSpend(corporateID string, at time.Time) (int64, error) {
+	spendRec, err := db.Get(tableName, corporateSpendKeys(corporateID, at))
+	if err != nil {
+		fmt.Printf("corporateSpend: db.Get Failed. Err: %v\n", err)
+		return 0, err
+	}
+	spent := int64(0)
+	if attrVal, ok := spendRec["Spent"]; ok {
+		spent, _ = db.AttrToNum64(attrVal)
+	}
+	return spent, nil
+}
+
+//policyAttrs are the attributes storing the policy in the corporate record.
+func policyAttrs(policy *mycabsapi.CorporatePolicy) map[string]*dynamodb.AttributeValue {
+	if policy == nil {
+		policy = &mycabsapi.CorporatePolicy{}
+	}
+	attrs := map[string]*dynamodb.AttributeValue{
+		"FromHour":   db.NumToAttr(policy.FromHour),
+		"ToHour":     db.NumToAttr(policy.ToHour),
+		"TimeZone":   db.StrToAttr(policy.TimeZone),
+		"MonthlyCap": db.Num64ToAttr(policy.MonthlyCap),
+	}
+	if len(policy.CabTypes) > 0 {
+		attrs["CabTypes"] = db.StrSetToAttr(policy.CabTypes)
+	}
+	if len(policy.Cities) > 0 {
+		attrs["Cities"] = db.StrSetToAttr(policy.Cities)
+	}
+	return attrs
+}
+
+//attrsToPolicy ...
+func attrsToPolicy(corporateRec map[string]*dynamodb.AttributeValue) *corporatePolicy {
+	policy := &corporatePolicy{Location: time.UTC}
+	policy.FromHour, _ = db.AttrToNum(corporateRec["FromHour"])
+	policy.ToHour, _ = db.AttrToNum(corporateRec["ToHour"])
+	policy.MonthlyCap, _ = db.AttrToNum64(corporateRec["MonthlyCap"])
+	if loc, err := time.LoadLocation(db.AttrToStr(corporateRec["TimeZone"])); err == nil {
+		policy.Location = loc
+	}
+	if attrVal, ok := corporateRec["CabTypes"]; ok {
+		policy.CabTypes = db.AttrToStrSet(attrVal)
+	}
+	if attrVal, ok := corporateRec["Cities"]; ok {
+		policy.Cities = db.AttrToStrSet(attrVal)
+	}
+	return policy
+}
+
+//toCorporatePolicy ...
+func toCorporatePolicy(corporateRec map[string]*dynamodb.AttributeValue) *mycabsapi.CorporatePolicy {
+	policy := attrsToPolicy(corporateRec)
+	return &mycabsapi.CorporatePolicy{
+		CabTypes:   policy.CabTypes,
+		Cities:     policy.Cities,
+		FromHour:   policy.FromHour,
+		ToHour:     policy.ToHour,
+		TimeZone:   db.AttrToStr(corporateRec["TimeZone"]),
+		MonthlyCap: policy.MonthlyCap,
+	}
+}
+
+//corporateMemberRecord lists the rider in the members partition of the account.
+func corporateMemberRecord(corporateID, riderID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(corporateMembersHKey(corporateID)),
+		db.RKeyName: db.StrToAttr(riderID),
+	}
+}
+
+//corporateMembersHKey is the partition listing the members of an account.
+func corporateMembersHKey(corporateID string) string {
+	return hkeyValCorporateMembers + corporateID + "/"
+}
+
+//corporateAttrs are the attributes keeping the account a booking is billed to.
+func corporateAttrs(corporateID string) map[string]*dynamodb.AttributeValue {
+	if corporateID == "" {
+		return nil
+	}
+	return map[string]*dynamodb.AttributeValue{
+		"CorporateID": db.StrToAttr(corporateID),
+	}
+}
+
+//attrsToCorporateID is the account the booking is billed to, empty if none.
+func attrsToCorporateID(rec map[string]*dynamodb.AttributeValue) string {
+	if attrVal, ok := rec["CorporateID"]; ok {
+		return db.AttrToStr(attrVal)
+	}
+	return ""
+}
+
+//corporateHeld is the spend held on the account for the trip, 0 if none.
+func corporateHeld(tripRec map[string]*dynamodb.AttributeValue) int64 {
+	held := int64(0)
+	if attrVal, ok := tripRec["CorporateHeld"]; ok {
+		held, _ = db.AttrToNum64(attrVal)
+	}
+	return held
+}
+
+//loadCorporate ...
+func loadCorporate(corporateID string) (map[string]*dynamodb.AttributeValue, error) {
+	corporateRec, err := db.Get(tableName, corporateKeys(corporateID))
+	if err != nil {
+		fmt.Printf("loadCorporate: db.Get Failed. Err: %v\n", err)
+		return nil, err
+	}
+	if len(corporateRec) == 0 {
+		return nil, &notFoundError{kind: "corporate", id: corporateID}
+	}
+	return corporateRec, nil
+}
+
+//corporateKeys ...
+func corporateKeys(corporateID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(hkeyValCorporates),
+		db.RKeyName: db.StrToAttr(corporateID),
+	}
+}
+
+//corporateSpendKeys is the spend of the account in the month (UTC) of the given time.
+func corporateSpendKeys(corporateID string, at time.Time) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(hkeyValCorporateSpend + corporateID + "/"),
+		db.RKeyName: db.StrToAttr(at.UTC().Format(invoiceMonthLayout)),
+	}
+}
@@ -1,17 +1,16 @@
 package mycabsservice
 
 import (
-	"errors"
 	"fmt"
-	"math/rand"
+	"mycabs/analytics"
 	"mycabs/db"
 	"mycabs/lease"
 	"mycabs/mycabsapi"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
-	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 )
 
@@ -34,9 +33,18 @@ const (
 )
 
 const (
-	stateIdle     = "IDLE"
-	stateOnTrip   = "ON_TRIP"
-	stateInActive = "IN_ACTIVE"
+	stateIdle        = "IDLE"
+	stateAssigned    = "ASSIGNED" //Booked, rider not picked up yet.
+	stateArriving    = "ARRIVING" //On the way to the pickup.
+	stateOnTrip      = "ON_TRIP"
+	stateInActive    = "IN_ACTIVE"
+	stateMaintenance = "MAINTENANCE"
+	stateRetired     = "RETIRED"
+)
+
+const (
+	partyRider  = "rider"
+	partyDriver = "driver"
 )
 
 //////////////// Fucntions which are directly called by Service///////////////////////
@@ -50,17 +58,19 @@ func OnboardCity(citiReq *mycabsapi.OnboardCityRequest) (cityID string, err erro
 		return cityID, err
 	}
 
-	cityRecord := make(map[string]*dynamodb.AttributeValue)
-	cityRecord[db.HKeyName] = db.StrToAttr(hkeyValCities)
-	cityRecord[db.RKeyName] = db.StrToAttr(cityID)
-	cityRecord["Id"] = db.StrToAttr(cityID)
-	cityRecord["Name"] = db.StrToAttr(citiReq.Name)
-	cityRecord["Bookings"] = db.Num64ToAttr(int64(0))
-
-	//Store city into DB
-	err = db.Put(tableName, cityRecord)
+	//Store city into DB, with its launch promo if any, a taken code failing the
+	//onboarding.
+	writes := []*db.TxWrite{{Put: newCityRecord(cityID, citiReq.Name)}}
+	if citiReq.LaunchPromo != nil {
+		citiReq.LaunchPromo.Cities = []string{cityID}
+		writes = append(writes, &db.TxWrite{Put: newPromoRecord(citiReq.LaunchPromo), New: true})
+	}
+	err = db.Transact(tableName, writes)
+	if db.IsConditionFailed(err) {
+		return cityID, promoTakenError(citiReq.LaunchPromo)
+	}
 	if err != nil {
-		fmt.Printf("OnboardCity: db.Put Failed. Err: %v\n", err)
+		fmt.Printf("OnboardCity: db.Transact Failed. Err: %v\n", err)
 		return cityID, err
 	}
 
@@ -69,6 +79,15 @@ func OnboardCity(citiReq *mycabsapi.OnboardCityRequest) (cityID string, err erro
 
 //RegisterCab ...
 func RegisterCab(req *mycabsapi.RegisterCabRequest) (cabID string, err error) {
+	err = checkCabType(req.Type)
+	if err != nil {
+		return "", err
+	}
+	err = checkCity(req.CityID, true)
+	if err != nil {
+		return "", err
+	}
+
 	//Generate New EmployeeID.
 	cabID, err = getNewCabID()
 	if err != nil {
@@ -76,6 +95,42 @@ func RegisterCab(req *mycabsapi.RegisterCabRequest) (cabID string, err error) {
 		return cabID, err
 	}
 
+	//Store the cab into DB, counted for its type as long as the type is still
+	//in the catalog, along with its entry in the cell it is in.
+	cabRecord := newCabRecord(cabID, req)
+	writes := []*db.TxWrite{
+		{Put: cabRecord, New: true},
+		countCabType(req.Type, 1),
+	}
+	if cell := cabGeoCell(cabRecord); cell != "" {
+		writes = append(writes, &db.TxWrite{Put: cellRecord(cell, cabID)})
+	}
+	err = db.Transact(tableName, append(writes, fleetIndexWrites(nil, cabRecord)...))
+	if db.IsConditionFailed(err) {
+		return "", &rejectedError{reason: fmt.Sprintf("unknown cab type %q", req.Type)}
+	}
+	if err != nil {
+		fmt.Printf("RegisterCab: db.Transact Failed. Err: %v\n", err)
+		return cabID, err
+	}
+
+	return cabID, nil
+}
+
+//newCityRecord ...
+func newCityRecord(cityID, name string) map[string]*dynamodb.AttributeValue {
+	cityRecord := make(map[string]*dynamodb.AttributeValue)
+	cityRecord[db.HKeyName] = db.StrToAttr(hkeyValCities)
+	cityRecord[db.RKeyName] = db.StrToAttr(cityID)
+	cityRecord["Id"] = db.StrToAttr(cityID)
+	cityRecord["Name"] = db.StrToAttr(name)
+	cityRecord["Bookings"] = db.Num64ToAttr(int64(0))
+	cityRecord["Active"] = db.BoolToAttr(true)
+	return cityRecord
+}
+
+//newCabRecord is the record of a cab just registered, IDLE.
+func newCabRecord(cabID string, req *mycabsapi.RegisterCabRequest) map[string]*dynamodb.AttributeValue {
 	curTime := time.Now().Unix()
 	historyRec := fmt.Sprintf("%v. State: %v | From Time: %v", 0, stateIdle, time.Now())
 
@@ -91,346 +146,561 @@ func RegisterCab(req *mycabsapi.RegisterCabRequest) (cabID string, err error) {
 	cabRecord["ToCityID"] = db.StrToAttr("")         //A workaround to avoid separate booking record as of now.
 	cabRecord["History"] = db.StrSetToAttr([]string{historyRec})
 	cabRecord["PrevIdleWaiting"] = db.Num64ToAttr(0)
+	if req.Plate != "" {
+		cabRecord["Plate"] = db.StrToAttr(req.Plate)
+	}
+	if req.Seats > 0 {
+		cabRecord["Seats"] = db.NumToAttr(req.Seats)
+	}
+	if req.Location != nil {
+		for attr, attrVal := range locationAttrs(req.Location) {
+			cabRecord[attr] = attrVal
+		}
+	}
 
 	//Add the lease value with 0, lease will be used in distributed synchronization.
 	//This can be optimized by not setting it now and handling it lease load.
 	cabRecord["Lease"] = db.Num64ToAttr(int64(0))
+	return cabRecord
+}
 
-	//Store city into DB
-	err = db.Put(tableName, cabRecord)
+//BookCab ...
+func BookCab(req *mycabsapi.BookingRequest) (cab *mycabsapi.Cab, err error) {
+	err = checkCabType(req.CabType)
 	if err != nil {
-		fmt.Printf("RegisterCab: db.Put Failed. Err: %v\n", err)
-		return cabID, err
+		return nil, err
 	}
+	err = checkTripCities(req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+	_, err = checkService(req.From, req.Pickup, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	_, err = checkPromo(req.PromoCode, req.RiderID, req.From, req.CabType, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if req.CorporateID != "" {
+		err = checkCorporateBooking(req, time.Now())
+		if err != nil {
+			return nil, err
+		}
+	}
+	countDemand(req.From, req.CabType)
+	return bookCab(req)
+}
 
-	return cabID, nil
+//bookCab books a cab without counting the request as demand, for bookings
+//which were planned ahead.
+func bookCab(req *mycabsapi.BookingRequest) (cab *mycabsapi.Cab, err error) {
+	err = claimRider(req.RiderID, riderBooking)
+	if err != nil {
+		return nil, err
+	}
+
+	cab, err = dispatchCab(req, req.CabType)
+	if err == nil && cab == nil && req.AllowUpgrade {
+		cab, err = upgradeCab(req)
+	}
+	if err != nil || cab == nil {
+		releaseRider(req.RiderID, riderBooking)
+		return cab, err
+	}
+	err = handOverRider(req.RiderID, riderBooking, cab.TripID)
+	if err != nil {
+		fmt.Printf("bookCab: handOverRider of %v Failed. Err: %v\n", req.RiderID, err)
+	}
+	return cab, nil
 }
 
-//BookCab ...
-func BookCab(req *mycabsapi.BookingRequest) (cab *mycabsapi.Cab, err error) {
+//dispatchCab assigns a cab of the city and of the cab type to the request.
+func dispatchCab(req *mycabsapi.BookingRequest, cabType string) (cab *mycabsapi.Cab, err error) {
 	//Bring in the list of cabs which are idle and available in the city.
-	//Sort them by idle time and assigns the cab with the most idle time.
+	//Rank them with the dispatch strategy of the city and assign the first
+	//of them which can still be booked.
 
-	filter := map[string]*dynamodb.Condition{
-		"CityID": &dynamodb.Condition{
-			ComparisonOperator: aws.String("EQ"),
-			AttributeValueList: []*dynamodb.AttributeValue{db.StrToAttr(req.From)},
-		},
-		"Type": &dynamodb.Condition{
-			ComparisonOperator: aws.String("EQ"),
-			AttributeValueList: []*dynamodb.AttributeValue{db.StrToAttr(req.CabType)},
-		},
-		"State": &dynamodb.Condition{
-			ComparisonOperator: aws.String("EQ"),
-			AttributeValueList: []*dynamodb.AttributeValue{db.StrToAttr(stateIdle)},
-		},
-	}
+	strategy := cityDispatchStrategy(req.From)
+	tried := map[string]bool{}
 
-	cabRecords, err := db.Query(tableName, hkeyValCabs, filter)
+	if _, nearest := strategy.(nearestStrategy); nearest && req.Pickup != nil {
+		//Look in the cells around the pickup first, the whole city only if
+		//none of the cabs around can be booked.
+		cabRecords, err := nearbyIdleCabs(req.From, cabType, req.Pickup)
+		if err != nil {
+			return nil, err
+		}
+		cab, err = assignFirstCab(req, strategy, cabRecords, tried)
+		if err != nil || cab != nil {
+			return cab, err
+		}
+	}
 
+	cabRecords, _, err := db.QueryPage(tableName, hkeyValCabs, idleCabsFilter(req.From, cabType), &db.Page{})
 	if err != nil {
-		fmt.Printf("BookCab: db.Query failed. Err: %v\n", err)
+		fmt.Printf("BookCab: db.QueryPage failed. Err: %v\n", err)
 		return nil, err
 	}
-	if len(cabRecords) == 0 {
+	return assignFirstCab(req, strategy, cabRecords, tried)
+}
+
+//assignFirstCab ranks the cabs not tried yet and assigns the first of them
+//which can still be booked, nil when none can.
+func assignFirstCab(req *mycabsapi.BookingRequest, strategy DispatchStrategy, cabRecords []map[string]*dynamodb.AttributeValue, tried map[string]bool) (*mycabsapi.Cab, error) {
+	currTime := time.Now()
+	candidates := make([]*CandidateCab, 0, len(cabRecords))
+	for _, cabRec := range cabRecords {
+		candidate := newCandidateCab(cabRec, currTime)
+		if tried[candidate.ID] {
+			continue
+		}
+		candidate.setDistance(req.Pickup)
+		candidates = append(candidates, candidate)
+	}
+	if len(candidates) == 0 {
 		fmt.Printf("BookCab: No cabs found for the given criteria\n")
 		return nil, nil
 	}
 
-	cabRecIndex := []int{}
-	maxIdleWaiting := int64(0)
-	currTime := time.Now().Unix()
-
-	for idx, cabRec := range cabRecords {
-		prevIdleWaiting, err := db.AttrToNum64(cabRec["PrevIdleWaiting"])
-		if err != nil {
-			fmt.Printf("BookCab: db.AttrToNum64 of PrevIdleWaiting Failed. Err: %v\n", err)
+	//Cabs held back by their rating go last, or not at all.
+	ranked := cityRatingPolicy(req.From).apply(strategy.Rank(req, candidates))
+	for _, candidate := range ranked {
+		tried[candidate.ID] = true
+		tripID, err := assignCab(req, candidate)
+		if _, taken := err.(*busyError); taken {
+			//The cab was taken by another booking meanwhile, try the next one.
+			fmt.Printf("BookCab: assignCab of %v failed. Err: %v\n", candidate.ID, err)
+			continue
 		}
-
-		idleSince, err := db.AttrToNum64(cabRec["IdleSince"])
 		if err != nil {
-			fmt.Printf("BookCab: db.AttrToNum64 of IdleSince failed. Err: %v\n", err)
+			fmt.Printf("BookCab: assignCab of %v failed. Err: %v\n", candidate.ID, err)
 			return nil, err
 		}
 
-		totalIdleWaiting := prevIdleWaiting + (currTime - idleSince)
-
-		if totalIdleWaiting > maxIdleWaiting {
-			//Clear the cabRecIndex and store the new
-			cabRecIndex = cabRecIndex[:0]
-			maxIdleWaiting = totalIdleWaiting
-			cabRecIndex = append(cabRecIndex, idx)
-		} else if totalIdleWaiting == maxIdleWaiting {
-			//just add this rec also to the cabRecIndex
-			cabRecIndex = append(cabRecIndex, idx)
+		//Now once the state of the cab is changed to ASSIGNED, it is ensured that
+		//that is booking is successful, returning it.
+		cab := &mycabsapi.Cab{}
+		cab.ID = candidate.ID
+		cab.Name = candidate.Name
+		cab.Type = candidate.Type
+		cab.TripID = tripID
+		cab.DriverID = candidate.DriverID
+		if candidate.Distance >= 0 {
+			cab.Distance = candidate.Distance
 		}
-	}
 
-	//Logic to pick cab with max idle time / radom of them in case of clash.
-	cabIdx := 0
-	numRecs := len(cabRecIndex)
-	if numRecs == 0 {
-		//Ideally shouldn't happen
-		errmsg := fmt.Sprintf("BookCab: Failed to get the cabs.")
-		fmt.Println(errmsg)
-		return nil, errors.New(errmsg)
-	} else if numRecs == 1 {
-		cabIdx = cabRecIndex[0]
-	} else {
-		cabIdx = cabRecIndex[rand.Intn(numRecs)]
+		return cab, nil
 	}
 
-	keys := map[string]*dynamodb.AttributeValue{
-		db.HKeyName: db.StrToAttr(hkeyValCabs),
-		db.RKeyName: cabRecords[cabIdx]["Id"],
-	}
+	fmt.Printf("BookCab: None of the %v cabs could be booked\n", len(candidates))
+	return nil, nil
+}
 
-	//Now once the cab is computed, Immeditely take lease on it.
-	ls, err := lease.Load(tableName, keys)
+//assignCab books the candidate cab for the request and returns the ID of the new
+//trip. It returns a busyError when the cab was taken by someone else meanwhile.
+func assignCab(req *mycabsapi.BookingRequest, candidate *CandidateCab) (tripID string, err error) {
+	//Immeditely take lease on the cab.
+	ls, err := lease.Load(tableName, cabKeys(candidate.ID))
+	if err == lease.ErrBusy {
+		return "", &busyError{kind: "cab", id: candidate.ID, with: "another booking"}
+	}
 	if err != nil {
-		//Improvement TODO: There could be a retry mechanism here which can check if there are
-		//any other available cabs matching the criteria.
-
-		fmt.Printf("BookCab: lease.Load failed. Err: %v\n", err)
-		return nil, err
+		return "", err
 	}
 	abort := make(chan int)
 	go ls.Renew(abort)
 	defer ls.Release()
 	defer close(abort)
 
-	//Cab History
-	history := db.AttrToStrSet(cabRecords[cabIdx]["History"])
-	histRec := fmt.Sprintf("%v. State: %v | Traveling From: %v to %v | StartTime: %v", len(history), stateOnTrip, req.From, req.To, time.Now())
-	history = append(history, histRec)
-
-	//Update the state of the cab in DB
-	updateInfo := map[string]*dynamodb.AttributeValue{
-		"State":           db.StrToAttr(stateOnTrip),
-		"ToCityID":        db.StrToAttr(req.To),
-		"History":         db.StrSetToAttr(history),
-		"PrevIdleWaiting": db.Num64ToAttr(maxIdleWaiting),
-		"IdleSince":       db.Num64ToAttr(0),
-	}
-	cond := map[string]*dynamodb.AttributeValue{
-		"State": db.StrToAttr(stateIdle),
+	tripID, err = getNewTripID()
+	if err != nil {
+		return "", err
 	}
 
-	err = db.UpdateExclusive(tableName, keys, updateInfo, cond)
+	currTime := time.Now()
+	held, err := holdCorporateSpend(req, currTime)
 	if err != nil {
-		fmt.Printf("BookCab: db.UpdateExclusive failed. Err: %v\n", err)
-		return nil, err
+		return "", err
 	}
-
-	//Try Udating BookingCount of the City.
-	citykeys := map[string]*dynamodb.AttributeValue{
-		db.HKeyName: db.StrToAttr(hkeyValCities),
-		db.RKeyName: db.StrToAttr(req.From),
+	err = transitionCab(candidate.record, &cabTransition{
+		To:      stateAssigned,
+		History: fmt.Sprintf("State: %v | Trip: %v | Traveling From: %v to %v | BookingTime: %v", stateAssigned, tripID, req.From, req.To, currTime),
+		Updates: map[string]*dynamodb.AttributeValue{
+			"ToCityID":   db.StrToAttr(req.To),
+			"TripID":     db.StrToAttr(tripID),
+			"BookedAt":   db.Num64ToAttr(currTime.Unix()),
+			"TripsDay":   db.StrToAttr(tripsDay(currTime)),
+			"TripsToday": db.Num64ToAttr(candidate.TripsToday + 1),
+		},
+		Writes: tripWrites(tripID, req, candidate, currTime, held),
+	})
+	if err != nil {
+		settleCorporateSpend(req.CorporateID, held, currTime, currTime, 0)
+	}
+	if _, moved := err.(*invalidTransitionError); moved {
+		//The cab left IDLE since it was read.
+		return "", &busyError{kind: "cab", id: candidate.ID, with: "another booking"}
 	}
-	_, err = db.Increment(tableName, citykeys, "Bookings", 1)
 	if err != nil {
-		//Just log the error and move ahead to return the cab.
-		fmt.Printf("BookCab Failed: %v\n", err)
+		return "", err
 	}
+	return tripID, nil
+}
 
-	//Now once the state of the cab is changed to ON_TRIP, it is ensured that
-	//that is booking is successful, returning it.
-	cab = &mycabsapi.Cab{}
-	cab.ID = db.AttrToStr(cabRecords[cabIdx]["Id"])
-	cab.Name = db.AttrToStr(cabRecords[cabIdx]["Name"])
-	cab.Type = db.AttrToStr(cabRecords[cabIdx]["Type"])
+//CabArriving marks an assigned cab as on its way to the pickup.
+func CabArriving(req *mycabsapi.CabArrivingRequest) error {
+	cabRec, err := loadCab(req.CabID)
+	if err != nil {
+		fmt.Printf("CabArriving: loadCab Failed. Err: %v\n", err)
+		return err
+	}
 
-	return cab, nil
+	return transitionCab(cabRec, &cabTransition{
+		To:      stateArriving,
+		History: fmt.Sprintf("State: %v | Time: %v", stateArriving, time.Now()),
+	})
 }
 
-//EndTrip (A force full update of state) ...
-func EndTrip(req *mycabsapi.EndTripRequest) error {
-	keys := map[string]*dynamodb.AttributeValue{
-		db.HKeyName: db.StrToAttr(hkeyValCabs),
-		db.RKeyName: db.StrToAttr(req.CabID),
+//PickupRider starts the trip of an assigned or arriving cab.
+func PickupRider(req *mycabsapi.PickupRequest) error {
+	cabRec, err := loadCab(req.CabID)
+	if err != nil {
+		fmt.Printf("PickupRider: loadCab Failed. Err: %v\n", err)
+		return err
 	}
 
-	cabRec, err := db.Get(tableName, keys)
+	curTime := time.Now()
+	err = transitionCab(cabRec, &cabTransition{
+		To:      stateOnTrip,
+		History: fmt.Sprintf("State: %v | Picked Up In: %v | StartTime: %v", stateOnTrip, db.AttrToStr(cabRec["CityID"]), curTime),
+		Updates: map[string]*dynamodb.AttributeValue{
+			"PickedUpAt": db.Num64ToAttr(curTime.Unix()),
+		},
+	})
 	if err != nil {
-		fmt.Printf("EndTrip: db.Get Failed. Err: %v\n", err)
 		return err
 	}
+	startTrip(cabTripID(cabRec), curTime)
+	return nil
+}
+
+//EndTrip ...
+func EndTrip(req *mycabsapi.EndTripRequest) (*mycabsapi.EndTripResponse, error) {
+	cabRec, err := loadCab(req.CabID)
+	if err != nil {
+		fmt.Printf("EndTrip: loadCab Failed. Err: %v\n", err)
+		return nil, err
+	}
 
 	cityID := req.CityID
 	if cityID == "" {
 		cityID = db.AttrToStr(cabRec["ToCityID"])
+	} else {
+		//The trip may end in a city which was deactivated meanwhile.
+		err = checkCity(cityID, false)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	//Cab History
-	history := db.AttrToStrSet(cabRec["History"])
-	histRec := fmt.Sprintf("%v. State: %v | Trip Ended In: %v | EndTime: %v", len(history), stateIdle, cityID, time.Now())
-	history = append(history, histRec)
+	endTime := time.Now()
+	err = transitionCab(cabRec, &cabTransition{
+		To:      stateIdle,
+		History: fmt.Sprintf("State: %v | Trip Ended In: %v | EndTime: %v", stateIdle, cityID, endTime),
+		Updates: map[string]*dynamodb.AttributeValue{
+			"CityID":   db.StrToAttr(cityID),
+			"ToCityID": db.StrToAttr(""),
+			"TripID":   db.StrToAttr(""),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return completeTrip(cabRec, cityID, req.Distance, endTime), nil
+}
 
-	updateInfo := map[string]*dynamodb.AttributeValue{
-		"State":     db.StrToAttr(stateIdle),
-		"CityID":    db.StrToAttr(cityID),
-		"ToCityID":  db.StrToAttr(""),
-		"IdleSince": db.Num64ToAttr(time.Now().Unix()),
-		"History":   db.StrSetToAttr(history),
+//CancelTrip returns a booked cab, which has not picked up the rider yet, to IDLE
+//in the city it was booked from.
+func CancelTrip(req *mycabsapi.CancelTripRequest) error {
+	cabRec, err := loadCab(req.CabID)
+	if err != nil {
+		fmt.Printf("CancelTrip: loadCab Failed. Err: %v\n", err)
+		return err
 	}
-	cond := map[string]*dynamodb.AttributeValue{
-		"State": db.StrToAttr(stateOnTrip),
+
+	updates := map[string]*dynamodb.AttributeValue{
+		"ToCityID": db.StrToAttr(""),
+		"TripID":   db.StrToAttr(""),
+	}
+	//The cab was waiting for the rider all along, so the time since the booking
+	//is given back to it as idle time.
+	if bookedAt, ok := cabRec["BookedAt"]; ok {
+		updates["IdleSince"] = bookedAt
 	}
 
-	err = db.UpdateExclusive(tableName, keys, updateInfo, cond)
-	return err
+	err = transitionCab(cabRec, &cabTransition{
+		To: stateIdle,
+		History: fmt.Sprintf("State: %v | Trip Cancelled By: %v | Reason: %v | Time: %v",
+			stateIdle, req.Party, req.Reason, time.Now()),
+		Updates: updates,
+	})
+	if err != nil {
+		return err
+	}
+	cancelTrip(cabTripID(cabRec), req.Party, req.Reason)
+	return nil
 }
 
-//DeActivateCab (A force full update of state) ...
+//DeActivateCab ...
 func DeActivateCab(req *mycabsapi.DeActivateCabRequest) error {
-	keys := map[string]*dynamodb.AttributeValue{
-		db.HKeyName: db.StrToAttr(hkeyValCabs),
-		db.RKeyName: db.StrToAttr(req.ID),
-	}
-	cabRec, err := db.Get(tableName, keys)
+	cabRec, err := loadCab(req.ID)
 	if err != nil {
-		fmt.Printf("DeActivateCab: db.Get Failed. Err: %v\n", err)
+		fmt.Printf("DeActivateCab: loadCab Failed. Err: %v\n", err)
 		return err
 	}
 
-	prevIdleWaiting, err := db.AttrToNum64(cabRec["PrevIdleWaiting"])
-	if err != nil {
-		fmt.Printf("DeActivateCab: db.AttrToNum64 of PrevIdleWaiting Failed. Err: %v\n", err)
-	}
+	return transitionCab(cabRec, &cabTransition{
+		To:      stateInActive,
+		History: fmt.Sprintf("State: %v | Time: %v", stateInActive, time.Now()),
+	})
+}
 
-	idleSince, err := db.AttrToNum64(cabRec["IdleSince"])
+//ActivateCab ...
+func ActivateCab(req *mycabsapi.ActivateCabRequest) error {
+	cabRec, err := loadCab(req.ID)
 	if err != nil {
-		fmt.Printf("BookCab: db.AttrToNum64 of IdleSince failed. Err: %v\n", err)
+		fmt.Printf("ActivateCab: loadCab Failed. Err: %v\n", err)
 		return err
 	}
 
-	totalIdleWaiting := prevIdleWaiting + (time.Now().Unix() - idleSince)
-
-	//Cab History
-	history := db.AttrToStrSet(cabRec["History"])
-	histRec := fmt.Sprintf("%v. State: %v | Time: %v", len(history), stateInActive, time.Now())
-	history = append(history, histRec)
+	return transitionCab(cabRec, &cabTransition{
+		To:      stateIdle,
+		History: fmt.Sprintf("State: %v | Time: %v", stateIdle, time.Now()),
+	})
+}
 
-	updateInfo := map[string]*dynamodb.AttributeValue{
-		"State":           db.StrToAttr(stateInActive),
-		"History":         db.StrSetToAttr(history),
-		"PrevIdleWaiting": db.Num64ToAttr(totalIdleWaiting),
-		"IdleSince":       db.Num64ToAttr(0),
+//ChangeCity (Only allowed in InActive State) ...
+func ChangeCity(req *mycabsapi.ChangeCityRequest) error {
+	cabRec, err := loadCab(req.CabID)
+	if err != nil {
+		fmt.Printf("ChangeCity: loadCab Failed. Err: %v\n", err)
+		return err
 	}
-	cond := map[string]*dynamodb.AttributeValue{
-		"State": db.StrToAttr(stateIdle),
+	err = checkCity(req.CityID, true)
+	if err != nil {
+		return err
 	}
+	curCity := db.AttrToStr(cabRec["CityID"])
 
-	err = db.UpdateExclusive(tableName, keys, updateInfo, cond)
-	return err
+	return transitionCab(cabRec, &cabTransition{
+		To:      stateInActive,
+		History: fmt.Sprintf("City Changed From: %v to %v", curCity, req.CityID),
+		Updates: map[string]*dynamodb.AttributeValue{
+			"CityID": db.StrToAttr(req.CityID),
+		},
+	})
 }
 
-//ActivateCab (A force full update of state) ...
-func ActivateCab(req *mycabsapi.ActivateCabRequest) error {
-	keys := map[string]*dynamodb.AttributeValue{
-		db.HKeyName: db.StrToAttr(hkeyValCabs),
-		db.RKeyName: db.StrToAttr(req.ID),
+//SendToMaintenance ...
+func SendToMaintenance(req *mycabsapi.MaintenanceRequest) error {
+	cabRec, err := loadCab(req.CabID)
+	if err != nil {
+		fmt.Printf("SendToMaintenance: loadCab Failed. Err: %v\n", err)
+		return err
 	}
 
-	cabRec, err := db.Get(tableName, keys)
+	return transitionCab(cabRec, &cabTransition{
+		To:      stateMaintenance,
+		History: fmt.Sprintf("State: %v | Reason: %v | Time: %v", stateMaintenance, req.Reason, time.Now()),
+	})
+}
+
+//UpdateCab changes the registration of the cab. The type of a cab can't change
+//while it is on a trip, and a decommissioned cab can't change at all.
+func UpdateCab(req *mycabsapi.UpdateCabRequest) error {
+	cabRec, err := loadCab(req.CabID)
 	if err != nil {
-		fmt.Printf("ActivateCab: db.Get Failed. Err: %v\n", err)
+		fmt.Printf("UpdateCab: loadCab Failed. Err: %v\n", err)
 		return err
 	}
+	state := db.AttrToStr(cabRec["State"])
+	if state == stateRetired {
+		return &rejectedError{reason: fmt.Sprintf("%v is decommissioned", req.CabID)}
+	}
 
-	//Cab History
-	history := db.AttrToStrSet(cabRec["History"])
-	histRec := fmt.Sprintf("%v. State: %v | Time: %v", len(history), stateIdle, time.Now())
-	history = append(history, histRec)
+	updateInfo := map[string]*dynamodb.AttributeValue{}
+	writes := []*db.TxWrite{}
+	changes := []string{}
+	change := func(attr, from, to string, attrVal *dynamodb.AttributeValue) {
+		if from != to {
+			updateInfo[attr] = attrVal
+			changes = append(changes, fmt.Sprintf("%v: %v -> %v", attr, from, to))
+		}
+	}
+	str := func(attr string) string {
+		if attrVal, ok := cabRec[attr]; ok {
+			return db.AttrToStr(attrVal)
+		}
+		return ""
+	}
+	if req.Name != "" {
+		change("Name", str("Name"), req.Name, db.StrToAttr(req.Name))
+	}
+	if req.Plate != "" {
+		change("Plate", str("Plate"), req.Plate, db.StrToAttr(req.Plate))
+	}
+	if req.Seats > 0 {
+		seats := 0
+		if attrVal, ok := cabRec["Seats"]; ok {
+			seats, _ = db.AttrToNum(attrVal)
+		}
+		change("Seats", strconv.Itoa(seats), strconv.Itoa(req.Seats), db.NumToAttr(req.Seats))
+	}
+	if req.Type != "" && req.Type != str("Type") {
+		if state == stateAssigned || state == stateArriving || state == stateOnTrip {
+			return &busyError{kind: "cab", id: req.CabID, with: "a trip in state " + state}
+		}
+		err = checkCabType(req.Type)
+		if err != nil {
+			return err
+		}
+		change("Type", str("Type"), req.Type, db.StrToAttr(req.Type))
+		writes = append(writes, countCabType(req.Type, 1), countCabType(str("Type"), -1))
+	}
+	if len(changes) == 0 {
+		return nil
+	}
 
-	updateInfo := map[string]*dynamodb.AttributeValue{
-		"State":     db.StrToAttr(stateIdle),
-		"IdleSince": db.Num64ToAttr(time.Now().Unix()),
-		"History":   db.StrSetToAttr(history),
+	adds := map[string]*dynamodb.AttributeValue{
+		"History": historyEntry(cabRec, fmt.Sprintf("Updated: %v | Time: %v", strings.Join(changes, ", "), time.Now())),
 	}
+	//The state is the same as read, so the type is not changed under a booking.
 	cond := map[string]*dynamodb.AttributeValue{
-		"State": db.StrToAttr(stateInActive),
+		"State": db.StrToAttr(state),
+	}
+	newRec := make(map[string]*dynamodb.AttributeValue, len(cabRec))
+	for attr, attrVal := range cabRec {
+		newRec[attr] = attrVal
+	}
+	for attr, attrVal := range updateInfo {
+		newRec[attr] = attrVal
+	}
+	writes = append(writes, fleetIndexWrites(cabRec, newRec)...)
+	writes = append([]*db.TxWrite{{Update: cabKeys(req.CabID), Updates: updateInfo, Adds: adds, Cond: cond}}, writes...)
+	err = db.Transact(tableName, writes)
+	if db.IsConditionFailed(err) {
+		if req.Type != "" && req.Type != str("Type") {
+			//The new type may have left the catalog meanwhile.
+			if typeErr := checkCabType(req.Type); typeErr != nil {
+				return typeErr
+			}
+		}
+		return &conflictError{kind: "cab", id: req.CabID}
+	}
+	if err != nil {
+		fmt.Printf("UpdateCab: db.Transact Failed. Err: %v\n", err)
 	}
-
-	err = db.UpdateExclusive(tableName, keys, updateInfo, cond)
 	return err
 }
 
-//ChangeCity (A force full update of City in InActive State) ...
-func ChangeCity(req *mycabsapi.ChangeCityRequest) error {
-	keys := map[string]*dynamodb.AttributeValue{
-		db.HKeyName: db.StrToAttr(hkeyValCabs),
-		db.RKeyName: db.StrToAttr(req.CabID),
-	}
-
-	cabRec, err := db.Get(tableName, keys)
+//DecommissionCab retires the cab for good. It is kept, with its history and
+//trips, but is never booked or activated again.
+func DecommissionCab(req *mycabsapi.DecommissionCabRequest) error {
+	cabRec, err := loadCab(req.CabID)
 	if err != nil {
-		fmt.Printf("ActivateCab: db.Get Failed. Err: %v\n", err)
+		fmt.Printf("DecommissionCab: loadCab Failed. Err: %v\n", err)
 		return err
 	}
-	curCity := db.AttrToStr(cabRec["CityID"])
-	//Cab History
-	history := db.AttrToStrSet(cabRec["History"])
-	histRec := fmt.Sprintf("%v. City Changed From: %v to %v", len(history), curCity, req.CityID)
-	history = append(history, histRec)
-
-	updateInfo := map[string]*dynamodb.AttributeValue{
-		"CityID":  db.StrToAttr(req.CityID),
-		"History": db.StrSetToAttr(history),
-	}
-	cond := map[string]*dynamodb.AttributeValue{
-		"State": db.StrToAttr(stateInActive),
+	err = checkNoDriver(cabRec)
+	if err != nil {
+		return err
 	}
 
-	err = db.UpdateExclusive(tableName, keys, updateInfo, cond)
-	return err
+	//A driver clocking in meanwhile fails the transition.
+	now := time.Now()
+	return transitionCab(cabRec, &cabTransition{
+		To:      stateRetired,
+		History: fmt.Sprintf("State: %v | Decommissioned | Reason: %v | Time: %v", stateRetired, req.Reason, now),
+		Updates: map[string]*dynamodb.AttributeValue{
+			"DecommissionedAt": db.Num64ToAttr(now.Unix()),
+		},
+		Free: []string{"DriverID"},
+	})
 }
 
-//DemandedCity ...
-func DemandedCity() (*mycabsapi.DemandCityResonse, error) {
-
-	cityRecords, err := db.Query(tableName, hkeyValCities, nil)
+//checkNoDriver makes sure no driver is on shift in a cab being retired.
+func checkNoDriver(cabRec map[string]*dynamodb.AttributeValue) error {
+	if driverID := cabDriverID(cabRec); driverID != "" {
+		return &busyError{kind: "cab", id: db.AttrToStr(cabRec["Id"]), with: "driver " + driverID + " on shift"}
+	}
+	return nil
+}
 
+//ReturnFromMaintenance brings a cab back to service (IDLE), parks it (IN_ACTIVE)
+//or writes it off (RETIRED).
+func ReturnFromMaintenance(req *mycabsapi.ReturnFromMaintenanceRequest) error {
+	cabRec, err := loadCab(req.CabID)
 	if err != nil {
-		fmt.Printf("DemandedCity: db.Query failed. Err: %v\n", err)
-		return nil, err
+		fmt.Printf("ReturnFromMaintenance: loadCab Failed. Err: %v\n", err)
+		return err
 	}
-	if len(cityRecords) == 0 {
-		fmt.Printf("DemandedCity: No cities found\n")
-		return nil, nil
+
+	if from := db.AttrToStr(cabRec["State"]); from != stateMaintenance {
+		return &invalidTransitionError{from: from, to: req.State}
 	}
 
-	maxBookings := int64(0)
-	cityIdx := 0
-	//Flaw - It returns only one in case of clash
-	for idx, cityRec := range cityRecords {
-		booking, err := db.AttrToNum64(cityRec["Bookings"])
+	now := time.Now()
+	tr := &cabTransition{
+		To:      req.State,
+		History: fmt.Sprintf("State: %v | Back From Maintenance | Time: %v", req.State, now),
+	}
+	if req.State == stateRetired {
+		//Written off like a decommissioned cab, with no driver on shift.
+		err = checkNoDriver(cabRec)
 		if err != nil {
-			fmt.Printf("DemandedCity: db.AttrToNum64 Failed. Err: %v\n", err)
-			return nil, err
+			return err
 		}
-		if booking > maxBookings {
-			maxBookings = booking
-			cityIdx = idx
+		tr.Updates = map[string]*dynamodb.AttributeValue{
+			"DecommissionedAt": db.Num64ToAttr(now.Unix()),
 		}
+		tr.Free = []string{"DriverID"}
+	}
+	return transitionCab(cabRec, tr)
+}
+
+//DemandedCity returns the city with the most bookings in the window up to now,
+//nil when none was booked. Cities with the same bookings go by ID.
+func DemandedCity(window string) (*mycabsapi.DemandCityResonse, error) {
+	cityNames, err := analyticsCities("")
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	counts, err := windowBookings(cityNames, "", now.Add(-demandWindows[window]), now)
+	if err != nil {
+		return nil, err
+	}
+
+	top := analytics.Top(counts, 1)
+	if len(top) == 0 {
+		fmt.Printf("DemandedCity: No bookings in the %v\n", window)
+		return nil, nil
 	}
 	city := &mycabsapi.DemandCityResonse{
-		CityID:   db.AttrToStr(cityRecords[cityIdx]["Id"]),
-		CityName: db.AttrToStr(cityRecords[cityIdx]["Name"]),
+		CityID:   top[0].CityID,
+		CityName: cityNames[top[0].CityID],
+		Window:   window,
+		Bookings: top[0].Bookings,
 	}
 	return city, nil
 }
 
-//CabHistory (A force full update of state) ...
+//CabHistory ...
 func CabHistory(req *mycabsapi.CabHistoryRequest) (*mycabsapi.CabHistoryResonse, error) {
-	keys := map[string]*dynamodb.AttributeValue{
-		db.HKeyName: db.StrToAttr(hkeyValCabs),
-		db.RKeyName: db.StrToAttr(req.CabID),
-	}
-
-	cabRec, err := db.Get(tableName, keys)
+	cabRec, err := loadCab(req.CabID)
 	if err != nil {
-		fmt.Printf("CabHistory: db.Get Failed. Err: %v\n", err)
+		fmt.Printf("CabHistory: loadCab Failed. Err: %v\n", err)
 		return nil, err
 	}
 
@@ -480,6 +750,24 @@ func getNewCabID() (string, error) {
 	return cabID, nil
 }
 
+//getNewTripID : Creates a unique id using Storage Counter and returns
+func getNewTripID() (string, error) {
+	newCount, err := db.Increment(tableName, counterKeys("trip"), "Counter", 1)
+	if err != nil {
+		fmt.Printf("getNewTripID Failed: %v\n", err)
+		return "", err
+	}
+	return "trip_" + strconv.FormatInt(int64(newCount), 10), nil
+}
+
+//counterKeys is the key of the named id counter.
+func counterKeys(counter string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(hkeyValCounter),
+		db.RKeyName: db.StrToAttr(counter),
+	}
+}
+
 func initCityCounter() error {
 	counterRecord := map[string]*dynamodb.AttributeValue{
 		db.HKeyName: db.StrToAttr(hkeyValCounter),
@@ -518,13 +806,15 @@ func dbEndpoint() string {
 	return ep
 }
 
+//init sets up the db session. It logs to stderr, as it runs before any command
+//can keep stdout for its own output.
 func init() {
 	dbEndpoint := dbEndpoint()
 	db.InitDBAPI(region, dbEndpoint, accessKey, secretKey)
-	fmt.Println("Initialized DB Session ...")
+	fmt.Fprintln(os.Stderr, "Initialized DB Session ...")
 	exist, err := db.DoesTableExit(tableName)
 	if err != nil {
-		fmt.Printf("db.DoesTableExit Failed %v\n. Exitting....", err)
+		fmt.Fprintf(os.Stderr, "db.DoesTableExit Failed %v\n. Exitting....", err)
 		os.Exit(1)
 	}
 	if exist {
@@ -532,17 +822,17 @@ func init() {
 	}
 	err = db.CreateTable(tableName, readCapacityUnits, writeCapacityUnits)
 	if err != nil {
-		fmt.Printf("db.CreateTable Failed %v\n. Exitting....", err)
+		fmt.Fprintf(os.Stderr, "db.CreateTable Failed %v\n. Exitting....", err)
 		os.Exit(1)
 	}
 	err = initCityCounter()
 	if err != nil {
-		fmt.Printf("initCityCounter Failed %v\n. Exitting....", err)
+		fmt.Fprintf(os.Stderr, "initCityCounter Failed %v\n. Exitting....", err)
 		os.Exit(1)
 	}
 	err = initCabCounter()
 	if err != nil {
-		fmt.Printf("initCabCounter Failed %v\n. Exitting....", err)
+		fmt.Fprintf(os.Stderr, "initCabCounter Failed %v\n. Exitting....", err)
 		os.Exit(1)
 	}
 }
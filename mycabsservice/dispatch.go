@@ -0,0 +1,223 @@
+package mycabsservice
+
+import (
+	"fmt"
+	"math/rand"
+	"mycabs/db"
+	"mycabs/geo"
+	"mycabs/mycabsapi"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+const defaultDispatchStrategy = "idle"
+
+//CandidateCab is an idle cab which can serve a booking.
+type CandidateCab struct {
+	ID          string
+	Name        string
+	Type        string
+	DriverID    string
+	IdleWaiting int64   //Total idle time in seconds, including the banked idle time.
+	LastBooked  int64   //Unix time of the last booking, 0 if never booked.
+	TripsToday  int64   //Bookings taken today.
+	Rating      float64 //Average rating, 0 till the cab is rated.
+	RatingCount int64   //Ratings received.
+	Location    *mycabsapi.Location
+	Distance    float64 //Meters to the pickup, -1 if the cab or the pickup has no location.
+
+	record map[string]*dynamodb.AttributeValue
+}
+
+//DispatchStrategy orders the candidate cabs for a booking, best first. BookCab
+//offers the booking to the cabs in that order till one of them takes it.
+type DispatchStrategy interface {
+	Rank(req *mycabsapi.BookingRequest, cabs []*CandidateCab) []*CandidateCab
+}
+
+var dispatchStrategies = map[string]DispatchStrategy{
+	"idle":       idleTimeStrategy{},
+	"roundrobin": roundRobinStrategy{},
+	"leasttrips": leastTripsStrategy{},
+	"rating":     ratingStrategy{},
+	"lottery":    lotteryStrategy{},
+	"nearest":    nearestStrategy{},
+}
+
+//RegisterDispatchStrategy makes a strategy available to be configured on cities
+//under the given name. It is meant to be called at init time.
+func RegisterDispatchStrategy(name string, strategy DispatchStrategy) {
+	dispatchStrategies[name] = strategy
+}
+
+//idleTimeStrategy prefers the cab which waited the longest, ties are broken randomly.
+type idleTimeStrategy struct{}
+
+func (idleTimeStrategy) Rank(req *mycabsapi.BookingRequest, cabs []*CandidateCab) []*CandidateCab {
+	return rankCabs(cabs, func(a, b *CandidateCab) bool {
+		return a.IdleWaiting > b.IdleWaiting
+	})
+}
+
+//roundRobinStrategy prefers the cab which was booked the longest time ago.
+type roundRobinStrategy struct{}
+
+func (roundRobinStrategy) Rank(req *mycabsapi.BookingRequest, cabs []*CandidateCab) []*CandidateCab {
+	return rankCabs(cabs, func(a, b *CandidateCab) bool {
+		return a.LastBooked < b.LastBooked
+	})
+}
+
+//leastTripsStrategy prefers the cab with the fewest bookings today.
+type leastTripsStrategy struct{}
+
+func (leastTripsStrategy) Rank(req *mycabsapi.BookingRequest, cabs []*CandidateCab) []*CandidateCab {
+	return rankCabs(cabs, func(a, b *CandidateCab) bool {
+		if a.TripsToday != b.TripsToday {
+			return a.TripsToday < b.TripsToday
+		}
+		return a.IdleWaiting > b.IdleWaiting
+	})
+}
+
+//ratingStrategy prefers the best rated cab.
+type ratingStrategy struct{}
+
+func (ratingStrategy) Rank(req *mycabsapi.BookingRequest, cabs []*CandidateCab) []*CandidateCab {
+	return rankCabs(cabs, func(a, b *CandidateCab) bool {
+		if a.Rating != b.Rating {
+			return a.Rating > b.Rating
+		}
+		return a.IdleWaiting > b.IdleWaiting
+	})
+}
+
+//lotteryStrategy draws the cabs at random, with the chance of a cab weighted
+//by its idle time. Every cab keeps a small chance even with no idle time.
+type lotteryStrategy struct{}
+
+func (lotteryStrategy) Rank(req *mycabsapi.BookingRequest, cabs []*CandidateCab) []*CandidateCab {
+	pool := append([]*CandidateCab{}, cabs...)
+	ranked := make([]*CandidateCab, 0, len(cabs))
+	for len(pool) > 0 {
+		totalWeight := int64(0)
+		for _, cab := range pool {
+			totalWeight += cab.IdleWaiting + 1
+		}
+		draw := rand.Int63n(totalWeight)
+		idx := 0
+		for ; idx < len(pool)-1; idx++ {
+			draw -= pool[idx].IdleWaiting + 1
+			if draw < 0 {
+				break
+			}
+		}
+		ranked = append(ranked, pool[idx])
+		pool = append(pool[:idx], pool[idx+1:]...)
+	}
+	return ranked
+}
+
+//nearestStrategy prefers the cab closest to the pickup. Cabs with no known
+//location come last. Without a pickup location it falls back to idle time.
+type nearestStrategy struct{}
+
+func (nearestStrategy) Rank(req *mycabsapi.BookingRequest, cabs []*CandidateCab) []*CandidateCab {
+	return rankCabs(cabs, func(a, b *CandidateCab) bool {
+		if (a.Distance < 0) != (b.Distance < 0) {
+			return a.Distance >= 0
+		}
+		if a.Distance != b.Distance {
+			return a.Distance < b.Distance
+		}
+		return a.IdleWaiting > b.IdleWaiting
+	})
+}
+
+//rankCabs sorts a copy of cabs with less, cabs which compare equal end up in random order.
+func rankCabs(cabs []*CandidateCab, less func(a, b *CandidateCab) bool) []*CandidateCab {
+	ranked := append([]*CandidateCab{}, cabs...)
+	rand.Shuffle(len(ranked), func(i, j int) {
+		ranked[i], ranked[j] = ranked[j], ranked[i]
+	})
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return less(ranked[i], ranked[j])
+	})
+	return ranked
+}
+
+//newCandidateCab ...
+func newCandidateCab(cabRec map[string]*dynamodb.AttributeValue, curTime time.Time) *CandidateCab {
+	cab := &CandidateCab{
+		ID:          db.AttrToStr(cabRec["Id"]),
+		Name:        db.AttrToStr(cabRec["Name"]),
+		Type:        db.AttrToStr(cabRec["Type"]),
+		DriverID:    cabDriverID(cabRec),
+		IdleWaiting: totalIdleWaiting(cabRec, curTime.Unix()),
+		record:      cabRec,
+	}
+	if attrVal, ok := cabRec["BookedAt"]; ok {
+		cab.LastBooked, _ = db.AttrToNum64(attrVal)
+	}
+	if attrVal, ok := cabRec["TripsDay"]; ok && db.AttrToStr(attrVal) == tripsDay(curTime) {
+		cab.TripsToday, _ = db.AttrToNum64(cabRec["TripsToday"])
+	}
+	cab.Rating, cab.RatingCount = attrsToRating(cabRec)
+	cab.Location = attrsToLocation(cabRec)
+	cab.Distance = -1
+	return cab
+}
+
+//setDistance ...
+func (cab *CandidateCab) setDistance(pickup *mycabsapi.Location) {
+	if pickup == nil || cab.Location == nil {
+		return
+	}
+	cab.Distance = geo.Distance(pickup.Lat, pickup.Lon, cab.Location.Lat, cab.Location.Lon)
+}
+
+//tripsDay is the day the TripsToday counter of a cab belongs to.
+func tripsDay(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+//cityDispatchStrategy returns the strategy configured for the city, or the
+//default one when the city has none.
+func cityDispatchStrategy(cityID string) DispatchStrategy {
+	cityRec, err := db.Get(tableName, cityKeys(cityID))
+	if err != nil {
+		fmt.Printf("cityDispatchStrategy: db.Get Failed. Err: %v\n", err)
+	}
+	if attrVal, ok := cityRec["Dispatch"]; ok {
+		if strategy, ok := dispatchStrategies[db.AttrToStr(attrVal)]; ok {
+			return strategy
+		}
+		fmt.Printf("cityDispatchStrategy: Unknown strategy %v for %v\n", db.AttrToStr(attrVal), cityID)
+	}
+	return dispatchStrategies[defaultDispatchStrategy]
+}
+
+//SetCityDispatch ...
+func SetCityDispatch(req *mycabsapi.CityDispatchRequest) error {
+	updateInfo := map[string]*dynamodb.AttributeValue{
+		"Dispatch": db.StrToAttr(req.Strategy),
+	}
+	cond := map[string]*dynamodb.AttributeValue{
+		"Id": db.StrToAttr(req.CityID),
+	}
+	err := db.UpdateExclusive(tableName, cityKeys(req.CityID), updateInfo, cond)
+	if db.IsConditionFailed(err) {
+		return &notFoundError{kind: "city", id: req.CityID}
+	}
+	return err
+}
+
+//cityKeys ...
+func cityKeys(cityID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(hkeyValCities),
+		db.RKeyName: db.StrToAttr(cityID),
+	}
+}
@@ -0,0 +1,311 @@
+package mycabsservice
+
+import (
+	"fmt"
+	"mycabs/db"
+	"mycabs/mycabsapi"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+//Drivers are kept under hkeyValDrivers. A shift binds a driver to a cab: the
+//driver gets the CabID and the cab the DriverID, each only taken when empty.
+//Only cabs with a driver on shift are dispatched. Ended shifts are kept in the
+//shifts partition of the driver for the reports.
+
+const (
+	hkeyValDrivers = "drivers/"
+	hkeyValShifts  = "shifts/"
+)
+
+const licenseDateLayout = "2006-01-02"
+
+//RegisterDriver ...
+func RegisterDriver(req *mycabsapi.RegisterDriverRequest) (driverID string, err error) {
+	seq, err := db.Increment(tableName, counterKeys("driver"), "Counter", 1)
+	if err != nil {
+		fmt.Printf("RegisterDriver: db.Increment Failed. Err: %v\n", err)
+		return "", err
+	}
+	driverID = "driver_" + strconv.Itoa(seq)
+
+	driverRecord := driverKeys(driverID)
+	driverRecord["Id"] = db.StrToAttr(driverID)
+	driverRecord["Name"] = db.StrToAttr(req.Name)
+	driverRecord["Phone"] = db.StrToAttr(req.Phone)
+	driverRecord["CityID"] = db.StrToAttr(req.CityID)
+	driverRecord["LicenseNo"] = db.StrToAttr(req.LicenseNo)
+	driverRecord["LicenseExpiry"] = db.StrToAttr(req.LicenseExpiry)
+	driverRecord["CabID"] = db.StrToAttr("")
+	driverRecord["CreatedAt"] = db.Num64ToAttr(time.Now().Unix())
+
+	err = db.Put(tableName, driverRecord)
+	if err != nil {
+		fmt.Printf("RegisterDriver: db.Put Failed. Err: %v\n", err)
+		return "", err
+	}
+	return driverID, nil
+}
+
+//Driver ...
+func Driver(req *mycabsapi.DriverRequest) (*mycabsapi.Driver, error) {
+	driverRec, err := loadDriver(req.DriverID)
+	if err != nil {
+		return nil, err
+	}
+	driver := &mycabsapi.Driver{
+		ID:            db.AttrToStr(driverRec["Id"]),
+		Name:          db.AttrToStr(driverRec["Name"]),
+		Phone:         db.AttrToStr(driverRec["Phone"]),
+		CityID:        db.AttrToStr(driverRec["CityID"]),
+		LicenseNo:     db.AttrToStr(driverRec["LicenseNo"]),
+		LicenseExpiry: db.AttrToStr(driverRec["LicenseExpiry"]),
+		CabID:         db.AttrToStr(driverRec["CabID"]),
+	}
+	driver.Rating, driver.Ratings = attrsToRating(driverRec)
+	if driver.CabID != "" {
+		driver.ShiftStart = attrToTime(driverRec["ShiftStart"])
+	}
+	return driver, nil
+}
+
+//ClockIn starts the shift of the driver on the cab.
+func ClockIn(req *mycabsapi.ClockInRequest) error {
+	driverRec, err := loadDriver(req.DriverID)
+	if err != nil {
+		return err
+	}
+	expiry, _ := time.Parse(licenseDateLayout, db.AttrToStr(driverRec["LicenseExpiry"]))
+	if time.Now().After(expiry.AddDate(0, 0, 1)) {
+		return &rejectedError{reason: fmt.Sprintf("license of %v expired on %v", req.DriverID, db.AttrToStr(driverRec["LicenseExpiry"]))}
+	}
+	cabRec, err := loadCab(req.CabID)
+	if err != nil {
+		return err
+	}
+	if db.AttrToStr(cabRec["State"]) == stateRetired {
+		return &rejectedError{reason: fmt.Sprintf("%v is decommissioned", req.CabID)}
+	}
+	if db.AttrToStr(cabRec["CityID"]) != db.AttrToStr(driverRec["CityID"]) {
+		return &rejectedError{reason: fmt.Sprintf("%v is not in the city of %v", req.CabID, req.DriverID)}
+	}
+
+	updateInfo := map[string]*dynamodb.AttributeValue{
+		"CabID":      db.StrToAttr(req.CabID),
+		"ShiftStart": db.Num64ToAttr(time.Now().Unix()),
+	}
+	err = db.UpdateIfEmpty(tableName, driverKeys(req.DriverID), updateInfo, "CabID")
+	if db.IsConditionFailed(err) {
+		return &busyError{kind: "driver", id: req.DriverID, with: "another shift"}
+	}
+	if err != nil {
+		fmt.Printf("ClockIn: db.UpdateIfEmpty of %v Failed. Err: %v\n", req.DriverID, err)
+		return err
+	}
+
+	updateInfo = map[string]*dynamodb.AttributeValue{
+		"DriverID": db.StrToAttr(req.DriverID),
+	}
+	err = db.UpdateIfEmpty(tableName, cabKeys(req.CabID), updateInfo, "DriverID")
+	if err != nil {
+		//Another driver has the cab, the driver stays off shift.
+		unbindDriver(req.DriverID, req.CabID)
+		if db.IsConditionFailed(err) {
+			return &busyError{kind: "cab", id: req.CabID, with: "another driver"}
+		}
+		fmt.Printf("ClockIn: db.UpdateIfEmpty of %v Failed. Err: %v\n", req.CabID, err)
+		return err
+	}
+
+	//The cab can take bookings from now, it may have tickets waiting for it.
+	cabRec, err = loadCab(req.CabID)
+	if err != nil {
+		return nil
+	}
+	switch db.AttrToStr(cabRec["State"]) {
+	case stateIdle:
+		serveWaitlist(cabRec)
+	case stateRetired:
+		//Decommissioned since it was read, the driver leaves it.
+		cond := map[string]*dynamodb.AttributeValue{
+			"DriverID": db.StrToAttr(req.DriverID),
+		}
+		err = db.UpdateExclusive(tableName, cabKeys(req.CabID), map[string]*dynamodb.AttributeValue{"DriverID": db.StrToAttr("")}, cond)
+		if err != nil {
+			fmt.Printf("ClockIn: db.UpdateExclusive of %v Failed. Err: %v\n", req.CabID, err)
+		}
+		unbindDriver(req.DriverID, req.CabID)
+		return &rejectedError{reason: fmt.Sprintf("%v is decommissioned", req.CabID)}
+	}
+	return nil
+}
+
+//ClockOut ends the shift of the driver. A driver can't leave a cab on a trip.
+func ClockOut(req *mycabsapi.ClockOutRequest) error {
+	driverRec, err := loadDriver(req.DriverID)
+	if err != nil {
+		return err
+	}
+	cabID := db.AttrToStr(driverRec["CabID"])
+	if cabID == "" {
+		return &rejectedError{reason: fmt.Sprintf("%v is not on shift", req.DriverID)}
+	}
+
+	updateInfo := map[string]*dynamodb.AttributeValue{
+		"DriverID": db.StrToAttr(""),
+	}
+	cond := map[string]*dynamodb.AttributeValue{
+		"DriverID": db.StrToAttr(req.DriverID),
+	}
+	cabRec, err := loadCab(cabID)
+	if err != nil {
+		return err
+	}
+	switch state := db.AttrToStr(cabRec["State"]); state {
+	case stateAssigned, stateArriving, stateOnTrip:
+		return &busyError{kind: "cab", id: cabID, with: "a trip in state " + state}
+	default:
+		//Only an IDLE cab can be booked meanwhile, it must still be IDLE when
+		//the driver leaves.
+		cond["State"] = cabRec["State"]
+	}
+	err = db.UpdateExclusive(tableName, cabKeys(cabID), updateInfo, cond)
+	if db.IsConditionFailed(err) {
+		return &conflictError{kind: "cab", id: cabID}
+	}
+	if err != nil {
+		fmt.Printf("ClockOut: db.UpdateExclusive of %v Failed. Err: %v\n", cabID, err)
+		return err
+	}
+	unbindDriver(req.DriverID, cabID)
+
+	start, _ := db.AttrToNum64(driverRec["ShiftStart"])
+	shiftRecord := map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(shiftsHKey(req.DriverID)),
+		db.RKeyName: db.StrToAttr(fmt.Sprintf("%012d", start)),
+		"CabID":     db.StrToAttr(cabID),
+		"Start":     db.Num64ToAttr(start),
+		"End":       db.Num64ToAttr(time.Now().Unix()),
+	}
+	err = db.Put(tableName, shiftRecord)
+	if err != nil {
+		fmt.Printf("ClockOut: db.Put of shift Failed. Err: %v\n", err)
+	}
+	return nil
+}
+
+//DriverReport sums up the trips, earnings and shifts of the driver per day,
+//from and to included. Days are in UTC.
+func DriverReport(req *mycabsapi.DriverReportRequest) (*mycabsapi.DriverReportResponse, error) {
+	_, err := loadDriver(req.DriverID)
+	if err != nil {
+		return nil, err
+	}
+	from, _ := time.Parse(licenseDateLayout, req.From)
+	to, _ := time.Parse(licenseDateLayout, req.To)
+	to = to.AddDate(0, 0, 1)
+
+	days := map[string]*mycabsapi.DriverDay{}
+	dayOf := func(unixTime int64) *mycabsapi.DriverDay {
+		day := time.Unix(unixTime, 0).UTC().Format(licenseDateLayout)
+		if _, ok := days[day]; !ok {
+			days[day] = &mycabsapi.DriverDay{Day: day, Earnings: map[string]int64{}}
+		}
+		return days[day]
+	}
+
+	tripRecords, err := indexedTrips(hkeyValDriverTrips+req.DriverID+"/", from.Unix(), to.Unix()-1)
+	if err != nil {
+		return nil, err
+	}
+	for _, tripRec := range tripRecords {
+		bookedAt, _ := db.AttrToNum64(tripRec["BookedAt"])
+		day := dayOf(bookedAt)
+		switch db.AttrToStr(tripRec["State"]) {
+		case tripCompleted:
+			day.Trips++
+			trip := toTrip(tripRec)
+			day.Distance += trip.Distance
+			if trip.Fare != nil {
+				day.Earnings[trip.Fare.Currency] += trip.Fare.Total
+			}
+		case tripCancelled:
+			day.Cancelled++
+		}
+	}
+
+	shiftRecords, err := db.QueryBetween(tableName, shiftsHKey(req.DriverID),
+		fmt.Sprintf("%012d", from.Unix()), fmt.Sprintf("%012d", to.Unix()-1))
+	if err != nil {
+		fmt.Printf("DriverReport: db.QueryBetween of shifts Failed. Err: %v\n", err)
+		return nil, err
+	}
+	for _, shiftRec := range shiftRecords {
+		start, _ := db.AttrToNum64(shiftRec["Start"])
+		end, _ := db.AttrToNum64(shiftRec["End"])
+		dayOf(start).OnShift += end - start
+	}
+
+	resp := &mycabsapi.DriverReportResponse{
+		DriverID: req.DriverID,
+		Days:     []*mycabsapi.DriverDay{},
+	}
+	for _, day := range days {
+		resp.Days = append(resp.Days, day)
+	}
+	sort.Slice(resp.Days, func(i, j int) bool {
+		return resp.Days[i].Day < resp.Days[j].Day
+	})
+	return resp, nil
+}
+
+//unbindDriver takes the driver off the cab.
+func unbindDriver(driverID, cabID string) {
+	updateInfo := map[string]*dynamodb.AttributeValue{
+		"CabID": db.StrToAttr(""),
+	}
+	cond := map[string]*dynamodb.AttributeValue{
+		"CabID": db.StrToAttr(cabID),
+	}
+	err := db.UpdateExclusive(tableName, driverKeys(driverID), updateInfo, cond)
+	if err != nil {
+		fmt.Printf("unbindDriver: db.UpdateExclusive of %v Failed. Err: %v\n", driverID, err)
+	}
+}
+
+//cabDriverID is the driver on shift in the cab, empty if there is none.
+func cabDriverID(cabRec map[string]*dynamodb.AttributeValue) string {
+	if attrVal, ok := cabRec["DriverID"]; ok {
+		return db.AttrToStr(attrVal)
+	}
+	return ""
+}
+
+//loadDriver ...
+func loadDriver(driverID string) (map[string]*dynamodb.AttributeValue, error) {
+	driverRec, err := db.Get(tableName, driverKeys(driverID))
+	if err != nil {
+		fmt.Printf("loadDriver: db.Get Failed. Err: %v\n", err)
+		return nil, err
+	}
+	if len(driverRec) == 0 {
+		return nil, &notFoundError{kind: "driver", id: driverID}
+	}
+	return driverRec, nil
+}
+
+//driverKeys ...
+func driverKeys(driverID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(hkeyValDrivers),
+		db.RKeyName: db.StrToAttr(driverID),
+	}
+}
+
+//shiftsHKey is the partition holding the ended shifts of a driver.
+func shiftsHKey(driverID string) string {
+	return hkeyValShifts + driverID + "/"
+}
@@ -0,0 +1,73 @@
+package mycabsservice
+
+import (
+	"fmt"
+	"net/http"
+)
+
+//invalidTransitionError is returned when a cab is asked to move to a state
+//which is not allowed from its current state.
+type invalidTransitionError struct {
+	from string
+	to   string
+}
+
+func (e *invalidTransitionError) Error() string {
+	return fmt.Sprintf("invalid transition from %v to %v", e.from, e.to)
+}
+
+//notFoundError is returned when the record a request refers to does not exist.
+type notFoundError struct {
+	kind string
+	id   string
+}
+
+func (e *notFoundError) Error() string {
+	return fmt.Sprintf("%v %v not found", e.kind, e.id)
+}
+
+//conflictError is returned when a record was changed by someone else between
+//reading and updating it. The request can be retried.
+type conflictError struct {
+	kind string
+	id   string
+}
+
+func (e *conflictError) Error() string {
+	return fmt.Sprintf("%v %v was changed meanwhile, retry", e.kind, e.id)
+}
+
+//busyError is returned when a rider, driver or cab is already taken by
+//something else.
+type busyError struct {
+	kind string
+	id   string
+	with string
+}
+
+func (e *busyError) Error() string {
+	return fmt.Sprintf("%v %v is busy with %v", e.kind, e.id, e.with)
+}
+
+//rejectedError is returned when a well formed request breaks a business rule.
+type rejectedError struct {
+	reason string
+}
+
+func (e *rejectedError) Error() string {
+	return e.reason
+}
+
+//errorStatus maps an error returned by the service functions to the http
+//status reported to the client.
+func errorStatus(err error) int {
+	switch err.(type) {
+	case *invalidTransitionError, *conflictError, *busyError:
+		return http.StatusConflict
+	case *notFoundError:
+		return http.StatusNotFound
+	case *rejectedError:
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
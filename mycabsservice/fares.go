@@ -0,0 +1,231 @@
+package mycabsservice
+
+import (
+	"fmt"
+	"mycabs/db"
+	"mycabs/fare"
+	"mycabs/geo"
+	"mycabs/mycabsapi"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+//Every city has a tariff per cab type under hkeyValTariffs, keyed by
+//"<city>/<cab type>". The fare of a trip is charged on the tariff of the city
+//it starts in.
+
+const (
+	hkeyValTariffs = "tariffs/"
+)
+
+const (
+	roadFactor     = 1.3  //Roads are longer than the straight line.
+	estimatedSpeed = 25.0 //Km/h, average city speed for estimates.
+)
+
+//SetTariff creates or replaces the tariff of the city and cab type.
+func SetTariff(req *mycabsapi.Tariff) error {
+	err := checkCabType(req.CabType)
+	if err != nil {
+		return err
+	}
+	cityRec, err := db.Get(tableName, cityKeys(req.CityID))
+	if err != nil {
+		fmt.Printf("SetTariff: db.Get Failed. Err: %v\n", err)
+		return err
+	}
+	if len(cityRec) == 0 {
+		return &notFoundError{kind: "city", id: req.CityID}
+	}
+
+	tariffRecord := tariffKeys(req.CityID, req.CabType)
+	tariffRecord["CityID"] = db.StrToAttr(req.CityID)
+	tariffRecord["CabType"] = db.StrToAttr(req.CabType)
+	tariffRecord["Currency"] = db.StrToAttr(req.Currency)
+	tariffRecord["BaseFare"] = db.Num64ToAttr(req.BaseFare)
+	tariffRecord["PerKm"] = db.Num64ToAttr(req.PerKm)
+	tariffRecord["PerMinute"] = db.Num64ToAttr(req.PerMinute)
+	tariffRecord["MinimumFare"] = db.Num64ToAttr(req.MinimumFare)
+	tariffRecord["InterCity"] = db.Num64ToAttr(req.InterCity)
+	if len(req.InterCityTo) > 0 {
+		interCityTo := make(map[string]*dynamodb.AttributeValue, len(req.InterCityTo))
+		for cityID, surcharge := range req.InterCityTo {
+			interCityTo[cityID] = db.Num64ToAttr(surcharge)
+		}
+		tariffRecord["InterCityTo"] = &dynamodb.AttributeValue{M: interCityTo}
+	}
+	if req.TaxRate > 0 {
+		tariffRecord["TaxName"] = db.StrToAttr(req.TaxName)
+		tariffRecord["TaxRate"] = db.FloatToAttr(req.TaxRate)
+	}
+	if req.CancelFee > 0 {
+		tariffRecord["CancelFee"] = db.Num64ToAttr(req.CancelFee)
+	}
+
+	err = db.Put(tableName, tariffRecord)
+	if err != nil {
+		fmt.Printf("SetTariff: db.Put Failed. Err: %v\n", err)
+	}
+	return err
+}
+
+//Tariff ...
+func Tariff(req *mycabsapi.TariffRequest) (*mycabsapi.Tariff, error) {
+	tariff, err := loadTariff(req.CityID, req.CabType)
+	if err != nil {
+		return nil, err
+	}
+	return &mycabsapi.Tariff{
+		CityID:      req.CityID,
+		CabType:     req.CabType,
+		Currency:    tariff.Currency,
+		BaseFare:    tariff.BaseFare,
+		PerKm:       tariff.PerKm,
+		PerMinute:   tariff.PerMinute,
+		MinimumFare: tariff.MinimumFare,
+		InterCity:   tariff.InterCity,
+		InterCityTo: tariff.InterCityTo,
+		TaxName:     tariff.TaxName,
+		TaxRate:     tariff.TaxRate,
+		CancelFee:   tariff.CancelFee,
+	}, nil
+}
+
+//FareEstimate returns what the trip would cost if booked now.
+func FareEstimate(req *mycabsapi.FareEstimateRequest) (*mycabsapi.FareEstimateResponse, error) {
+	tariff, err := loadTariff(req.From, req.CabType)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &mycabsapi.FareEstimateResponse{
+		Distance: req.Distance,
+		Duration: req.Duration,
+	}
+	if resp.Distance == 0 && req.Pickup != nil && req.Drop != nil {
+		resp.Distance = roadFactor * geo.Distance(req.Pickup.Lat, req.Pickup.Lon, req.Drop.Lat, req.Drop.Lon)
+	}
+	if resp.Duration == 0 {
+		resp.Duration = int64(resp.Distance / (estimatedSpeed * 1000 / 3600))
+	}
+
+	discount, err := checkPromo(req.PromoCode, req.RiderID, req.From, req.CabType, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	resp.Fare = toFare(tariff.Compute(&fare.Trip{
+		From:     req.From,
+		To:       req.To,
+		Meters:   resp.Distance,
+		Duration: time.Duration(resp.Duration) * time.Second,
+		Surge:    surgeMultiplier(req.From, req.CabType),
+		Discount: discount,
+	}))
+	return resp, nil
+}
+
+//loadTariff ...
+func loadTariff(cityID, cabType string) (*fare.Tariff, error) {
+	tariffRec, err := db.Get(tableName, tariffKeys(cityID, cabType))
+	if err != nil {
+		fmt.Printf("loadTariff: db.Get Failed. Err: %v\n", err)
+		return nil, err
+	}
+	if len(tariffRec) == 0 {
+		return nil, &notFoundError{kind: "tariff", id: cityID + "/" + cabType}
+	}
+
+	tariff := &fare.Tariff{
+		Currency: db.AttrToStr(tariffRec["Currency"]),
+	}
+	tariff.BaseFare, _ = db.AttrToNum64(tariffRec["BaseFare"])
+	tariff.PerKm, _ = db.AttrToNum64(tariffRec["PerKm"])
+	tariff.PerMinute, _ = db.AttrToNum64(tariffRec["PerMinute"])
+	tariff.MinimumFare, _ = db.AttrToNum64(tariffRec["MinimumFare"])
+	tariff.InterCity, _ = db.AttrToNum64(tariffRec["InterCity"])
+	if attrVal, ok := tariffRec["InterCityTo"]; ok {
+		tariff.InterCityTo = make(map[string]int64, len(attrVal.M))
+		for cityID, surcharge := range attrVal.M {
+			tariff.InterCityTo[cityID], _ = db.AttrToNum64(surcharge)
+		}
+	}
+	if attrVal, ok := tariffRec["TaxRate"]; ok {
+		tariff.TaxRate, _ = db.AttrToFloat(attrVal)
+		tariff.TaxName = db.AttrToStr(tariffRec["TaxName"])
+	}
+	if attrVal, ok := tariffRec["CancelFee"]; ok {
+		tariff.CancelFee, _ = db.AttrToNum64(attrVal)
+	}
+	return tariff, nil
+}
+
+//toFare ...
+func toFare(f *fare.Fare) *mycabsapi.Fare {
+	return &mycabsapi.Fare{
+		Currency:   f.Currency,
+		Base:       f.Base,
+		Distance:   f.Distance,
+		Time:       f.Time,
+		Minimum:    f.Minimum,
+		Surge:      f.Surge,
+		InterCity:  f.InterCity,
+		Discount:   f.Discount,
+		PromoCode:  f.PromoCode,
+		Total:      f.Total,
+		Multiplier: f.Multiplier,
+	}
+}
+
+//fareAttrs are the attributes storing the fare of a trip.
+func fareAttrs(f *mycabsapi.Fare) map[string]*dynamodb.AttributeValue {
+	if f == nil {
+		return nil
+	}
+	return map[string]*dynamodb.AttributeValue{
+		"Currency":      db.StrToAttr(f.Currency),
+		"FareBase":      db.Num64ToAttr(f.Base),
+		"FareDistance":  db.Num64ToAttr(f.Distance),
+		"FareTime":      db.Num64ToAttr(f.Time),
+		"FareMinimum":   db.Num64ToAttr(f.Minimum),
+		"FareSurge":     db.Num64ToAttr(f.Surge),
+		"FareInterCity": db.Num64ToAttr(f.InterCity),
+		"Fare":          db.Num64ToAttr(f.Total),
+		"FareDiscount":  db.Num64ToAttr(f.Discount),
+	}
+}
+
+//attrsToFare returns the fare of the trip, nil if it has none.
+func attrsToFare(rec map[string]*dynamodb.AttributeValue) *mycabsapi.Fare {
+	attrVal, ok := rec["Fare"]
+	if !ok {
+		return nil
+	}
+	f := &mycabsapi.Fare{
+		Currency: db.AttrToStr(rec["Currency"]),
+	}
+	f.Total, _ = db.AttrToNum64(attrVal)
+	f.Base, _ = db.AttrToNum64(rec["FareBase"])
+	f.Distance, _ = db.AttrToNum64(rec["FareDistance"])
+	f.Time, _ = db.AttrToNum64(rec["FareTime"])
+	f.Minimum, _ = db.AttrToNum64(rec["FareMinimum"])
+	f.InterCity, _ = db.AttrToNum64(rec["FareInterCity"])
+	f.Multiplier = 1
+	if attrVal, ok := rec["FareSurge"]; ok {
+		f.Surge, _ = db.AttrToNum64(attrVal)
+		f.Multiplier, _ = db.AttrToFloat(rec["Surge"])
+	}
+	if attrVal, ok := rec["FareDiscount"]; ok {
+		f.Discount, _ = db.AttrToNum64(attrVal)
+		f.PromoCode = attrsToPromoCode(rec)
+	}
+	return f
+}
+
+//tariffKeys ...
+func tariffKeys(cityID, cabType string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(hkeyValTariffs),
+		db.RKeyName: db.StrToAttr(cityID + "/" + cabType),
+	}
+}
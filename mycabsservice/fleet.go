@@ -0,0 +1,304 @@
+package mycabsservice
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mycabs/db"
+	"mycabs/mycabsapi"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+//The fleet is searched a page at a time, reading at most the limit of cabs of
+//the page in the order asked for and keeping the ones matching the filters, so
+//a page can come short, or empty, with a cursor for the next one. Sorted by id
+//the cabs partition is read itself. The other sorts read a partition listing
+//every cab under its sort key followed by its ID, kept along with the cabs; an
+//entry whose cab has moved on since is stale and skipped. Only IDLE cabs are
+//listed by idle time. The cursor is the key of the last entry read.
+
+const (
+	hkeyValCabNames    = "cabnames/"
+	hkeyValIdleCabs    = "idlecabs/"
+	fleetSortID        = "id"
+	fleetSortName      = "name"
+	fleetSortIdleSince = "idlesince"
+	fleetPageSize      = 50
+	fleetMaxPageSize   = 200
+)
+
+//fleetFields are the attributes of the cab record behind each field of the
+//search response.
+var fleetFields = map[string][]string{
+	"id":        {"Id"},
+	"name":      {"Name"},
+	"type":      {"Type"},
+	"city":      {"CityID"},
+	"state":     {"State"},
+	"idlesince": {"IdleSince"},
+	"location":  {"Lat", "Lon"},
+	"tripid":    {"TripID"},
+	"driverid":  {"DriverID"},
+	"plate":     {"Plate"},
+	"seats":     {"Seats"},
+}
+
+//fleetFilterAttrs are the attributes the filters and the sort keys are on.
+var fleetFilterAttrs = []string{"Id", "Name", "Type", "CityID", "State", "IdleSince"}
+
+//fleetSortHKeys are the partitions of the sorts but id.
+var fleetSortHKeys = map[string]string{
+	fleetSortName:      hkeyValCabNames,
+	fleetSortIdleSince: hkeyValIdleCabs,
+}
+
+//fleetSortKeys return the key the cab is listed under for each sort but id,
+//empty when it is not listed.
+var fleetSortKeys = map[string]func(cabRec map[string]*dynamodb.AttributeValue) string{
+	fleetSortName: func(cabRec map[string]*dynamodb.AttributeValue) string {
+		name := ""
+		if attrVal, ok := cabRec["Name"]; ok {
+			name = db.AttrToStr(attrVal)
+		}
+		return name + "|" + db.AttrToStr(cabRec["Id"])
+	},
+	fleetSortIdleSince: func(cabRec map[string]*dynamodb.AttributeValue) string {
+		if db.AttrToStr(cabRec["State"]) != stateIdle {
+			return ""
+		}
+		idleSince, _ := db.AttrToNum64(cabRec["IdleSince"])
+		return fmt.Sprintf("%020d/%v", idleSince, db.AttrToStr(cabRec["Id"]))
+	},
+}
+
+//SearchCabs returns a page of the cabs matching the filters of the request.
+func SearchCabs(req *mycabsapi.SearchCabsRequest) (*mycabsapi.SearchCabsResponse, error) {
+	after, err := decodeFleetCursor(req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &db.Page{
+		After:    after,
+		Limit:    req.Limit,
+		Backward: req.Desc,
+		Attrs:    fleetAttrs(req),
+	}
+	var cabRecords []map[string]*dynamodb.AttributeValue
+	var next string
+	if req.Sort == fleetSortID {
+		cabRecords, next, err = db.QueryPage(tableName, hkeyValCabs, nil, page)
+	} else {
+		cabRecords, next, err = sortedFleetPage(req.Sort, page)
+	}
+	if err != nil {
+		fmt.Printf("SearchCabs: read of %v Failed. Err: %v\n", req.Sort, err)
+		return nil, err
+	}
+
+	resp := &mycabsapi.SearchCabsResponse{
+		Cabs:   []*mycabsapi.Cab{},
+		Cursor: encodeFleetCursor(next),
+	}
+	now := time.Now()
+	for _, cabRec := range cabRecords {
+		if fleetMatch(req, cabRec, now) {
+			resp.Cabs = append(resp.Cabs, toFleetCab(cabRec, req.Fields))
+		}
+	}
+	return resp, nil
+}
+
+//sortedFleetPage reads a page of the partition of the sort, and the cabs its
+//entries are still current for, in its order.
+func sortedFleetPage(sort string, page *db.Page) ([]map[string]*dynamodb.AttributeValue, string, error) {
+	attrs := page.Attrs
+	page.Attrs = []string{"CabID"}
+	entries, next, err := db.QueryPage(tableName, fleetSortHKeys[sort], nil, page)
+	if err != nil || len(entries) == 0 {
+		return nil, next, err
+	}
+
+	keys := make([]map[string]*dynamodb.AttributeValue, 0, len(entries))
+	for _, entry := range entries {
+		keys = append(keys, cabKeys(db.AttrToStr(entry["CabID"])))
+	}
+	if len(attrs) > 0 {
+		attrs = append([]string{db.RKeyName}, attrs...)
+	}
+	cabRecords, err := db.BatchGet(tableName, keys, attrs)
+	if err != nil {
+		return nil, "", err
+	}
+	byKey := make(map[string]map[string]*dynamodb.AttributeValue, len(cabRecords))
+	for _, cabRec := range cabRecords {
+		byKey[fleetSortKeys[sort](cabRec)] = cabRec
+	}
+	sorted := make([]map[string]*dynamodb.AttributeValue, 0, len(entries))
+	for _, entry := range entries {
+		if cabRec, ok := byKey[db.AttrToStr(entry[db.RKeyName])]; ok {
+			sorted = append(sorted, cabRec)
+		}
+	}
+	return sorted, next, nil
+}
+
+//fleetIndexWrites keep the sort partitions up to date with the cab moving from
+//oldRec, nil for a new cab, to newRec.
+func fleetIndexWrites(oldRec, newRec map[string]*dynamodb.AttributeValue) []*db.TxWrite {
+	writes := []*db.TxWrite{}
+	for sort, sortKey := range fleetSortKeys {
+		oldKey, newKey := "", sortKey(newRec)
+		if oldRec != nil {
+			oldKey = sortKey(oldRec)
+		}
+		if oldKey == newKey {
+			continue
+		}
+		if oldKey != "" {
+			writes = append(writes, &db.TxWrite{Delete: fleetIndexKeys(sort, oldKey)})
+		}
+		if newKey != "" {
+			writes = append(writes, &db.TxWrite{Put: fleetIndexRecord(sort, newKey, db.AttrToStr(newRec["Id"]))})
+		}
+	}
+	return writes
+}
+
+//fleetIndexRecords are the entries of the cab in the sort partitions.
+func fleetIndexRecords(cabRec map[string]*dynamodb.AttributeValue) []map[string]*dynamodb.AttributeValue {
+	records := []map[string]*dynamodb.AttributeValue{}
+	for _, write := range fleetIndexWrites(nil, cabRec) {
+		records = append(records, write.Put)
+	}
+	return records
+}
+
+//fleetIndexRecord ...
+func fleetIndexRecord(sort, key, cabID string) map[string]*dynamodb.AttributeValue {
+	record := fleetIndexKeys(sort, key)
+	record["CabID"] = db.StrToAttr(cabID)
+	return record
+}
+
+//fleetIndexKeys ...
+func fleetIndexKeys(sort, key string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(fleetSortHKeys[sort]),
+		db.RKeyName: db.StrToAttr(key),
+	}
+}
+
+//fleetMatch tells if the cab matches the filters of the request.
+func fleetMatch(req *mycabsapi.SearchCabsRequest, cabRec map[string]*dynamodb.AttributeValue, now time.Time) bool {
+	str := func(attr string) string {
+		if attrVal, ok := cabRec[attr]; ok {
+			return db.AttrToStr(attrVal)
+		}
+		return ""
+	}
+	equals := map[string]string{
+		"CityID": req.CityID,
+		"Type":   req.Type,
+		"State":  req.State,
+	}
+	if req.IdleMinutes > 0 {
+		equals["State"] = stateIdle
+	}
+	for attr, value := range equals {
+		if value != "" && str(attr) != value {
+			return false
+		}
+	}
+	if req.IdleMinutes > 0 {
+		idleSince, _ := db.AttrToNum64(cabRec["IdleSince"])
+		if idleSince >= now.Add(-time.Duration(req.IdleMinutes)*time.Minute).Unix() {
+			return false
+		}
+	}
+	return strings.HasPrefix(str("Name"), req.NamePrefix)
+}
+
+//fleetAttrs are the attributes of the cab records read for the search, all
+//of them when it asks for every field.
+func fleetAttrs(req *mycabsapi.SearchCabsRequest) []string {
+	if len(req.Fields) == 0 {
+		return nil
+	}
+	//The filters, and the sort key, are on attributes which may not be asked for.
+	attrs := append([]string{}, fleetFilterAttrs...)
+	for _, field := range req.Fields {
+		attrs = append(attrs, fleetFields[field]...)
+	}
+	return attrs
+}
+
+//toFleetCab returns the fields of the cab asked for, all when none are.
+func toFleetCab(cabRec map[string]*dynamodb.AttributeValue, fields []string) *mycabsapi.Cab {
+	want := func(field string) bool {
+		return len(fields) == 0 || contains(fields, field)
+	}
+	str := func(attr string) string {
+		if attrVal, ok := cabRec[attr]; ok {
+			return db.AttrToStr(attrVal)
+		}
+		return ""
+	}
+
+	cab := &mycabsapi.Cab{}
+	if want("id") {
+		cab.ID = db.AttrToStr(cabRec[db.RKeyName])
+	}
+	if want("name") {
+		cab.Name = str("Name")
+	}
+	if want("type") {
+		cab.Type = str("Type")
+	}
+	if want("city") {
+		cab.City = str("CityID")
+	}
+	if want("state") {
+		cab.State = str("State")
+	}
+	if want("tripid") {
+		cab.TripID = str("TripID")
+	}
+	if want("driverid") {
+		cab.DriverID = str("DriverID")
+	}
+	if want("plate") {
+		cab.Plate = str("Plate")
+	}
+	if attrVal, ok := cabRec["Seats"]; ok && want("seats") {
+		cab.Seats, _ = db.AttrToNum(attrVal)
+	}
+	if attrVal, ok := cabRec["IdleSince"]; ok && want("idlesince") && str("State") == stateIdle {
+		idleSince, _ := db.AttrToNum64(attrVal)
+		cab.IdleSince = time.Unix(idleSince, 0).UTC().Format(time.RFC3339)
+	}
+	if want("location") {
+		cab.Location = attrsToLocation(cabRec)
+	}
+	return cab
+}
+
+//encodeFleetCursor ...
+func encodeFleetCursor(key string) string {
+	if key == "" {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+//decodeFleetCursor returns the key of the entry the page starts after, empty
+//for the first page.
+func decodeFleetCursor(cursor string) (string, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", &rejectedError{reason: fmt.Sprintf("invalid cursor %q", cursor)}
+	}
+	return string(decoded), nil
+}
@@ -0,0 +1,432 @@
+package mycabsservice
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mycabs/db"
+	"mycabs/mycabsapi"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+//A fleet import is checked in full before anything is written. Rows which fail
+//are reported and skipped, the others are given IDs from a block taken off the
+//counters at once, and written in batches, cities first, the cabs counted for
+//their types along. A row is reported failed, with no ID, when one of its
+//records was not written, and so are the cabs of a city which failed. The rows
+//reported imported are all written, those failed can be imported again.
+
+const (
+	importFormatCSV   = "csv"
+	importFormatJSONL = "jsonl"
+	importKindCity    = "city"
+	importKindCab     = "cab"
+	importBatchSize   = 25
+	maxImportRows     = 10000
+)
+
+//importCSVColumns are the columns a CSV import can have, name being required.
+var importCSVColumns = []string{"kind", "name", "type", "cityid", "plate", "seats", "lat", "lon"}
+
+//importRow is a row of the import being checked and written.
+type importRow struct {
+	mycabsapi.ImportRow
+	result *mycabsapi.ImportRowResult
+	city   *importRow //The city of the same import the cab is in.
+	record map[string]*dynamodb.AttributeValue
+}
+
+func (row *importRow) fail(err error) {
+	if row.result.Error == "" {
+		row.result.Error = err.Error()
+	}
+}
+
+func (row *importRow) failed() bool {
+	return row.result.Error != ""
+}
+
+//ImportFleet imports the cities and cabs of the CSV or JSON-lines data. It
+//only fails on data it can't read, the rows which can't be imported are
+//reported as failed.
+func ImportFleet(data io.Reader, format string, dryRun bool) (*mycabsapi.ImportReport, error) {
+	var rows []*importRow
+	var err error
+	switch format {
+	case importFormatCSV:
+		rows, err = readImportCSV(data)
+	case importFormatJSONL:
+		rows, err = readImportJSONL(data)
+	default:
+		err = &rejectedError{reason: fmt.Sprintf("unknown import format %q, csv or jsonl", format)}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) > maxImportRows {
+		return nil, &rejectedError{reason: fmt.Sprintf("%v rows, at most %v can be imported at once", len(rows), maxImportRows)}
+	}
+
+	cities, cabs, err := checkImportRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	if !dryRun {
+		writeImportRows(cities, importKindCity)
+		writeImportRows(cabs, importKindCab)
+	}
+
+	report := &mycabsapi.ImportReport{
+		DryRun: dryRun,
+		Rows:   make([]*mycabsapi.ImportRowResult, 0, len(rows)),
+	}
+	for _, row := range rows {
+		if row.failed() {
+			report.Failed++
+		} else {
+			report.Imported++
+		}
+		report.Rows = append(report.Rows, row.result)
+	}
+	return report, nil
+}
+
+//checkImportRows validates the rows and returns the cities and cabs to import.
+func checkImportRows(rows []*importRow) (cities, cabs []*importRow, err error) {
+	catalog, err := loadCabTypes()
+	if err != nil {
+		return nil, nil, err
+	}
+	cabTypes := map[string]bool{}
+	for _, cabType := range catalog {
+		cabTypes[cabType.Name] = true
+	}
+
+	citiesByName := map[string]*importRow{}
+	for _, row := range rows {
+		if row.failed() || row.Kind != importKindCity {
+			continue
+		}
+		err := validateOnboardCityReq(&mycabsapi.OnboardCityRequest{Name: row.Name})
+		if err != nil {
+			row.fail(err)
+			continue
+		}
+		if _, ok := citiesByName[row.Name]; ok {
+			row.fail(fmt.Errorf("city %v is given twice", row.Name))
+			continue
+		}
+		citiesByName[row.Name] = row
+		cities = append(cities, row)
+	}
+
+	cityChecks := map[string]error{}
+	for _, row := range rows {
+		if row.failed() || row.Kind != importKindCab {
+			continue
+		}
+		req := &mycabsapi.RegisterCabRequest{
+			Name:     row.Name,
+			Type:     row.Type,
+			CityID:   row.CityID,
+			Location: row.Location,
+			Plate:    row.Plate,
+			Seats:    row.Seats,
+		}
+		err := validateRegisterCabReq(req)
+		if err != nil {
+			row.fail(err)
+			continue
+		}
+		row.Type, row.Plate = req.Type, req.Plate
+		if !cabTypes[row.Type] {
+			row.fail(fmt.Errorf("unknown cab type %q", row.Type))
+			continue
+		}
+		if city, ok := citiesByName[row.CityID]; ok {
+			row.city = city
+		} else {
+			checked, ok := cityChecks[row.CityID]
+			if !ok {
+				checked = checkCity(row.CityID, true)
+				cityChecks[row.CityID] = checked
+			}
+			if checked != nil {
+				row.fail(checked)
+				continue
+			}
+		}
+		cabs = append(cabs, row)
+	}
+	return cities, cabs, nil
+}
+
+//writeImportRows gives the rows IDs off the counter of their kind and writes
+//them in batches.
+func writeImportRows(rows []*importRow, kind string) {
+	for _, row := range rows {
+		if row.city != nil && row.city.failed() {
+			row.fail(fmt.Errorf("city on line %v was not imported", row.city.result.Line))
+		}
+	}
+	writable := make([]*importRow, 0, len(rows))
+	for _, row := range rows {
+		if !row.failed() {
+			writable = append(writable, row)
+		}
+	}
+	if len(writable) == 0 {
+		return
+	}
+
+	last, err := db.Increment(tableName, counterKeys(kind), "Counter", len(writable))
+	if err != nil {
+		fmt.Printf("writeImportRows: db.Increment of %v Failed. Err: %v\n", kind, err)
+		for _, row := range writable {
+			row.fail(err)
+		}
+		return
+	}
+	for idx, row := range writable {
+		row.result.ID = kind + "_" + strconv.Itoa(last-len(writable)+idx+1)
+		if kind == importKindCity {
+			row.record = newCityRecord(row.result.ID, row.Name)
+			continue
+		}
+		cityID := row.CityID
+		if row.city != nil {
+			cityID = row.city.result.ID
+		}
+		row.record = newCabRecord(row.result.ID, &mycabsapi.RegisterCabRequest{
+			Name:     row.Name,
+			Type:     row.Type,
+			CityID:   cityID,
+			Location: row.Location,
+			Plate:    row.Plate,
+			Seats:    row.Seats,
+		})
+	}
+
+	for start := 0; start < len(writable); start += importBatchSize {
+		end := start + importBatchSize
+		if end > len(writable) {
+			end = len(writable)
+		}
+		if kind == importKindCab {
+			err = countImportTypes(writable[start:end], 1)
+			if err != nil {
+				fmt.Printf("writeImportRows: countImportTypes Failed. Err: %v\n", err)
+				for _, row := range writable[start:end] {
+					row.result.ID = ""
+					row.fail(err)
+				}
+				continue
+			}
+		}
+		batch := writable[start:end]
+		if kind == importKindCab {
+			//The entries listing the cabs first, an entry with no cab is skipped
+			//when read but a cab with no entries would not be found.
+			batch = putImportRows(batch, cabListingRecords)
+		}
+		written := putImportRows(batch, func(row *importRow) []map[string]*dynamodb.AttributeValue {
+			return []map[string]*dynamodb.AttributeValue{row.record}
+		})
+		if kind == importKindCab && len(written) < end-start {
+			failed := make([]*importRow, 0, end-start-len(written))
+			for _, row := range writable[start:end] {
+				if row.failed() {
+					failed = append(failed, row)
+				}
+			}
+			if countErr := countImportTypes(failed, -1); countErr != nil {
+				fmt.Printf("writeImportRows: countImportTypes Failed. Err: %v\n", countErr)
+			}
+		}
+	}
+}
+
+//putImportRows writes the records of the rows, failing the rows with a record
+//which was not written. It returns the rows written.
+func putImportRows(rows []*importRow, records func(row *importRow) []map[string]*dynamodb.AttributeValue) []*importRow {
+	items := []map[string]*dynamodb.AttributeValue{}
+	for _, row := range rows {
+		items = append(items, records(row)...)
+	}
+	err := db.BatchPut(tableName, items)
+	if err == nil {
+		return rows
+	}
+	fmt.Printf("writeImportRows: db.BatchPut of %v rows Failed. Err: %v\n", len(rows), err)
+
+	batchErr, ok := err.(*db.BatchPutError)
+	unwritten := map[string]bool{}
+	if ok {
+		err = batchErr.Err
+		for _, item := range batchErr.Unwritten {
+			unwritten[importItemKey(item)] = true
+		}
+	}
+	written := make([]*importRow, 0, len(rows))
+	for _, row := range rows {
+		failed := !ok
+		for _, record := range records(row) {
+			failed = failed || unwritten[importItemKey(record)]
+		}
+		if !failed {
+			written = append(written, row)
+			continue
+		}
+		row.result.ID = ""
+		row.fail(fmt.Errorf("not written: %v", err))
+	}
+	return written
+}
+
+//cabListingRecords are the entries listing the cab of the row, in its geo
+//cell and in the fleet sort partitions.
+func cabListingRecords(row *importRow) []map[string]*dynamodb.AttributeValue {
+	records := fleetIndexRecords(row.record)
+	if cell := cabGeoCell(row.record); cell != "" {
+		records = append(records, cellRecord(cell, row.result.ID))
+	}
+	return records
+}
+
+//importItemKey ...
+func importItemKey(item map[string]*dynamodb.AttributeValue) string {
+	return db.AttrToStr(item[db.HKeyName]) + db.AttrToStr(item[db.RKeyName])
+}
+
+//countImportTypes counts the cabs of the rows for their types, all or none.
+func countImportTypes(rows []*importRow, sign int) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	counts := map[string]int{}
+	for _, row := range rows {
+		counts[row.Type]++
+	}
+	writes := make([]*db.TxWrite, 0, len(counts))
+	for cabType, count := range counts {
+		writes = append(writes, countCabType(cabType, sign*count))
+	}
+	err := db.Transact(tableName, writes)
+	if db.IsConditionFailed(err) {
+		return &rejectedError{reason: "a cab type was taken out of the catalog meanwhile"}
+	}
+	return err
+}
+
+//readImportCSV reads the rows of a CSV file with a header line.
+func readImportCSV(data io.Reader) ([]*importRow, error) {
+	reader := csv.NewReader(data)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, &rejectedError{reason: fmt.Sprintf("no CSV header: %v", err)}
+	}
+	columns := map[string]int{}
+	for idx, column := range header {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if !contains(importCSVColumns, column) {
+			return nil, &rejectedError{reason: fmt.Sprintf("unknown CSV column %q, columns are %v", column, strings.Join(importCSVColumns, ", "))}
+		}
+		columns[column] = idx
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, &rejectedError{reason: "no name column in the CSV header"}
+	}
+
+	rows := []*importRow{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		row := newImportRow(line)
+		rows = append(rows, row)
+		if err != nil {
+			if _, ok := err.(*csv.ParseError); !ok {
+				return nil, err
+			}
+			row.fail(err)
+			continue
+		}
+
+		field := func(column string) string {
+			if idx, ok := columns[column]; ok {
+				return strings.TrimSpace(record[idx])
+			}
+			return ""
+		}
+		row.Kind = field("kind")
+		row.Name = field("name")
+		row.Type = field("type")
+		row.CityID = field("cityid")
+		row.Plate = field("plate")
+		if seats := field("seats"); seats != "" {
+			row.Seats, err = strconv.Atoi(seats)
+			if err != nil {
+				row.fail(fmt.Errorf("seats %q is not a number", seats))
+			}
+		}
+		if lat, lon := field("lat"), field("lon"); lat != "" || lon != "" {
+			row.Location = &mycabsapi.Location{}
+			row.Location.Lat, err = strconv.ParseFloat(lat, 64)
+			if err == nil {
+				row.Location.Lon, err = strconv.ParseFloat(lon, 64)
+			}
+			if err != nil {
+				row.fail(fmt.Errorf("location %q, %q is not a number pair", lat, lon))
+			}
+		}
+		row.setKind()
+	}
+}
+
+//readImportJSONL reads the rows of a file with a json object per line.
+func readImportJSONL(data io.Reader) ([]*importRow, error) {
+	scanner := bufio.NewScanner(data)
+	rows := []*importRow{}
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		row := newImportRow(line)
+		rows = append(rows, row)
+		err := json.Unmarshal([]byte(text), &row.ImportRow)
+		if err != nil {
+			row.fail(err)
+			continue
+		}
+		row.setKind()
+	}
+	return rows, scanner.Err()
+}
+
+//newImportRow ...
+func newImportRow(line int) *importRow {
+	return &importRow{
+		result: &mycabsapi.ImportRowResult{Line: line, Kind: importKindCab},
+	}
+}
+
+//setKind checks the kind of the row, a cab when none is given.
+func (row *importRow) setKind() {
+	row.Kind = strings.ToLower(row.Kind)
+	if row.Kind == "" {
+		row.Kind = importKindCab
+	}
+	row.result.Kind = row.Kind
+	row.result.Name = row.Name
+	if row.Kind != importKindCab && row.Kind != importKindCity {
+		row.fail(fmt.Errorf("unknown kind %q, city or cab", row.Kind))
+	}
+}
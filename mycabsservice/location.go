@@ -0,0 +1,191 @@
+package mycabsservice
+
+import (
+	"fmt"
+	"mycabs/db"
+	"mycabs/geo"
+	"mycabs/mycabsapi"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+//Cabs with a known location carry Lat, Lon and GeoCell, the geohash of the
+//location with geoCellPrecision characters (a cell of about 5km x 5km). Every
+//such cab also has an entry in the partition of its cell, the spatial index:
+//nearby cabs are found by querying the partitions of the cell of the pickup
+//and of the cells around it. The entry is moved when the cab changes cells.
+//An entry left behind by a failed move is skipped, and dropped, as its cab is
+//in another cell.
+
+const (
+	hkeyValCells     = "cells/"
+	geoCellPrecision = 5
+)
+
+//UpdateCabLocation ...
+func UpdateCabLocation(req *mycabsapi.UpdateCabLocationRequest) error {
+	cabRec, err := loadCab(req.CabID)
+	if err != nil {
+		return err
+	}
+	updateInfo := locationAttrs(&mycabsapi.Location{Lat: req.Lat, Lon: req.Lon})
+	updateInfo["LocationTime"] = db.Num64ToAttr(unixMillis(time.Now()))
+	cond := map[string]*dynamodb.AttributeValue{
+		"Id": db.StrToAttr(req.CabID),
+	}
+
+	err = db.UpdateExclusive(tableName, cabKeys(req.CabID), updateInfo, cond)
+	if db.IsConditionFailed(err) {
+		return &notFoundError{kind: "cab", id: req.CabID}
+	}
+	if err != nil {
+		fmt.Printf("UpdateCabLocation: db.UpdateExclusive Failed. Err: %v\n", err)
+		return err
+	}
+	return moveCabCell(req.CabID, cabGeoCell(cabRec), db.AttrToStr(updateInfo["GeoCell"]))
+}
+
+//moveCabCell moves the entry of the cab in the spatial index from one cell to
+//the other, either of which can be empty.
+func moveCabCell(cabID, fromCell, toCell string) error {
+	if fromCell == toCell {
+		return nil
+	}
+	if toCell != "" {
+		err := db.Put(tableName, cellRecord(toCell, cabID))
+		if err != nil {
+			fmt.Printf("moveCabCell: db.Put of %v Failed. Err: %v\n", cabID, err)
+			return err
+		}
+	}
+	if fromCell != "" {
+		err := db.Delete(tableName, cellRecord(fromCell, cabID), nil)
+		if err != nil {
+			//Skipped as stale when read.
+			fmt.Printf("moveCabCell: db.Delete of %v Failed. Err: %v\n", cabID, err)
+		}
+	}
+	return nil
+}
+
+//nearbyIdleCabs returns the idle cabs of the type in the city, with a driver
+//on shift, which are in the cells around the pickup.
+func nearbyIdleCabs(cityID, cabType string, pickup *mycabsapi.Location) ([]map[string]*dynamodb.AttributeValue, error) {
+	entries := map[string][]string{} //Cells of the entries of each cab.
+	keys := []map[string]*dynamodb.AttributeValue{}
+	for _, cell := range geo.Neighbours(geo.Encode(pickup.Lat, pickup.Lon, geoCellPrecision)) {
+		cellRecords, err := db.Query(tableName, cellHKey(cell), nil)
+		if err != nil {
+			fmt.Printf("nearbyIdleCabs: db.Query of cell %v Failed. Err: %v\n", cell, err)
+			return nil, err
+		}
+		for _, cellRec := range cellRecords {
+			cabID := db.AttrToStr(cellRec[db.RKeyName])
+			if len(entries[cabID]) == 0 {
+				keys = append(keys, cabKeys(cabID))
+			}
+			entries[cabID] = append(entries[cabID], cell)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	cabRecords, err := db.BatchGet(tableName, keys, nil)
+	if err != nil {
+		fmt.Printf("nearbyIdleCabs: db.BatchGet Failed. Err: %v\n", err)
+		return nil, err
+	}
+	idle := []map[string]*dynamodb.AttributeValue{}
+	for _, cabRec := range cabRecords {
+		cabID := db.AttrToStr(cabRec["Id"])
+		for _, cell := range entries[cabID] {
+			if cell != cabGeoCell(cabRec) {
+				//Left behind by a failed move.
+				db.Delete(tableName, cellRecord(cell, cabID), nil)
+			}
+		}
+		if contains(entries[cabID], cabGeoCell(cabRec)) && isIdleCab(cabRec, cityID, cabType) {
+			idle = append(idle, cabRec)
+		}
+	}
+	return idle, nil
+}
+
+//cabGeoCell is the cell of the cab, empty when its location is not known.
+func cabGeoCell(cabRec map[string]*dynamodb.AttributeValue) string {
+	if attrVal, ok := cabRec["GeoCell"]; ok {
+		return db.AttrToStr(attrVal)
+	}
+	return ""
+}
+
+//cellRecord is the entry of the cab in the partition of the cell.
+func cellRecord(cell, cabID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(cellHKey(cell)),
+		db.RKeyName: db.StrToAttr(cabID),
+	}
+}
+
+//cellHKey ...
+func cellHKey(cell string) string {
+	return hkeyValCells + cell + "/"
+}
+
+//unixMillis is the time in milliseconds, the resolution of LocationTime.
+func unixMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+//locationAttrs are the cab attributes for the location.
+func locationAttrs(loc *mycabsapi.Location) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"Lat":     db.FloatToAttr(loc.Lat),
+		"Lon":     db.FloatToAttr(loc.Lon),
+		"GeoCell": db.StrToAttr(geo.Encode(loc.Lat, loc.Lon, geoCellPrecision)),
+	}
+}
+
+//attrsToLocation returns the location of the cab, nil if it is not known.
+func attrsToLocation(cabRec map[string]*dynamodb.AttributeValue) *mycabsapi.Location {
+	return attrsToPoint(cabRec, "Lat", "Lon")
+}
+
+//pickupAttrs are the attributes storing the pickup of a ticket or a reservation.
+func pickupAttrs(pickup *mycabsapi.Location) map[string]*dynamodb.AttributeValue {
+	if pickup == nil {
+		return nil
+	}
+	return map[string]*dynamodb.AttributeValue{
+		"PickupLat": db.FloatToAttr(pickup.Lat),
+		"PickupLon": db.FloatToAttr(pickup.Lon),
+	}
+}
+
+//attrsToPickup ...
+func attrsToPickup(rec map[string]*dynamodb.AttributeValue) *mycabsapi.Location {
+	return attrsToPoint(rec, "PickupLat", "PickupLon")
+}
+
+//attrsToPoint ...
+func attrsToPoint(rec map[string]*dynamodb.AttributeValue, latAttr, lonAttr string) *mycabsapi.Location {
+	latVal, ok := rec[latAttr]
+	if !ok {
+		return nil
+	}
+	lonVal, ok := rec[lonAttr]
+	if !ok {
+		return nil
+	}
+	lat, err := db.AttrToFloat(latVal)
+	if err != nil {
+		return nil
+	}
+	lon, err := db.AttrToFloat(lonVal)
+	if err != nil {
+		return nil
+	}
+	return &mycabsapi.Location{Lat: lat, Lon: lon}
+}
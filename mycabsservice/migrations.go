@@ -0,0 +1,248 @@
+package mycabsservice
+
+import (
+	"fmt"
+	"mycabs/db"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+//Migrations bring the records written by earlier versions up to date. Each is
+//run once, at startup, and marked done under hkeyValMigrations. Replicas
+//starting together may both run one, so they must be safe to run again.
+
+const (
+	hkeyValMigrations = "migrations/"
+)
+
+//migration ...
+type migration struct {
+	name string
+	run  func() error
+}
+
+//migrations run in order, a failed one is retried at the next startup.
+var migrations = []migration{
+	{name: "ttl", run: enableTTL},
+	{name: "cabtypes", run: normalizeCabTypes},
+	{name: "fleetindex", run: indexFleet},
+}
+
+//RunMigrations runs the migrations not done yet.
+func RunMigrations() {
+	for _, m := range migrations {
+		migrationKeys := map[string]*dynamodb.AttributeValue{
+			db.HKeyName: db.StrToAttr(hkeyValMigrations),
+			db.RKeyName: db.StrToAttr(m.name),
+		}
+		migrationRec, err := db.Get(tableName, migrationKeys)
+		if err != nil {
+			fmt.Printf("RunMigrations: db.Get of %v Failed. Err: %v\n", m.name, err)
+			return
+		}
+		if len(migrationRec) > 0 {
+			continue
+		}
+		err = m.run()
+		if err != nil {
+			fmt.Printf("RunMigrations: %v Failed. Err: %v\n", m.name, err)
+			return
+		}
+		err = db.PutIfNew(tableName, migrationKeys)
+		if err != nil && !db.IsConditionFailed(err) {
+			fmt.Printf("RunMigrations: db.PutIfNew of %v Failed. Err: %v\n", m.name, err)
+		}
+		fmt.Printf("RunMigrations: %v done\n", m.name)
+	}
+}
+
+//enableTTL lets the table expire the records with an ExpiresAt.
+func enableTTL() error {
+	return db.EnableTTL(tableName, "ExpiresAt")
+}
+
+//normalizeCabTypes lower cases the cab types written before the catalog, on
+//cabs, tariffs, waiting tickets and scheduled reservations, and counts the
+//cabs of every type of the catalog. A type no one added to the catalog is
+//added without capabilities, SetCabType sets them.
+func normalizeCabTypes() error {
+	catalog, err := loadCabTypes()
+	if err != nil {
+		return err
+	}
+	counts := map[string]int{}
+	for _, cabType := range catalog {
+		counts[cabType.Name] = 0
+	}
+
+	cabRecords, _, err := db.QueryPage(tableName, hkeyValCabs, nil, &db.Page{Attrs: []string{"Id", "Type", "State"}})
+	if err != nil {
+		return err
+	}
+	for _, cabRec := range cabRecords {
+		cabType := strings.ToLower(db.AttrToStr(cabRec["Type"]))
+		if cabType != db.AttrToStr(cabRec["Type"]) {
+			updateInfo := map[string]*dynamodb.AttributeValue{"Type": db.StrToAttr(cabType)}
+			cond := map[string]*dynamodb.AttributeValue{"Type": cabRec["Type"]}
+			err = db.UpdateExclusive(tableName, cabKeys(db.AttrToStr(cabRec["Id"])), updateInfo, cond)
+			if err != nil && !db.IsConditionFailed(err) {
+				return err
+			}
+		}
+		if db.AttrToStr(cabRec["State"]) != stateRetired {
+			counts[cabType]++
+		}
+	}
+
+	tariffRecords, _, err := db.QueryPage(tableName, hkeyValTariffs, nil, &db.Page{})
+	if err != nil {
+		return err
+	}
+	for _, tariffRec := range tariffRecords {
+		cabType := strings.ToLower(db.AttrToStr(tariffRec["CabType"]))
+		counts[cabType] += 0
+		if cabType == db.AttrToStr(tariffRec["CabType"]) {
+			continue
+		}
+		cityID := db.AttrToStr(tariffRec["CityID"])
+		newRec := tariffKeys(cityID, cabType)
+		for attr, attrVal := range tariffRec {
+			if attr != db.HKeyName && attr != db.RKeyName {
+				newRec[attr] = attrVal
+			}
+		}
+		newRec["CabType"] = db.StrToAttr(cabType)
+		err = db.PutIfNew(tableName, newRec)
+		if err != nil && !db.IsConditionFailed(err) {
+			return err
+		}
+		err = db.Delete(tableName, tariffKeys(cityID, db.AttrToStr(tariffRec["CabType"])), nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	for cabType, count := range counts {
+		if cabType == "" {
+			continue
+		}
+		cabTypeRecord := cabTypeKeys(cabType)
+		cabTypeRecord["Name"] = db.StrToAttr(cabType)
+		cabTypeRecord["Seats"] = db.NumToAttr(0)
+		cabTypeRecord["Luggage"] = db.NumToAttr(0)
+		cabTypeRecord["Wheelchair"] = db.BoolToAttr(false)
+		cabTypeRecord["ChildSeat"] = db.BoolToAttr(false)
+		cabTypeRecord["Class"] = db.NumToAttr(0)
+		cabTypeRecord["Cabs"] = db.NumToAttr(count)
+		err = db.PutIfNew(tableName, cabTypeRecord)
+		if db.IsConditionFailed(err) {
+			//Already in the catalog, counted unless cabs were counted on it since.
+			err = db.Transact(tableName, []*db.TxWrite{{
+				Update:  cabTypeKeys(cabType),
+				Updates: map[string]*dynamodb.AttributeValue{"Cabs": db.NumToAttr(count)},
+				Absent:  []string{"Cabs"},
+			}})
+		}
+		if err != nil && !db.IsConditionFailed(err) {
+			return err
+		}
+	}
+
+	err = normalizeTicketCabTypes()
+	if err != nil {
+		return err
+	}
+	return normalizeReservationCabTypes()
+}
+
+//normalizeTicketCabTypes moves the waiting tickets to the waitlist of their
+//lower cased cab type.
+func normalizeTicketCabTypes() error {
+	filter := map[string]*dynamodb.Condition{
+		"State": &dynamodb.Condition{
+			ComparisonOperator: aws.String("EQ"),
+			AttributeValueList: []*dynamodb.AttributeValue{db.StrToAttr(ticketWaiting)},
+		},
+	}
+	ticketRecords, _, err := db.QueryPage(tableName, hkeyValTickets, filter, &db.Page{})
+	if err != nil {
+		return err
+	}
+	for _, ticketRec := range ticketRecords {
+		from, cabType := db.AttrToStr(ticketRec["From"]), db.AttrToStr(ticketRec["CabType"])
+		if strings.ToLower(cabType) == cabType {
+			continue
+		}
+		ticketID := db.AttrToStr(ticketRec["Id"])
+		err = db.Transact(tableName, []*db.TxWrite{
+			{
+				Delete: map[string]*dynamodb.AttributeValue{
+					db.HKeyName: db.StrToAttr(waitlistHKey(from, cabType)),
+					db.RKeyName: ticketRec["QueueKey"],
+				},
+				Cond: map[string]*dynamodb.AttributeValue{"TicketID": db.StrToAttr(ticketID)},
+			},
+			{
+				Put: map[string]*dynamodb.AttributeValue{
+					db.HKeyName: db.StrToAttr(waitlistHKey(from, strings.ToLower(cabType))),
+					db.RKeyName: ticketRec["QueueKey"],
+					"TicketID":  db.StrToAttr(ticketID),
+				},
+				New: true,
+			},
+			{
+				Update:  ticketKeys(ticketID),
+				Updates: map[string]*dynamodb.AttributeValue{"CabType": db.StrToAttr(strings.ToLower(cabType))},
+				Cond:    map[string]*dynamodb.AttributeValue{"State": db.StrToAttr(ticketWaiting)},
+			},
+		})
+		//A ticket served or cancelled meanwhile is left as it is.
+		if err != nil && !db.IsConditionFailed(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+//normalizeReservationCabTypes lower cases the cab type of the scheduled
+//reservations.
+func normalizeReservationCabTypes() error {
+	filter := map[string]*dynamodb.Condition{
+		"State": &dynamodb.Condition{
+			ComparisonOperator: aws.String("EQ"),
+			AttributeValueList: []*dynamodb.AttributeValue{db.StrToAttr(reservationScheduled)},
+		},
+	}
+	reservationRecords, _, err := db.QueryPage(tableName, hkeyValReservations, filter, &db.Page{Attrs: []string{"Id", "CabType"}})
+	if err != nil {
+		return err
+	}
+	for _, reservationRec := range reservationRecords {
+		cabType := db.AttrToStr(reservationRec["CabType"])
+		if strings.ToLower(cabType) == cabType {
+			continue
+		}
+		updateInfo := map[string]*dynamodb.AttributeValue{"CabType": db.StrToAttr(strings.ToLower(cabType))}
+		cond := map[string]*dynamodb.AttributeValue{"CabType": reservationRec["CabType"]}
+		err = db.UpdateExclusive(tableName, reservationKeys(db.AttrToStr(reservationRec["Id"])), updateInfo, cond)
+		if err != nil && !db.IsConditionFailed(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+//indexFleet lists the cabs registered before the fleet sort partitions in them.
+func indexFleet() error {
+	cabRecords, _, err := db.QueryPage(tableName, hkeyValCabs, nil, &db.Page{Attrs: fleetFilterAttrs})
+	if err != nil {
+		return err
+	}
+	indexRecords := []map[string]*dynamodb.AttributeValue{}
+	for _, cabRec := range cabRecords {
+		indexRecords = append(indexRecords, fleetIndexRecords(cabRec)...)
+	}
+	return db.BatchPut(tableName, indexRecords)
+}
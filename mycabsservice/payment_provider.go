@@ -0,0 +1,77 @@
+package mycabsservice
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+const defaultPaymentProvider = "fake"
+
+//PaymentProvider moves the money of riders and drivers. Every call carries the
+//ID of the journal it is made for as ref, a provider must not move the money
+//twice for the same ref so that settlements can be retried.
+type PaymentProvider interface {
+	Charge(riderID, method, currency string, amount int64, ref string) (txnID string, err error)
+	Refund(chargeTxnID, currency string, amount int64, ref string) (txnID string, err error)
+	Payout(driverID, currency string, amount int64, ref string) (txnID string, err error)
+}
+
+var paymentProviders = map[string]PaymentProvider{
+	"fake": newFakeProvider(),
+}
+
+//RegisterPaymentProvider makes a provider available under the given name, to
+//be picked with MYCABS_PAYMENT_PROVIDER. It is meant to be called at init time.
+func RegisterPaymentProvider(name string, provider PaymentProvider) {
+	paymentProviders[name] = provider
+}
+
+//paymentProvider returns the configured provider and its name.
+func paymentProvider() (string, PaymentProvider, error) {
+	name := os.Getenv("MYCABS_PAYMENT_PROVIDER")
+	if name == "" {
+		name = defaultPaymentProvider
+	}
+	provider, ok := paymentProviders[name]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown payment provider %v", name)
+	}
+	return name, provider, nil
+}
+
+//fakeProvider accepts every payment without moving any money, for local runs.
+type fakeProvider struct {
+	mu   sync.Mutex
+	seq  int
+	txns map[string]string //ref to txnID.
+}
+
+func newFakeProvider() *fakeProvider {
+	return &fakeProvider{txns: map[string]string{}}
+}
+
+func (p *fakeProvider) Charge(riderID, method, currency string, amount int64, ref string) (string, error) {
+	return p.txn("charge", ref), nil
+}
+
+func (p *fakeProvider) Refund(chargeTxnID, currency string, amount int64, ref string) (string, error) {
+	return p.txn("refund", ref), nil
+}
+
+func (p *fakeProvider) Payout(driverID, currency string, amount int64, ref string) (string, error) {
+	return p.txn("payout", ref), nil
+}
+
+//txn returns the transaction of ref, a new one if ref was not seen yet.
+func (p *fakeProvider) txn(kind, ref string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if txnID, ok := p.txns[ref]; ok {
+		return txnID
+	}
+	p.seq++
+	txnID := fmt.Sprintf("fake_%v_%v", kind, p.seq)
+	p.txns[ref] = txnID
+	return txnID
+}
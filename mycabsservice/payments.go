@@ -0,0 +1,432 @@
+package mycabsservice
+
+import (
+	"fmt"
+	"hash/fnv"
+	"mycabs/db"
+	"mycabs/ledger"
+	"mycabs/mycabsapi"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+//Journals are stored once under hkeyValJournals, keyed by an ID made from the
+//trip so that a settlement or refund which failed half way can be retried
+//without posting twice. Every posting also adds to the running balance of its
+//account and currency under the balances partition of the account, in the
+//same write as the journal.
+//
+//Settling a completed trip posts the charge of the fare to the rider, the
+//payment of the rider and, unless paid in cash, the payout of the driver
+//share. In cash the driver keeps the fare and owes the commission. Trips
+//booked on a corporate account are charged to the account, which pays them on
+//its monthly statement, and the driver is paid out as for a card.
+//
+//A rider cancelling a booking older than cancelFreeWindow is charged the
+//cancellation fee of the tariff when the trip is cancelled. The fee is then
+//settled and refunded as the fare of the trip.
+
+const (
+	hkeyValJournals = "journals/"
+	hkeyValBalances = "balances/"
+)
+
+const (
+	platformCommission = 0.20
+	cancelFreeWindow   = 5 * time.Minute
+)
+
+//paymentCorporate is the payment method of the trips billed to a corporate account.
+const paymentCorporate = "corporate"
+
+//SettleTrip charges the rider of a completed trip and pays the driver.
+func SettleTrip(req *mycabsapi.SettleTripRequest) (*mycabsapi.SettleTripResponse, error) {
+	tripRec, err := loadTrip(req.TripID)
+	if err != nil {
+		return nil, err
+	}
+	f := attrsToFare(tripRec)
+	if state := db.AttrToStr(tripRec["State"]); state != tripCompleted && (state != tripCancelled || f == nil) {
+		return nil, &rejectedError{reason: fmt.Sprintf("%v is %v, only completed trips and late cancellations are charged", req.TripID, state)}
+	}
+	riderID, driverID := tripRiderID(tripRec), tripDriverID(tripRec)
+	if f == nil || riderID == "" || driverID == "" {
+		return nil, &rejectedError{reason: fmt.Sprintf("%v has no fare, rider or driver to settle", req.TripID)}
+	}
+	riderRec, err := loadRider(riderID)
+	if err != nil {
+		return nil, err
+	}
+	providerName, provider, err := paymentProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &mycabsapi.SettleTripResponse{
+		TripID:     req.TripID,
+		Method:     db.AttrToStr(riderRec["DefaultPayment"]),
+		Currency:   f.Currency,
+		Total:      f.Total,
+		Commission: ledger.Commission(f.Total, platformCommission),
+	}
+	resp.DriverShare = resp.Total - resp.Commission
+	payer, driver := tripPayer(tripRec), ledger.DriverAccount(driverID)
+	if attrsToCorporateID(tripRec) != "" {
+		resp.Method = paymentCorporate
+	}
+
+	journals := []*ledger.Journal{{
+		ID:       req.TripID + "/charge",
+		Kind:     ledger.KindCharge,
+		Postings: ledger.Charge(payer, driver, resp.Total, resp.Commission),
+	}}
+	transfers := []func(ref string) (string, error){nil}
+	if resp.Method == "cash" {
+		journals = append(journals, &ledger.Journal{
+			ID:       req.TripID + "/payment",
+			Kind:     ledger.KindPayment,
+			Postings: ledger.Payment(payer, driver, resp.Total),
+		})
+		transfers = append(transfers, nil)
+	} else {
+		if resp.Method != paymentCorporate {
+			journals = append(journals, &ledger.Journal{
+				ID:       req.TripID + "/payment",
+				Kind:     ledger.KindPayment,
+				Postings: ledger.Payment(payer, ledger.ProviderAccount(providerName), resp.Total),
+			})
+			transfers = append(transfers, func(ref string) (string, error) {
+				return provider.Charge(riderID, resp.Method, resp.Currency, resp.Total, ref)
+			})
+		}
+		journals = append(journals, &ledger.Journal{
+			ID:       req.TripID + "/payout",
+			Kind:     ledger.KindPayout,
+			Postings: ledger.Payout(driver, ledger.ProviderAccount(providerName), resp.DriverShare),
+		})
+		transfers = append(transfers, func(ref string) (string, error) {
+			return provider.Payout(driverID, resp.Currency, resp.DriverShare, ref)
+		})
+	}
+
+	posted := 0
+	for idx, j := range journals {
+		j.TripID = req.TripID
+		j.Currency = resp.Currency
+		done, err := postJournal(j, transfers[idx])
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			posted++
+		}
+		resp.Journals = append(resp.Journals, j.ID)
+	}
+	if posted == 0 {
+		return nil, &rejectedError{reason: fmt.Sprintf("%v is already settled", req.TripID)}
+	}
+
+	updateInfo := map[string]*dynamodb.AttributeValue{
+		"SettledAt":     db.Num64ToAttr(time.Now().Unix()),
+		"PaymentMethod": db.StrToAttr(resp.Method),
+		"Commission":    db.Num64ToAttr(resp.Commission),
+	}
+	updateTrip(req.TripID, updateInfo)
+	return resp, nil
+}
+
+//RefundTrip gives back the amount, all that is left of the fare if none, to
+//the rider of a settled trip.
+func RefundTrip(req *mycabsapi.RefundTripRequest) (*mycabsapi.RefundTripResponse, error) {
+	tripRec, err := loadTrip(req.TripID)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := tripRec["SettledAt"]; !ok {
+		return nil, &rejectedError{reason: fmt.Sprintf("%v is not settled", req.TripID)}
+	}
+	f := attrsToFare(tripRec)
+	commission, _ := db.AttrToNum64(tripRec["Commission"])
+	method := db.AttrToStr(tripRec["PaymentMethod"])
+	refunded, refunds := int64(0), int64(0)
+	if attrVal, ok := tripRec["RefundCount"]; ok {
+		refunds, _ = db.AttrToNum64(attrVal)
+		refunded, _ = db.AttrToNum64(tripRec["Refunded"])
+	}
+	payer := tripPayer(tripRec)
+	refundID := req.TripID + "/refund/" + refundKey(req)
+
+	journalRec, err := db.Get(tableName, journalKeys(refundID))
+	if err != nil {
+		fmt.Printf("RefundTrip: db.Get of %v Failed. Err: %v\n", refundID, err)
+		return nil, err
+	}
+	amount := journalAmount(journalRec, payer)
+	if len(journalRec) == 0 {
+		amount = req.Amount
+		if amount == 0 {
+			amount = f.Total - refunded
+		}
+		if amount <= 0 || refunded+amount > f.Total {
+			return nil, &rejectedError{reason: fmt.Sprintf("%v has %v left to refund", req.TripID, f.Total-refunded)}
+		}
+
+		//The journal is posted with the refund taken on the trip, so that two
+		//refunds can't go over the fare and a retry finds the journal.
+		tripWrite := &db.TxWrite{
+			Update: tripKeys(req.TripID),
+			Updates: map[string]*dynamodb.AttributeValue{
+				"Refunded":    db.Num64ToAttr(refunded + amount),
+				"RefundCount": db.Num64ToAttr(refunds + 1),
+			},
+		}
+		if refunds == 0 {
+			tripWrite.Absent = []string{"RefundCount"}
+		} else {
+			tripWrite.Cond = map[string]*dynamodb.AttributeValue{
+				"RefundCount": db.Num64ToAttr(refunds),
+			}
+		}
+		_, err = postJournal(&ledger.Journal{
+			ID:       refundID,
+			Kind:     ledger.KindRefund,
+			TripID:   req.TripID,
+			Currency: f.Currency,
+			Postings: ledger.Refund(payer, ledger.DriverAccount(tripDriverID(tripRec)), amount, f.Total, commission),
+			Memo:     req.Reason,
+		}, nil, tripWrite)
+		if db.IsConditionFailed(err) {
+			return nil, &conflictError{kind: "trip", id: req.TripID}
+		}
+		if err != nil {
+			return nil, err
+		}
+		refunded += amount
+	}
+
+	resp := &mycabsapi.RefundTripResponse{
+		TripID:        req.TripID,
+		Currency:      f.Currency,
+		Refunded:      amount,
+		TotalRefunded: refunded,
+		Journals:      []string{refundID},
+	}
+	if method == "cash" || method == paymentCorporate {
+		//Nothing to send back through the provider, the credit stays on the account.
+		return resp, nil
+	}
+
+	chargeRec, err := db.Get(tableName, journalKeys(req.TripID+"/payment"))
+	if err != nil {
+		fmt.Printf("RefundTrip: db.Get of the payment Failed. Err: %v\n", err)
+		return nil, err
+	}
+	providerName, provider, err := paymentProvider()
+	if err != nil {
+		return nil, err
+	}
+	paymentID := refundID + "/payment"
+	_, err = postJournal(&ledger.Journal{
+		ID:       paymentID,
+		Kind:     ledger.KindRefund,
+		TripID:   req.TripID,
+		Currency: f.Currency,
+		//The provider pays the rider back.
+		Postings: ledger.Payment(ledger.ProviderAccount(providerName), payer, amount),
+	}, func(ref string) (string, error) {
+		return provider.Refund(db.AttrToStr(chargeRec["Ref"]), f.Currency, amount, ref)
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp.Journals = append(resp.Journals, paymentID)
+	return resp, nil
+}
+
+//Balance ...
+func Balance(req *mycabsapi.BalanceRequest) (*mycabsapi.BalanceResponse, error) {
+	balanceRecords, err := db.Query(tableName, balancesHKey(req.Account), nil)
+	if err != nil {
+		fmt.Printf("Balance: db.Query Failed. Err: %v\n", err)
+		return nil, err
+	}
+	resp := &mycabsapi.BalanceResponse{
+		Account:  req.Account,
+		Balances: map[string]int64{},
+	}
+	for _, balanceRec := range balanceRecords {
+		resp.Balances[db.AttrToStr(balanceRec[db.RKeyName])], _ = db.AttrToNum64(balanceRec["Balance"])
+	}
+	return resp, nil
+}
+
+//postJournal stores the journal and adds its postings to the balances, if it
+//was not posted yet. transfer, if any, moves the money at the provider first
+//and returns the transaction kept as the Ref of the journal. The extra writes
+//go through with the journal or not at all, the condition failing on one of
+//them is returned.
+func postJournal(j *ledger.Journal, transfer func(ref string) (string, error), extra ...*db.TxWrite) (posted bool, err error) {
+	journalRec, err := db.Get(tableName, journalKeys(j.ID))
+	if err != nil {
+		fmt.Printf("postJournal: db.Get of %v Failed. Err: %v\n", j.ID, err)
+		return false, err
+	}
+	if len(journalRec) != 0 {
+		return false, nil
+	}
+	if err = j.Validate(); err != nil {
+		fmt.Printf("postJournal: %v is invalid. Err: %v\n", j.ID, err)
+		return false, err
+	}
+	if transfer != nil {
+		j.Ref, err = transfer(j.ID)
+		if err != nil {
+			fmt.Printf("postJournal: Transfer of %v Failed. Err: %v\n", j.ID, err)
+			return false, err
+		}
+	}
+	j.Time = time.Now().Unix()
+
+	writes := []*db.TxWrite{&db.TxWrite{Put: journalRecord(j), New: true}}
+	accounts, balances := []string{}, map[string]int64{}
+	for _, p := range j.Postings {
+		if _, ok := balances[p.Account]; !ok {
+			accounts = append(accounts, p.Account)
+		}
+		balances[p.Account] += p.Amount
+	}
+	for _, account := range accounts {
+		writes = append(writes, &db.TxWrite{
+			Update: balanceKeys(account, j.Currency),
+			Adds: map[string]*dynamodb.AttributeValue{
+				"Balance": db.Num64ToAttr(balances[account]),
+			},
+		})
+	}
+	writes = append(writes, extra...)
+
+	err = db.Transact(tableName, writes)
+	if db.IsConditionFailed(err) && len(extra) == 0 {
+		//Posted meanwhile by a retry.
+		return false, nil
+	}
+	if err != nil && !db.IsConditionFailed(err) {
+		fmt.Printf("postJournal: db.Transact of %v Failed. Err: %v\n", j.ID, err)
+	}
+	return err == nil, err
+}
+
+//chargeCancellation posts the cancellation fee of the trip cancelled by party
+//at cancelledAt, if the rider cancelled late, and returns the fare to keep on
+//the trip, nil if there is no fee.
+func chargeCancellation(tripRec map[string]*dynamodb.AttributeValue, party string, cancelledAt time.Time) *mycabsapi.Fare {
+	bookedAt, _ := db.AttrToNum64(tripRec["BookedAt"])
+	if party != partyRider || cancelledAt.Sub(time.Unix(bookedAt, 0)) < cancelFreeWindow {
+		return nil
+	}
+	driverID := tripDriverID(tripRec)
+	cabType := db.AttrToStr(tripRec["CabType"])
+	if attrVal, ok := tripRec["BookedType"]; ok {
+		cabType = db.AttrToStr(attrVal)
+	}
+	tariff, err := loadTariff(db.AttrToStr(tripRec["From"]), cabType)
+	if err != nil || tariff.CancelFee == 0 || driverID == "" {
+		return nil
+	}
+
+	tripID := db.AttrToStr(tripRec["Id"])
+	f := &mycabsapi.Fare{Currency: tariff.Currency, Base: tariff.CancelFee, Total: tariff.CancelFee, Multiplier: 1}
+	_, err = postJournal(&ledger.Journal{
+		ID:       tripID + "/charge",
+		Kind:     ledger.KindCharge,
+		TripID:   tripID,
+		Currency: f.Currency,
+		Postings: ledger.Charge(tripPayer(tripRec), ledger.DriverAccount(driverID), f.Total, ledger.Commission(f.Total, platformCommission)),
+		Memo:     "cancellation fee",
+	}, nil)
+	if err != nil {
+		//SettleTrip posts the charge when it was not.
+		fmt.Printf("chargeCancellation: postJournal of %v Failed. Err: %v\n", tripID, err)
+	}
+	return f
+}
+
+//refundKey tells apart the refunds of a trip: the key of the request, else
+//the amount and reason asked for.
+func refundKey(req *mycabsapi.RefundTripRequest) string {
+	if req.Key != "" {
+		return req.Key
+	}
+	h := fnv.New32a()
+	h.Write([]byte(req.Reason))
+	return fmt.Sprintf("%d-%08x", req.Amount, h.Sum32())
+}
+
+//journalAmount is the amount credited to account by the journal, 0 if there is none.
+func journalAmount(journalRec map[string]*dynamodb.AttributeValue, account string) int64 {
+	amount := int64(0)
+	if attrVal, ok := journalRec["Postings"]; ok {
+		for _, p := range attrVal.L {
+			if db.AttrToStr(p.M["Account"]) == account {
+				posted, _ := db.AttrToNum64(p.M["Amount"])
+				amount -= posted
+			}
+		}
+	}
+	return amount
+}
+
+//tripPayer is the account charged for the trip.
+func tripPayer(tripRec map[string]*dynamodb.AttributeValue) string {
+	if corporateID := attrsToCorporateID(tripRec); corporateID != "" {
+		return ledger.CorporateAccount(corporateID)
+	}
+	return ledger.RiderAccount(tripRiderID(tripRec))
+}
+
+//journalRecord ...
+func journalRecord(j *ledger.Journal) map[string]*dynamodb.AttributeValue {
+	postings := make([]*dynamodb.AttributeValue, 0, len(j.Postings))
+	for _, p := range j.Postings {
+		postings = append(postings, &dynamodb.AttributeValue{M: map[string]*dynamodb.AttributeValue{
+			"Account": db.StrToAttr(p.Account),
+			"Amount":  db.Num64ToAttr(p.Amount),
+		}})
+	}
+	journalRecord := journalKeys(j.ID)
+	journalRecord["Id"] = db.StrToAttr(j.ID)
+	journalRecord["Kind"] = db.StrToAttr(j.Kind)
+	journalRecord["TripID"] = db.StrToAttr(j.TripID)
+	journalRecord["Currency"] = db.StrToAttr(j.Currency)
+	journalRecord["Postings"] = &dynamodb.AttributeValue{L: postings}
+	journalRecord["Time"] = db.Num64ToAttr(j.Time)
+	if j.Ref != "" {
+		journalRecord["Ref"] = db.StrToAttr(j.Ref)
+	}
+	if j.Memo != "" {
+		journalRecord["Memo"] = db.StrToAttr(j.Memo)
+	}
+	return journalRecord
+}
+
+//journalKeys ...
+func journalKeys(journalID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(hkeyValJournals),
+		db.RKeyName: db.StrToAttr(journalID),
+	}
+}
+
+//balanceKeys ...
+func balanceKeys(account, currency string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(balancesHKey(account)),
+		db.RKeyName: db.StrToAttr(currency),
+	}
+}
+
+//balancesHKey is the partition holding the balances of an account, one per currency.
+func balancesHKey(account string) string {
+	return hkeyValBalances + account + "/"
+}
@@ -0,0 +1,228 @@
+package mycabsservice
+
+import (
+	"fmt"
+	"mycabs/db"
+	"mycabs/mycabsapi"
+	"mycabs/surge"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+//Every booking request is counted in a per minute bucket of the demand
+//partition of its city and cab type. The surge multiplier compares the
+//bookings of the last surgeWindow with the idle cabs, and is kept under
+//hkeyValSurge so that it moves smoothly from one computation to the next.
+
+const (
+	hkeyValDemand = "demand/"
+	hkeyValSurge  = "surge/"
+)
+
+const (
+	surgeWindow   = 10 * time.Minute
+	surgeInterval = 30 * time.Second //How long a computed multiplier is reused.
+	maxSurgeCap   = 5.0
+)
+
+//Surge ...
+func Surge(req *mycabsapi.SurgeRequest) (*mycabsapi.SurgeResponse, error) {
+	return currentSurge(req.CityID, req.CabType)
+}
+
+//SetCitySurge ...
+func SetCitySurge(req *mycabsapi.CitySurgeRequest) error {
+	updateInfo := map[string]*dynamodb.AttributeValue{
+		"SurgeCap": db.FloatToAttr(req.Cap),
+	}
+	cond := map[string]*dynamodb.AttributeValue{
+		"Id": db.StrToAttr(req.CityID),
+	}
+	err := db.UpdateExclusive(tableName, cityKeys(req.CityID), updateInfo, cond)
+	if db.IsConditionFailed(err) {
+		return &notFoundError{kind: "city", id: req.CityID}
+	}
+	return err
+}
+
+//surgeMultiplier returns the multiplier of the city and cab type, no surge
+//if it cannot be worked out.
+func surgeMultiplier(cityID, cabType string) float64 {
+	resp, err := currentSurge(cityID, cabType)
+	if err != nil {
+		fmt.Printf("surgeMultiplier: currentSurge Failed. Err: %v\n", err)
+		return 1
+	}
+	return resp.Multiplier
+}
+
+//currentSurge returns the stored multiplier, computing a new one if it is older than surgeInterval.
+func currentSurge(cityID, cabType string) (*mycabsapi.SurgeResponse, error) {
+	surgeRec, err := db.Get(tableName, surgeKeys(cityID, cabType))
+	if err != nil {
+		fmt.Printf("currentSurge: db.Get Failed. Err: %v\n", err)
+		return nil, err
+	}
+
+	prev := 1.0
+	if attrVal, ok := surgeRec["Multiplier"]; ok {
+		prev, _ = db.AttrToFloat(attrVal)
+		updatedAt, _ := db.AttrToNum64(surgeRec["UpdatedAt"])
+		if time.Now().Unix()-updatedAt < int64(surgeInterval/time.Second) {
+			return toSurgeResponse(surgeRec), nil
+		}
+	}
+
+	demand, err := recentDemand(cityID, cabType)
+	if err != nil {
+		return nil, err
+	}
+	supply, err := idleSupply(cityID, cabType)
+	if err != nil {
+		return nil, err
+	}
+	params := citySurgeParams(cityID)
+
+	surgeRecord := surgeKeys(cityID, cabType)
+	surgeRecord["CityID"] = db.StrToAttr(cityID)
+	surgeRecord["CabType"] = db.StrToAttr(cabType)
+	surgeRecord["Multiplier"] = db.FloatToAttr(params.Next(prev, demand, supply))
+	surgeRecord["Demand"] = db.NumToAttr(demand)
+	surgeRecord["Supply"] = db.NumToAttr(supply)
+	surgeRecord["UpdatedAt"] = db.Num64ToAttr(time.Now().Unix())
+	err = db.Put(tableName, surgeRecord)
+	if err != nil {
+		//Still good for this request.
+		fmt.Printf("currentSurge: db.Put Failed. Err: %v\n", err)
+	}
+	return toSurgeResponse(surgeRecord), nil
+}
+
+//countDemand adds a booking request to the demand of the current minute.
+func countDemand(cityID, cabType string) {
+	demandKeys := map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(demandHKey(cityID, cabType)),
+		db.RKeyName: db.StrToAttr(demandMinute(time.Now())),
+	}
+	_, err := db.Increment(tableName, demandKeys, "Bookings", 1)
+	if err != nil {
+		//Just log the error, the booking goes on.
+		fmt.Printf("countDemand Failed: %v\n", err)
+	}
+}
+
+//recentDemand is the number of booking requests in the last surgeWindow.
+func recentDemand(cityID, cabType string) (int, error) {
+	return demandIn(cityID, cabType, surgeWindow)
+}
+
+//demandIn is the number of booking requests in the last window.
+func demandIn(cityID, cabType string, window time.Duration) (int, error) {
+	now := time.Now()
+	demandRecords, err := db.QueryBetween(tableName, demandHKey(cityID, cabType),
+		demandMinute(now.Add(-window)), demandMinute(now))
+	if err != nil {
+		fmt.Printf("demandIn: db.QueryBetween Failed. Err: %v\n", err)
+		return 0, err
+	}
+
+	demand := 0
+	for _, demandRec := range demandRecords {
+		bookings, _ := db.AttrToNum(demandRec["Bookings"])
+		demand += bookings
+	}
+	return demand, nil
+}
+
+//idleSupply is the number of idle cabs of the type in the city.
+func idleSupply(cityID, cabType string) (int, error) {
+	//All the pages, reading only the ids to count them.
+	cabRecords, _, err := db.QueryPage(tableName, hkeyValCabs, idleCabsFilter(cityID, cabType), &db.Page{Attrs: []string{"Id"}})
+	if err != nil {
+		fmt.Printf("idleSupply: db.QueryPage Failed. Err: %v\n", err)
+		return 0, err
+	}
+	return len(cabRecords), nil
+}
+
+//citySurgeParams are the default params with the cap of the city.
+func citySurgeParams(cityID string) surge.Params {
+	params := surge.Default
+	cityRec, err := db.Get(tableName, cityKeys(cityID))
+	if err != nil {
+		fmt.Printf("citySurgeParams: db.Get Failed. Err: %v\n", err)
+	}
+	if attrVal, ok := cityRec["SurgeCap"]; ok {
+		params.Cap, _ = db.AttrToFloat(attrVal)
+	}
+	return params
+}
+
+//idleCabsFilter selects the idle cabs of the type in the city which have a
+//driver on shift.
+func idleCabsFilter(cityID, cabType string) map[string]*dynamodb.Condition {
+	return map[string]*dynamodb.Condition{
+		//Cleared to empty at clock out.
+		"DriverID": &dynamodb.Condition{
+			ComparisonOperator: aws.String("GT"),
+			AttributeValueList: []*dynamodb.AttributeValue{db.StrToAttr("")},
+		},
+		"CityID": &dynamodb.Condition{
+			ComparisonOperator: aws.String("EQ"),
+			AttributeValueList: []*dynamodb.AttributeValue{db.StrToAttr(cityID)},
+		},
+		"Type": &dynamodb.Condition{
+			ComparisonOperator: aws.String("EQ"),
+			AttributeValueList: []*dynamodb.AttributeValue{db.StrToAttr(cabType)},
+		},
+		"State": &dynamodb.Condition{
+			ComparisonOperator: aws.String("EQ"),
+			AttributeValueList: []*dynamodb.AttributeValue{db.StrToAttr(stateIdle)},
+		},
+	}
+}
+
+//isIdleCab tells if the cab is one idleCabsFilter selects.
+func isIdleCab(cabRec map[string]*dynamodb.AttributeValue, cityID, cabType string) bool {
+	str := func(attr string) string {
+		if attrVal, ok := cabRec[attr]; ok {
+			return db.AttrToStr(attrVal)
+		}
+		return ""
+	}
+	return str("DriverID") != "" && str("CityID") == cityID &&
+		str("Type") == cabType && str("State") == stateIdle
+}
+
+//toSurgeResponse ...
+func toSurgeResponse(surgeRec map[string]*dynamodb.AttributeValue) *mycabsapi.SurgeResponse {
+	resp := &mycabsapi.SurgeResponse{
+		CityID:  db.AttrToStr(surgeRec["CityID"]),
+		CabType: db.AttrToStr(surgeRec["CabType"]),
+	}
+	resp.Multiplier, _ = db.AttrToFloat(surgeRec["Multiplier"])
+	resp.Demand, _ = db.AttrToNum(surgeRec["Demand"])
+	resp.Supply, _ = db.AttrToNum(surgeRec["Supply"])
+	resp.UpdatedAt = attrToTime(surgeRec["UpdatedAt"])
+	return resp
+}
+
+//demandMinute is the bucket of the demand partition for t.
+func demandMinute(t time.Time) string {
+	return fmt.Sprintf("%012d", t.Unix()/60)
+}
+
+//demandHKey ...
+func demandHKey(cityID, cabType string) string {
+	return hkeyValDemand + cityID + "/" + cabType + "/"
+}
+
+//surgeKeys ...
+func surgeKeys(cityID, cabType string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(hkeyValSurge),
+		db.RKeyName: db.StrToAttr(cityID + "/" + cabType),
+	}
+}
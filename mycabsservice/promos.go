@@ -0,0 +1,270 @@
+package mycabsservice
+
+import (
+	"fmt"
+	"mycabs/db"
+	"mycabs/fare"
+	"mycabs/mycabsapi"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+//Promo codes are kept under hkeyValPromos with Uses counting the trips they
+//were applied to, and the uses of each rider under the promo uses partition of
+//the code. A code is checked when the trip is booked and redeemed when its fare
+//is computed at EndTrip, so cancelled trips don't use it up. Both counters only
+//move while below their limit, a trip which finds the code used up meanwhile is
+//charged in full.
+
+const (
+	hkeyValPromos    = "promos/"
+	hkeyValPromoUses = "promouses/"
+)
+
+//CreatePromo ...
+func CreatePromo(req *mycabsapi.Promo) error {
+	promoRecord := newPromoRecord(req)
+	err := db.PutIfNew(tableName, promoRecord)
+	if db.IsConditionFailed(err) {
+		return promoTakenError(req)
+	}
+	if err != nil {
+		fmt.Printf("CreatePromo: db.PutIfNew Failed. Err: %v\n", err)
+	}
+	return err
+}
+
+//promoTakenError ...
+func promoTakenError(req *mycabsapi.Promo) error {
+	return &rejectedError{reason: fmt.Sprintf("promo code %v is taken", strings.ToUpper(req.Code))}
+}
+
+//newPromoRecord ...
+func newPromoRecord(req *mycabsapi.Promo) map[string]*dynamodb.AttributeValue {
+	code := strings.ToUpper(req.Code)
+	validFrom, _ := time.Parse(time.RFC3339, req.ValidFrom)
+	validTo, _ := time.Parse(time.RFC3339, req.ValidTo)
+
+	promoRecord := promoKeys(code)
+	promoRecord["Code"] = db.StrToAttr(code)
+	promoRecord["Percent"] = db.FloatToAttr(req.Percent)
+	promoRecord["MaxOff"] = db.Num64ToAttr(req.MaxOff)
+	promoRecord["Flat"] = db.Num64ToAttr(req.Flat)
+	promoRecord["ValidFrom"] = db.Num64ToAttr(validFrom.Unix())
+	promoRecord["ValidTo"] = db.Num64ToAttr(validTo.Unix())
+	promoRecord["MaxUses"] = db.NumToAttr(req.MaxUses)
+	promoRecord["MaxUsesPerRider"] = db.NumToAttr(req.MaxUsesPerRider)
+	promoRecord["Uses"] = db.NumToAttr(0)
+	if len(req.Cities) > 0 {
+		promoRecord["Cities"] = db.StrSetToAttr(req.Cities)
+	}
+	if len(req.CabTypes) > 0 {
+		promoRecord["CabTypes"] = db.StrSetToAttr(req.CabTypes)
+	}
+	return promoRecord
+}
+
+//Promo ...
+func Promo(req *mycabsapi.PromoRequest) (*mycabsapi.Promo, error) {
+	promoRec, err := loadPromo(req.Code)
+	if err != nil {
+		return nil, err
+	}
+	promo := &mycabsapi.Promo{
+		Code:      db.AttrToStr(promoRec["Code"]),
+		ValidFrom: attrToTime(promoRec["ValidFrom"]),
+		ValidTo:   attrToTime(promoRec["ValidTo"]),
+	}
+	promo.Percent, _ = db.AttrToFloat(promoRec["Percent"])
+	promo.MaxOff, _ = db.AttrToNum64(promoRec["MaxOff"])
+	promo.Flat, _ = db.AttrToNum64(promoRec["Flat"])
+	promo.MaxUses, _ = db.AttrToNum(promoRec["MaxUses"])
+	promo.MaxUsesPerRider, _ = db.AttrToNum(promoRec["MaxUsesPerRider"])
+	promo.Uses, _ = db.AttrToNum(promoRec["Uses"])
+	if attrVal, ok := promoRec["Cities"]; ok {
+		promo.Cities = db.AttrToStrSet(attrVal)
+	}
+	if attrVal, ok := promoRec["CabTypes"]; ok {
+		promo.CabTypes = db.AttrToStrSet(attrVal)
+	}
+	return promo, nil
+}
+
+//EndPromo stops the code from being used for new bookings from now.
+func EndPromo(req *mycabsapi.PromoRequest) error {
+	code := strings.ToUpper(req.Code)
+	updateInfo := map[string]*dynamodb.AttributeValue{
+		"ValidTo": db.Num64ToAttr(time.Now().Unix()),
+	}
+	cond := map[string]*dynamodb.AttributeValue{
+		"Code": db.StrToAttr(code),
+	}
+	err := db.UpdateExclusive(tableName, promoKeys(code), updateInfo, cond)
+	if db.IsConditionFailed(err) {
+		return &notFoundError{kind: "promo", id: code}
+	}
+	return err
+}
+
+//checkPromo returns the discount of the code for a trip of the rider booked
+//at the given time, nil if there is no code.
+func checkPromo(code, riderID, cityID, cabType string, at time.Time) (*fare.Discount, error) {
+	if code == "" {
+		return nil, nil
+	}
+	promoRec, err := loadPromo(code)
+	if _, ok := err.(*notFoundError); ok {
+		return nil, &rejectedError{reason: fmt.Sprintf("unknown promo code %v", code)}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return promoDiscount(promoRec, riderID, cityID, cabType, at)
+}
+
+//redeemPromo counts a use of the code by the rider and returns its discount,
+//nil if there is no code or it can't be used anymore.
+func redeemPromo(code, riderID, cityID, cabType string, at time.Time) *fare.Discount {
+	if code == "" {
+		return nil
+	}
+	promoRec, err := loadPromo(code)
+	if err != nil {
+		fmt.Printf("redeemPromo: loadPromo of %v Failed. Err: %v\n", code, err)
+		return nil
+	}
+	discount, err := promoDiscount(promoRec, riderID, cityID, cabType, at)
+	if err != nil {
+		fmt.Printf("redeemPromo: %v\n", err)
+		return nil
+	}
+
+	code = discount.Code
+	maxUses, _ := db.AttrToNum(promoRec["MaxUses"])
+	maxUsesPerRider, _ := db.AttrToNum(promoRec["MaxUsesPerRider"])
+	if riderID != "" {
+		_, err = countPromoUse(promoUseKeys(code, riderID), maxUsesPerRider)
+		if err != nil {
+			fmt.Printf("redeemPromo: %v is used up by %v. Err: %v\n", code, riderID, err)
+			return nil
+		}
+	}
+	_, err = countPromoUse(promoKeys(code), maxUses)
+	if err != nil {
+		fmt.Printf("redeemPromo: %v is used up. Err: %v\n", code, err)
+		if riderID != "" {
+			db.Increment(tableName, promoUseKeys(code, riderID), "Uses", -1)
+		}
+		return nil
+	}
+	return discount
+}
+
+//countPromoUse adds a use to the Uses counter at keys, if still under limit.
+func countPromoUse(keys map[string]*dynamodb.AttributeValue, limit int) (int, error) {
+	if limit <= 0 {
+		return db.Increment(tableName, keys, "Uses", 1)
+	}
+	return db.IncrementBelow(tableName, keys, "Uses", limit)
+}
+
+//promoDiscount checks that the promo applies to the trip and returns its discount.
+func promoDiscount(promoRec map[string]*dynamodb.AttributeValue, riderID, cityID, cabType string, at time.Time) (*fare.Discount, error) {
+	code := db.AttrToStr(promoRec["Code"])
+	validFrom, _ := db.AttrToNum64(promoRec["ValidFrom"])
+	validTo, _ := db.AttrToNum64(promoRec["ValidTo"])
+	if at.Unix() < validFrom || at.Unix() >= validTo {
+		return nil, &rejectedError{reason: fmt.Sprintf("promo %v is not valid at %v", code, at.Format(time.RFC3339))}
+	}
+	if attrVal, ok := promoRec["Cities"]; ok && !contains(db.AttrToStrSet(attrVal), cityID) {
+		return nil, &rejectedError{reason: fmt.Sprintf("promo %v is not valid in %v", code, cityID)}
+	}
+	if attrVal, ok := promoRec["CabTypes"]; ok && !contains(db.AttrToStrSet(attrVal), cabType) {
+		return nil, &rejectedError{reason: fmt.Sprintf("promo %v is not valid for %v cabs", code, cabType)}
+	}
+
+	maxUses, _ := db.AttrToNum(promoRec["MaxUses"])
+	uses, _ := db.AttrToNum(promoRec["Uses"])
+	if maxUses > 0 && uses >= maxUses {
+		return nil, &rejectedError{reason: fmt.Sprintf("promo %v is used up", code)}
+	}
+	maxUsesPerRider, _ := db.AttrToNum(promoRec["MaxUsesPerRider"])
+	if maxUsesPerRider > 0 && riderID != "" {
+		useRec, err := db.Get(tableName, promoUseKeys(code, riderID))
+		if err != nil {
+			fmt.Printf("promoDiscount: db.Get Failed. Err: %v\n", err)
+			return nil, err
+		}
+		if attrVal, ok := useRec["Uses"]; ok {
+			if riderUses, _ := db.AttrToNum(attrVal); riderUses >= maxUsesPerRider {
+				return nil, &rejectedError{reason: fmt.Sprintf("promo %v is used up by %v", code, riderID)}
+			}
+		}
+	}
+
+	discount := &fare.Discount{Code: code}
+	discount.Percent, _ = db.AttrToFloat(promoRec["Percent"])
+	discount.MaxOff, _ = db.AttrToNum64(promoRec["MaxOff"])
+	discount.Flat, _ = db.AttrToNum64(promoRec["Flat"])
+	return discount, nil
+}
+
+//promoAttrs are the attributes keeping the promo code of a booking.
+func promoAttrs(code string) map[string]*dynamodb.AttributeValue {
+	if code == "" {
+		return nil
+	}
+	return map[string]*dynamodb.AttributeValue{
+		"PromoCode": db.StrToAttr(strings.ToUpper(code)),
+	}
+}
+
+//attrsToPromoCode ...
+func attrsToPromoCode(rec map[string]*dynamodb.AttributeValue) string {
+	if attrVal, ok := rec["PromoCode"]; ok {
+		return db.AttrToStr(attrVal)
+	}
+	return ""
+}
+
+//contains ...
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+//loadPromo ...
+func loadPromo(code string) (map[string]*dynamodb.AttributeValue, error) {
+	code = strings.ToUpper(code)
+	promoRec, err := db.Get(tableName, promoKeys(code))
+	if err != nil {
+		fmt.Printf("loadPromo: db.Get Failed. Err: %v\n", err)
+		return nil, err
+	}
+	if len(promoRec) == 0 {
+		return nil, &notFoundError{kind: "promo", id: code}
+	}
+	return promoRec, nil
+}
+
+//promoKeys ...
+func promoKeys(code string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(hkeyValPromos),
+		db.RKeyName: db.StrToAttr(code),
+	}
+}
+
+//promoUseKeys ...
+func promoUseKeys(code, riderID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(hkeyValPromoUses + code + "/"),
+		db.RKeyName: db.StrToAttr(riderID),
+	}
+}
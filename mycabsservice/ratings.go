@@ -0,0 +1,217 @@
+package mycabsservice
+
+import (
+	"fmt"
+	"mycabs/db"
+	"mycabs/mycabsapi"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+//Riders rate the driver, and with it the cab, of a completed trip and drivers
+//rate the rider. Each side rates a trip once, the stars are kept on the trip.
+//Cabs, drivers and riders add the stars to StarsSum and StarsCount in the same
+//write, their average rating is worked out when read. Rating and RatingCount
+//hold the average and number of the ratings received before.
+//
+//A city can set thresholds on the cab rating: cabs under the deprioritize
+//threshold are offered a booking only after all the others, cabs under the
+//suspend threshold are not dispatched at all. Cabs with fewer than the minimum
+//number of ratings are never held back.
+
+const (
+	ratingOK            = "OK"
+	ratingDeprioritized = "DEPRIORITIZED"
+	ratingSuspended     = "SUSPENDED"
+)
+
+//ratingPolicy holds the rating thresholds of a city, 0 turns a threshold off.
+type ratingPolicy struct {
+	Deprioritize float64
+	Suspend      float64
+	MinRatings   int64
+}
+
+//RateDriver records the rating given by the rider to the driver and cab of the trip.
+func RateDriver(req *mycabsapi.RateDriverRequest) error {
+	tripRec, err := loadRatedTrip(req.TripID)
+	if err != nil {
+		return err
+	}
+	if tripRiderID(tripRec) != req.RiderID {
+		return &rejectedError{reason: fmt.Sprintf("%v is not the rider of %v", req.RiderID, req.TripID)}
+	}
+	rated := []map[string]*dynamodb.AttributeValue{cabKeys(db.AttrToStr(tripRec["CabID"]))}
+	if driverID := tripDriverID(tripRec); driverID != "" {
+		rated = append(rated, driverKeys(driverID))
+	}
+	return rateTrip(req.TripID, "RiderStars", "RiderComment", req.Stars, req.Comment, rated)
+}
+
+//RateRider records the rating given by the driver to the rider of the trip.
+func RateRider(req *mycabsapi.RateRiderRequest) error {
+	tripRec, err := loadRatedTrip(req.TripID)
+	if err != nil {
+		return err
+	}
+	if tripDriverID(tripRec) != req.DriverID {
+		return &rejectedError{reason: fmt.Sprintf("%v is not the driver of %v", req.DriverID, req.TripID)}
+	}
+	rated := []map[string]*dynamodb.AttributeValue{}
+	if riderID := tripRiderID(tripRec); riderID != "" {
+		rated = append(rated, riderKeys(riderID))
+	}
+	return rateTrip(req.TripID, "DriverStars", "DriverComment", req.Stars, req.Comment, rated)
+}
+
+//CabRating ...
+func CabRating(req *mycabsapi.CabRatingRequest) (*mycabsapi.CabRatingResponse, error) {
+	cabRec, err := loadCab(req.CabID)
+	if err != nil {
+		return nil, err
+	}
+	rating, count := attrsToRating(cabRec)
+	policy := cityRatingPolicy(db.AttrToStr(cabRec["CityID"]))
+	return &mycabsapi.CabRatingResponse{
+		CabID:   req.CabID,
+		Rating:  rating,
+		Ratings: count,
+		Status:  policy.status(rating, count),
+	}, nil
+}
+
+//SetCityRating ...
+func SetCityRating(req *mycabsapi.CityRatingRequest) error {
+	updateInfo := map[string]*dynamodb.AttributeValue{
+		"RatingDeprioritize": db.FloatToAttr(req.Deprioritize),
+		"RatingSuspend":      db.FloatToAttr(req.Suspend),
+		"RatingMinCount":     db.Num64ToAttr(req.MinRatings),
+	}
+	cond := map[string]*dynamodb.AttributeValue{
+		"Id": db.StrToAttr(req.CityID),
+	}
+	err := db.UpdateExclusive(tableName, cityKeys(req.CityID), updateInfo, cond)
+	if db.IsConditionFailed(err) {
+		return &notFoundError{kind: "city", id: req.CityID}
+	}
+	return err
+}
+
+//loadRatedTrip loads the trip, which can only be rated once completed.
+func loadRatedTrip(tripID string) (map[string]*dynamodb.AttributeValue, error) {
+	tripRec, err := loadTrip(tripID)
+	if err != nil {
+		return nil, err
+	}
+	if state := db.AttrToStr(tripRec["State"]); state != tripCompleted {
+		return nil, &rejectedError{reason: fmt.Sprintf("%v is %v, only completed trips can be rated", tripID, state)}
+	}
+	return tripRec, nil
+}
+
+//rateTrip stores the stars on the trip, once, and adds them to the ratings
+//of the records at rated, all in one write.
+func rateTrip(tripID, starsAttr, commentAttr string, stars int, comment string, rated []map[string]*dynamodb.AttributeValue) error {
+	updateInfo := map[string]*dynamodb.AttributeValue{
+		starsAttr: db.NumToAttr(stars),
+	}
+	if comment != "" {
+		updateInfo[commentAttr] = db.StrToAttr(comment)
+	}
+	writes := []*db.TxWrite{&db.TxWrite{Update: tripKeys(tripID), Updates: updateInfo, Absent: []string{starsAttr}}}
+	for _, keys := range rated {
+		writes = append(writes, &db.TxWrite{
+			Update: keys,
+			Adds: map[string]*dynamodb.AttributeValue{
+				"StarsSum":   db.NumToAttr(stars),
+				"StarsCount": db.NumToAttr(1),
+			},
+			//Not to bring back a record deleted meanwhile.
+			Cond: map[string]*dynamodb.AttributeValue{
+				"Id": keys[db.RKeyName],
+			},
+		})
+	}
+
+	err := db.Transact(tableName, writes)
+	if db.IsConditionFailed(err) {
+		if tripRec, err := loadTrip(tripID); err == nil {
+			if _, ok := tripRec[starsAttr]; ok {
+				return &rejectedError{reason: fmt.Sprintf("%v is already rated", tripID)}
+			}
+		}
+		return &conflictError{kind: "trip", id: tripID}
+	}
+	if err != nil {
+		fmt.Printf("rateTrip: db.Transact of %v Failed. Err: %v\n", tripID, err)
+	}
+	return err
+}
+
+//attrsToRating returns the average rating of the record and the number of
+//ratings it got, 0 if it was never rated.
+func attrsToRating(rec map[string]*dynamodb.AttributeValue) (rating float64, count int64) {
+	sum := 0.0
+	if attrVal, ok := rec["RatingCount"]; ok {
+		count, _ = db.AttrToNum64(attrVal)
+		rating, _ = db.AttrToFloat(rec["Rating"])
+		sum = rating * float64(count)
+	}
+	if attrVal, ok := rec["StarsCount"]; ok {
+		starsCount, _ := db.AttrToNum64(attrVal)
+		starsSum, _ := db.AttrToFloat(rec["StarsSum"])
+		count += starsCount
+		sum += starsSum
+	}
+	if count == 0 {
+		return 0, 0
+	}
+	return sum / float64(count), count
+}
+
+//cityRatingPolicy returns the rating thresholds of the city, none if it has not set them.
+func cityRatingPolicy(cityID string) *ratingPolicy {
+	policy := &ratingPolicy{}
+	cityRec, err := db.Get(tableName, cityKeys(cityID))
+	if err != nil {
+		fmt.Printf("cityRatingPolicy: db.Get Failed. Err: %v\n", err)
+	}
+	if attrVal, ok := cityRec["RatingMinCount"]; ok {
+		policy.MinRatings, _ = db.AttrToNum64(attrVal)
+		policy.Deprioritize, _ = db.AttrToFloat(cityRec["RatingDeprioritize"])
+		policy.Suspend, _ = db.AttrToFloat(cityRec["RatingSuspend"])
+	}
+	return policy
+}
+
+//status tells whether a cab with the rating is held back by the policy.
+func (policy *ratingPolicy) status(rating float64, count int64) string {
+	if count == 0 || count < policy.MinRatings {
+		return ratingOK
+	}
+	if rating < policy.Suspend {
+		return ratingSuspended
+	}
+	if rating < policy.Deprioritize {
+		return ratingDeprioritized
+	}
+	return ratingOK
+}
+
+//apply drops the suspended cabs from the ranked cabs and moves the
+//deprioritized ones to the end, keeping the order of the strategy otherwise.
+func (policy *ratingPolicy) apply(ranked []*CandidateCab) []*CandidateCab {
+	cabs := make([]*CandidateCab, 0, len(ranked))
+	var held []*CandidateCab
+	for _, cab := range ranked {
+		switch policy.status(cab.Rating, cab.RatingCount) {
+		case ratingSuspended:
+			continue
+		case ratingDeprioritized:
+			held = append(held, cab)
+		default:
+			cabs = append(cabs, cab)
+		}
+	}
+	return append(cabs, held...)
+}
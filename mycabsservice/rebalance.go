@@ -0,0 +1,374 @@
+package mycabsservice
+
+import (
+	"fmt"
+	"mycabs/db"
+	"mycabs/mycabsapi"
+	"mycabs/rebalance"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+//A rebalance compares the bookings of each cab type in each city over a recent
+//window with its idle cabs with a driver on shift, and moves spare cabs, IDLE
+//or IN_ACTIVE with no driver on shift, to the cities short of them. A driver
+//on shift is bound to the city, so a cab is never moved from under one.
+//
+//The plan is kept under hkeyValRebalances, and its moves under
+//hkeyValRebalanceMoves + planID. Once approved it is EXECUTING till every move
+//has its result, written along with the move of the cab, so a plan left
+//EXECUTING is taken up again where it stopped by approving it again. The moved
+//cabs end up IN_ACTIVE in their new city, to be activated once a driver takes
+//them there.
+
+const (
+	hkeyValRebalances     = "rebalances/"
+	hkeyValRebalanceMoves = "rebalancemoves/"
+	rebalanceProposed     = "PROPOSED"
+	rebalanceExecuting    = "EXECUTING"
+	rebalanceApproved     = "APPROVED" //Of earlier versions, left as EXECUTING is.
+	rebalanceExecuted     = "EXECUTED"
+	rebalanceMoved        = "moved"
+	rebalanceWindow       = 60  //Minutes
+	maxRebalanceWindow    = 360 //Minutes
+	maxRebalanceMoves     = 500
+	rebalancePlanTTL      = 30 * time.Minute
+)
+
+//RecommendRebalance works out the moves and keeps them for approval.
+func RecommendRebalance(req *mycabsapi.RecommendRebalanceRequest) (*mycabsapi.RebalancePlan, error) {
+	cells, err := rebalanceCells(req)
+	if err != nil {
+		return nil, err
+	}
+	params := rebalance.Params{Ratio: req.Ratio, MaxMoves: req.MaxMoves}
+	moves, impacts := params.Plan(cells)
+
+	plan := &mycabsapi.RebalancePlan{
+		State:     rebalanceProposed,
+		CreatedAt: time.Now().Format(time.RFC3339),
+		Moves:     []*mycabsapi.RebalanceMove{},
+		Impact:    []*mycabsapi.RebalanceImpact{},
+	}
+	for _, move := range moves {
+		plan.Moves = append(plan.Moves, &mycabsapi.RebalanceMove{
+			CabID:   move.CabID,
+			CabType: move.CabType,
+			From:    move.From,
+			To:      move.To,
+		})
+	}
+	for _, impact := range impacts {
+		if impact.Demand == 0 && impact.In == 0 && impact.Out == 0 {
+			continue
+		}
+		plan.Impact = append(plan.Impact, &mycabsapi.RebalanceImpact{
+			CityID:        impact.CityID,
+			CabType:       impact.CabType,
+			Demand:        impact.Demand,
+			Supply:        impact.Supply,
+			Wanted:        impact.Wanted,
+			Shortage:      impact.Shortage,
+			In:            impact.In,
+			Out:           impact.Out,
+			ShortageAfter: impact.ShortageAfter,
+		})
+	}
+	if len(plan.Moves) == 0 {
+		return plan, nil
+	}
+
+	seq, err := db.Increment(tableName, counterKeys("rebalance"), "Counter", 1)
+	if err != nil {
+		fmt.Printf("RecommendRebalance: db.Increment Failed. Err: %v\n", err)
+		return nil, err
+	}
+	plan.ID = "rebalance_" + strconv.Itoa(seq)
+
+	moveRecords := make([]map[string]*dynamodb.AttributeValue, 0, len(plan.Moves))
+	for idx, move := range plan.Moves {
+		moveRecord := rebalanceMoveKeys(plan.ID, idx)
+		moveRecord["CabID"] = db.StrToAttr(move.CabID)
+		moveRecord["CabType"] = db.StrToAttr(move.CabType)
+		moveRecord["From"] = db.StrToAttr(move.From)
+		moveRecord["To"] = db.StrToAttr(move.To)
+		moveRecords = append(moveRecords, moveRecord)
+	}
+	err = db.BatchPut(tableName, moveRecords)
+	if err != nil {
+		fmt.Printf("RecommendRebalance: db.BatchPut Failed. Err: %v\n", err)
+		return nil, err
+	}
+
+	//Stored last, a plan without all its moves is never seen.
+	planRecord := rebalanceKeys(plan.ID)
+	planRecord["Id"] = db.StrToAttr(plan.ID)
+	planRecord["State"] = db.StrToAttr(plan.State)
+	planRecord["CreatedAt"] = db.Num64ToAttr(time.Now().Unix())
+	planRecord["Moves"] = db.NumToAttr(len(plan.Moves))
+	err = db.Put(tableName, planRecord)
+	if err != nil {
+		fmt.Printf("RecommendRebalance: db.Put Failed. Err: %v\n", err)
+		return nil, err
+	}
+	return plan, nil
+}
+
+//RebalancePlan ...
+func RebalancePlan(req *mycabsapi.RebalancePlanRequest) (*mycabsapi.RebalancePlan, error) {
+	planRec, err := loadRebalancePlan(req.PlanID)
+	if err != nil {
+		return nil, err
+	}
+	return toRebalancePlan(planRec)
+}
+
+//ApproveRebalance carries out the moves of the plan, or the ones left of a plan
+//still EXECUTING. A move which can't be done any more, as the cab was booked or
+//moved meanwhile, is skipped.
+func ApproveRebalance(req *mycabsapi.RebalancePlanRequest) (*mycabsapi.RebalancePlan, error) {
+	planRec, err := loadRebalancePlan(req.PlanID)
+	if err != nil {
+		return nil, err
+	}
+	switch state := db.AttrToStr(planRec["State"]); state {
+	case rebalanceProposed:
+		createdAt, _ := db.AttrToNum64(planRec["CreatedAt"])
+		if time.Since(time.Unix(createdAt, 0)) > rebalancePlanTTL {
+			return nil, &rejectedError{reason: fmt.Sprintf("%v is older than %v, recommend a new one", req.PlanID, rebalancePlanTTL)}
+		}
+		updateInfo := map[string]*dynamodb.AttributeValue{
+			"State":      db.StrToAttr(rebalanceExecuting),
+			"ApprovedAt": db.Num64ToAttr(time.Now().Unix()),
+		}
+		cond := map[string]*dynamodb.AttributeValue{
+			"State": db.StrToAttr(rebalanceProposed),
+		}
+		err = db.UpdateExclusive(tableName, rebalanceKeys(req.PlanID), updateInfo, cond)
+		if db.IsConditionFailed(err) {
+			return nil, &conflictError{kind: "rebalance", id: req.PlanID}
+		}
+		if err != nil {
+			fmt.Printf("ApproveRebalance: db.UpdateExclusive Failed. Err: %v\n", err)
+			return nil, err
+		}
+	case rebalanceExecuting, rebalanceApproved:
+	default:
+		return nil, &invalidTransitionError{from: state, to: rebalanceExecuting}
+	}
+
+	plan, err := toRebalancePlan(planRec)
+	if err != nil {
+		return nil, err
+	}
+	for idx, move := range plan.Moves {
+		if move.Result != "" {
+			continue
+		}
+		err = moveCab(plan.ID, idx, move)
+		if err == nil {
+			continue
+		}
+		if errorStatus(err) == http.StatusInternalServerError {
+			//The plan stays EXECUTING, to be taken up again.
+			return nil, err
+		}
+		//Kept unless the move was done meanwhile, by another approval.
+		updateInfo := map[string]*dynamodb.AttributeValue{
+			"Result": db.StrToAttr(err.Error()),
+		}
+		err = db.UpdateIfEmpty(tableName, rebalanceMoveKeys(plan.ID, idx), updateInfo, "Result")
+		if err != nil && !db.IsConditionFailed(err) {
+			fmt.Printf("ApproveRebalance: db.UpdateIfEmpty of move %v Failed. Err: %v\n", idx, err)
+			return nil, err
+		}
+	}
+
+	updateInfo := map[string]*dynamodb.AttributeValue{
+		"State":      db.StrToAttr(rebalanceExecuted),
+		"ExecutedAt": db.Num64ToAttr(time.Now().Unix()),
+	}
+	executing := db.AttrToStr(planRec["State"])
+	if executing == rebalanceProposed {
+		executing = rebalanceExecuting
+	}
+	cond := map[string]*dynamodb.AttributeValue{
+		"State": db.StrToAttr(executing),
+	}
+	err = db.UpdateExclusive(tableName, rebalanceKeys(plan.ID), updateInfo, cond)
+	if err != nil && !db.IsConditionFailed(err) {
+		fmt.Printf("ApproveRebalance: db.UpdateExclusive Failed. Err: %v\n", err)
+		return nil, err
+	}
+	planRec, err = loadRebalancePlan(plan.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toRebalancePlan(planRec)
+}
+
+//moveCab takes the cab out of service, if it is IDLE, and into its new city,
+//in a single transition which records the move done. It fails if a driver
+//took the cab meanwhile, or it left the city it is moved from.
+func moveCab(planID string, idx int, move *mycabsapi.RebalanceMove) error {
+	err := checkCity(move.To, true)
+	if err != nil {
+		return err
+	}
+	cabRec, err := loadCab(move.CabID)
+	if err != nil {
+		return err
+	}
+	if cityID := db.AttrToStr(cabRec["CityID"]); cityID != move.From {
+		return &rejectedError{reason: fmt.Sprintf("%v is in %v now", move.CabID, cityID)}
+	}
+	if attrVal, ok := cabRec["DriverID"]; ok && db.AttrToStr(attrVal) != "" {
+		return &busyError{kind: "cab", id: move.CabID, with: "driver " + db.AttrToStr(attrVal) + " on shift"}
+	}
+	if state := db.AttrToStr(cabRec["State"]); state != stateIdle && state != stateInActive {
+		return &invalidTransitionError{from: state, to: stateInActive}
+	}
+
+	return transitionCab(cabRec, &cabTransition{
+		To:      stateInActive,
+		History: fmt.Sprintf("State: %v | Rebalanced From: %v to %v | Plan: %v | Time: %v", stateInActive, move.From, move.To, planID, time.Now()),
+		Updates: map[string]*dynamodb.AttributeValue{
+			"CityID": db.StrToAttr(move.To),
+		},
+		Writes: []*db.TxWrite{{
+			Update:  rebalanceMoveKeys(planID, idx),
+			Updates: map[string]*dynamodb.AttributeValue{"Result": db.StrToAttr(rebalanceMoved)},
+			Absent:  []string{"Result"},
+		}},
+		Free:   []string{"DriverID"},
+		Expect: map[string]*dynamodb.AttributeValue{"CityID": db.StrToAttr(move.From)},
+	})
+}
+
+//rebalanceCells reads the demand, supply and spare cabs of every cab type in
+//every city. Inactive cities only give their cabs away.
+func rebalanceCells(req *mycabsapi.RecommendRebalanceRequest) ([]*rebalance.Cell, error) {
+	cabTypes := []string{req.CabType}
+	if req.CabType == "" {
+		catalog, err := loadCabTypes()
+		if err != nil {
+			return nil, err
+		}
+		cabTypes = cabTypes[:0]
+		for _, cabType := range catalog {
+			cabTypes = append(cabTypes, cabType.Name)
+		}
+	}
+	cities, err := ListCities(&mycabsapi.ListCitiesRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	cells := map[string]*rebalance.Cell{}
+	cellList := []*rebalance.Cell{}
+	window := time.Duration(req.WindowMinutes) * time.Minute
+	for _, city := range cities.Cities {
+		for _, cabType := range cabTypes {
+			cell := &rebalance.Cell{CityID: city.ID, CabType: cabType}
+			if city.Active {
+				cell.Demand, err = demandIn(city.ID, cabType, window)
+				if err != nil {
+					return nil, err
+				}
+			}
+			cells[city.ID+"/"+cabType] = cell
+			cellList = append(cellList, cell)
+		}
+	}
+
+	filter := map[string]*dynamodb.Condition{
+		"State": &dynamodb.Condition{
+			ComparisonOperator: aws.String("IN"),
+			AttributeValueList: []*dynamodb.AttributeValue{db.StrToAttr(stateIdle), db.StrToAttr(stateInActive)},
+		},
+	}
+	page := &db.Page{Attrs: []string{"CityID", "Type", "State", "DriverID"}}
+	cabRecords, _, err := db.QueryPage(tableName, hkeyValCabs, filter, page)
+	if err != nil {
+		fmt.Printf("rebalanceCells: db.QueryPage Failed. Err: %v\n", err)
+		return nil, err
+	}
+	for _, cabRec := range cabRecords {
+		cell, ok := cells[db.AttrToStr(cabRec["CityID"])+"/"+db.AttrToStr(cabRec["Type"])]
+		if !ok {
+			continue
+		}
+		onShift := false
+		if attrVal, ok := cabRec["DriverID"]; ok {
+			onShift = db.AttrToStr(attrVal) != ""
+		}
+		switch {
+		case onShift && db.AttrToStr(cabRec["State"]) == stateIdle:
+			cell.Supply++
+		case !onShift:
+			cell.Spare = append(cell.Spare, db.AttrToStr(cabRec[db.RKeyName]))
+		}
+	}
+	return cellList, nil
+}
+
+//loadRebalancePlan ...
+func loadRebalancePlan(planID string) (map[string]*dynamodb.AttributeValue, error) {
+	planRec, err := db.Get(tableName, rebalanceKeys(planID))
+	if err != nil {
+		fmt.Printf("loadRebalancePlan: db.Get Failed. Err: %v\n", err)
+		return nil, err
+	}
+	if len(planRec) == 0 {
+		return nil, &notFoundError{kind: "rebalance", id: planID}
+	}
+	return planRec, nil
+}
+
+//toRebalancePlan returns the plan with its moves.
+func toRebalancePlan(planRec map[string]*dynamodb.AttributeValue) (*mycabsapi.RebalancePlan, error) {
+	plan := &mycabsapi.RebalancePlan{
+		ID:        db.AttrToStr(planRec["Id"]),
+		State:     db.AttrToStr(planRec["State"]),
+		CreatedAt: attrToTime(planRec["CreatedAt"]),
+		Moves:     []*mycabsapi.RebalanceMove{},
+	}
+	moveRecords, err := db.Query(tableName, hkeyValRebalanceMoves+plan.ID+"/", nil)
+	if err != nil {
+		fmt.Printf("toRebalancePlan: db.Query Failed. Err: %v\n", err)
+		return nil, err
+	}
+	for _, moveRec := range moveRecords {
+		move := &mycabsapi.RebalanceMove{
+			CabID:   db.AttrToStr(moveRec["CabID"]),
+			CabType: db.AttrToStr(moveRec["CabType"]),
+			From:    db.AttrToStr(moveRec["From"]),
+			To:      db.AttrToStr(moveRec["To"]),
+		}
+		if attrVal, ok := moveRec["Result"]; ok {
+			move.Result = db.AttrToStr(attrVal)
+		}
+		plan.Moves = append(plan.Moves, move)
+	}
+	return plan, nil
+}
+
+//rebalanceKeys ...
+func rebalanceKeys(planID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(hkeyValRebalances),
+		db.RKeyName: db.StrToAttr(planID),
+	}
+}
+
+//rebalanceMoveKeys ...
+func rebalanceMoveKeys(planID string, idx int) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(hkeyValRebalanceMoves + planID + "/"),
+		db.RKeyName: db.StrToAttr(fmt.Sprintf("%04d", idx)),
+	}
+}
@@ -0,0 +1,154 @@
+package mycabsservice
+
+import (
+	"fmt"
+	"mycabs/db"
+	"mycabs/fare"
+	"mycabs/mycabsapi"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+//Receipts are not stored, they are built from the trip record whenever asked
+//for, so that refunds made later show up on them.
+
+const invoiceMonthLayout = "2006-01"
+
+//Receipt ...
+func Receipt(req *mycabsapi.ReceiptRequest) (*mycabsapi.Receipt, error) {
+	tripRec, err := loadTrip(req.TripID)
+	if err != nil {
+		return nil, err
+	}
+	if state := db.AttrToStr(tripRec["State"]); state != tripCompleted {
+		return nil, &rejectedError{reason: fmt.Sprintf("%v is %v, only completed trips have a receipt", req.TripID, state)}
+	}
+	rcpt := newReceiptNames().receipt(tripRec)
+	if rcpt.RiderID != "" {
+		if riderRec, err := loadRider(rcpt.RiderID); err == nil {
+			rcpt.RiderName = db.AttrToStr(riderRec["Name"])
+		}
+	}
+	return rcpt, nil
+}
+
+//Invoice gathers the receipts of the trips of the rider which ended in the month.
+func Invoice(req *mycabsapi.InvoiceRequest) (*mycabsapi.Invoice, error) {
+	riderRec, err := loadRider(req.RiderID)
+	if err != nil {
+		return nil, err
+	}
+	month, _ := time.Parse(invoiceMonthLayout, req.Month)
+	tripRecords, err := monthTrips(req.RiderID, month)
+	if err != nil {
+		return nil, err
+	}
+
+	inv := &mycabsapi.Invoice{
+		InvoiceNo: "INV-" + req.RiderID + "-" + month.Format("200601"),
+		RiderID:   req.RiderID,
+		RiderName: db.AttrToStr(riderRec["Name"]),
+		Month:     req.Month,
+		Receipts:  []*mycabsapi.Receipt{},
+		Totals:    []*mycabsapi.InvoiceTotal{},
+	}
+	names := newReceiptNames()
+	for _, tripRec := range tripRecords {
+		rcpt := names.receipt(tripRec)
+		rcpt.RiderName = inv.RiderName
+		inv.Receipts = append(inv.Receipts, rcpt)
+		inv.Totals = addToTotals(inv.Totals, rcpt)
+	}
+	return inv, nil
+}
+
+//monthTrips returns the completed trips billed to the rider or corporate
+//account which ended in the month, in the order they ended.
+func monthTrips(payerID string, month time.Time) ([]map[string]*dynamodb.AttributeValue, error) {
+	return indexedTrips(hkeyValBilledTrips+payerID+"/", month.Unix(), month.AddDate(0, 1, 0).Unix()-1)
+}
+
+//addToTotals adds the receipt to the total of its currency.
+func addToTotals(totals []*mycabsapi.InvoiceTotal, rcpt *mycabsapi.Receipt) []*mycabsapi.InvoiceTotal {
+	if rcpt.Fare == nil {
+		return totals
+	}
+	var total *mycabsapi.InvoiceTotal
+	for _, t := range totals {
+		if t.Currency == rcpt.Fare.Currency {
+			total = t
+		}
+	}
+	if total == nil {
+		total = &mycabsapi.InvoiceTotal{Currency: rcpt.Fare.Currency}
+		totals = append(totals, total)
+	}
+	total.Trips++
+	total.Fare += rcpt.Fare.Total
+	total.Tax += rcpt.Tax
+	total.Refunded += rcpt.Refunded
+	total.Due = total.Fare - total.Refunded
+	return totals
+}
+
+//receiptNames looks up the names shown on receipts, once per city, cab or rider.
+type receiptNames struct {
+	cities map[string]string
+	cabs   map[string]string
+	riders map[string]string
+}
+
+func newReceiptNames() *receiptNames {
+	return &receiptNames{cities: map[string]string{}, cabs: map[string]string{}, riders: map[string]string{}}
+}
+
+//receipt builds the receipt of the completed trip.
+func (names *receiptNames) receipt(tripRec map[string]*dynamodb.AttributeValue) *mycabsapi.Receipt {
+	trip := toTrip(tripRec)
+	rcpt := &mycabsapi.Receipt{
+		ReceiptNo:  "RCPT-" + trip.ID,
+		TripID:     trip.ID,
+		RiderID:    trip.RiderID,
+		CabID:      trip.CabID,
+		CabName:    names.lookup(names.cabs, cabKeys(trip.CabID)),
+		CabType:    trip.CabType,
+		DriverID:   trip.DriverID,
+		From:       trip.From,
+		FromName:   names.lookup(names.cities, cityKeys(trip.From)),
+		To:         trip.To,
+		ToName:     names.lookup(names.cities, cityKeys(trip.To)),
+		BookedAt:   trip.BookedAt,
+		PickedUpAt: trip.PickedUpAt,
+		EndedAt:    trip.EndedAt,
+		Distance:   trip.Distance,
+		Duration:   trip.Duration,
+		Fare:       trip.Fare,
+	}
+	if attrVal, ok := tripRec["TaxRate"]; ok && rcpt.Fare != nil {
+		rcpt.TaxRate, _ = db.AttrToFloat(attrVal)
+		rcpt.TaxName = db.AttrToStr(tripRec["TaxName"])
+		rcpt.Tax = fare.IncludedTax(rcpt.Fare.Total, rcpt.TaxRate)
+	}
+	if attrVal, ok := tripRec["Refunded"]; ok {
+		rcpt.Refunded, _ = db.AttrToNum64(attrVal)
+	}
+	return rcpt
+}
+
+//lookup returns the Name of the record at keys, its ID if it has none.
+func (names *receiptNames) lookup(cache map[string]string, keys map[string]*dynamodb.AttributeValue) string {
+	id := db.AttrToStr(keys[db.RKeyName])
+	if name, ok := cache[id]; ok {
+		return name
+	}
+	name := id
+	rec, err := db.Get(tableName, keys)
+	if err != nil {
+		fmt.Printf("receiptNames: db.Get of %v Failed. Err: %v\n", id, err)
+	} else if attrVal, ok := rec["Name"]; ok {
+		name = db.AttrToStr(attrVal)
+	}
+	cache[id] = name
+	return name
+}
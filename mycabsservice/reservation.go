@@ -0,0 +1,492 @@
+package mycabsservice
+
+import (
+	"fmt"
+	"mycabs/db"
+	"mycabs/lease"
+	"mycabs/mycabsapi"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+//Reservations are bookings for a later pickup. The scheduler books a cab for
+//a reservation once its pickup time is within the lead time. Every scheduled
+//reservation has an entry in hkeyValReservationsDue keyed by its pickup time,
+//so that only the due ones are read. Every replica runs the scheduler, but a
+//round is only run by the replica which claimed it, and a reservation is only
+//dispatched by the replica holding its lease. Entries of reservations which
+//were cancelled, or moved to another pickup time, are dropped once due.
+
+const (
+	hkeyValReservations    = "reservations/"
+	hkeyValReservationsDue = "reservationsdue/"
+	hkeyValScheduler       = "scheduler/"
+)
+
+const (
+	reservationScheduled  = "SCHEDULED"
+	reservationDispatched = "DISPATCHED"
+	reservationFailed     = "FAILED"
+	reservationCancelled  = "CANCELLED"
+)
+
+const (
+	defaultReservationLead = 15 * time.Minute
+	reservationGrace       = 10 * time.Minute //Retries after the pickup time before giving up.
+	schedulerInterval      = 30 * time.Second
+)
+
+//ReserveCab ...
+func ReserveCab(req *mycabsapi.ReserveCabRequest) (reservationID string, err error) {
+	pickupTime, _ := time.Parse(time.RFC3339, req.PickupTime)
+	err = checkCabType(req.CabType)
+	if err != nil {
+		return "", err
+	}
+	err = checkTripCities(req.From, req.To)
+	if err != nil {
+		return "", err
+	}
+	_, err = checkService(req.From, req.Pickup, pickupTime)
+	if err != nil {
+		return "", err
+	}
+	_, err = checkPromo(req.PromoCode, req.RiderID, req.From, req.CabType, pickupTime)
+	if err != nil {
+		return "", err
+	}
+
+	seq, err := db.Increment(tableName, counterKeys("reservation"), "Counter", 1)
+	if err != nil {
+		fmt.Printf("ReserveCab: db.Increment Failed. Err: %v\n", err)
+		return "", err
+	}
+	reservationID = "reservation_" + strconv.Itoa(seq)
+
+	reservationRecord := make(map[string]*dynamodb.AttributeValue)
+	reservationRecord[db.HKeyName] = db.StrToAttr(hkeyValReservations)
+	reservationRecord[db.RKeyName] = db.StrToAttr(reservationID)
+	reservationRecord["Id"] = db.StrToAttr(reservationID)
+	reservationRecord["RiderID"] = db.StrToAttr(req.RiderID)
+	reservationRecord["From"] = db.StrToAttr(req.From)
+	reservationRecord["To"] = db.StrToAttr(req.To)
+	reservationRecord["CabType"] = db.StrToAttr(req.CabType)
+	reservationRecord["PickupTime"] = db.Num64ToAttr(pickupTime.Unix())
+	reservationRecord["State"] = db.StrToAttr(reservationScheduled)
+	reservationRecord["Version"] = db.Num64ToAttr(0)
+	reservationRecord["Lease"] = db.Num64ToAttr(0)
+	for attr, attrVal := range pickupAttrs(req.Pickup) {
+		reservationRecord[attr] = attrVal
+	}
+	for attr, attrVal := range promoAttrs(req.PromoCode) {
+		reservationRecord[attr] = attrVal
+	}
+
+	err = db.Transact(tableName, []*db.TxWrite{
+		{Put: reservationRecord, New: true},
+		{Put: reservationDueRecord(reservationID, pickupTime.Unix())},
+	})
+	if err != nil {
+		fmt.Printf("ReserveCab: db.Transact Failed. Err: %v\n", err)
+		return "", err
+	}
+	return reservationID, nil
+}
+
+//ListReservations lists the reservations of a rider, the upcoming ones unless
+//another state is asked for.
+func ListReservations(req *mycabsapi.ListReservationsRequest) (*mycabsapi.ListReservationsResponse, error) {
+	state := req.State
+	if state == "" {
+		state = reservationScheduled
+	}
+	filter := map[string]*dynamodb.Condition{
+		"RiderID": &dynamodb.Condition{
+			ComparisonOperator: aws.String("EQ"),
+			AttributeValueList: []*dynamodb.AttributeValue{db.StrToAttr(req.RiderID)},
+		},
+		"State": &dynamodb.Condition{
+			ComparisonOperator: aws.String("EQ"),
+			AttributeValueList: []*dynamodb.AttributeValue{db.StrToAttr(state)},
+		},
+	}
+	if req.From != "" {
+		filter["From"] = &dynamodb.Condition{
+			ComparisonOperator: aws.String("EQ"),
+			AttributeValueList: []*dynamodb.AttributeValue{db.StrToAttr(req.From)},
+		}
+	}
+
+	reservationRecords, _, err := db.QueryPage(tableName, hkeyValReservations, filter, &db.Page{})
+	if err != nil {
+		fmt.Printf("ListReservations: db.QueryPage Failed. Err: %v\n", err)
+		return nil, err
+	}
+
+	resp := &mycabsapi.ListReservationsResponse{
+		Reservations: []mycabsapi.Reservation{},
+	}
+	for _, reservationRec := range reservationRecords {
+		resp.Reservations = append(resp.Reservations, toReservation(reservationRec))
+	}
+	return resp, nil
+}
+
+//ModifyReservation changes a reservation which is not dispatched yet.
+func ModifyReservation(req *mycabsapi.ModifyReservationRequest) error {
+	reservationRec, err := loadReservation(req.ID)
+	if err != nil {
+		return err
+	}
+	if state := db.AttrToStr(reservationRec["State"]); state != reservationScheduled {
+		return &invalidTransitionError{from: state, to: reservationScheduled}
+	}
+
+	version, _ := db.AttrToNum64(reservationRec["Version"])
+	updateInfo := map[string]*dynamodb.AttributeValue{
+		"Version": db.Num64ToAttr(version + 1),
+	}
+	if req.To != "" {
+		err = checkCity(req.To, true)
+		if err != nil {
+			return err
+		}
+		updateInfo["To"] = db.StrToAttr(req.To)
+	}
+	if req.CabType != "" {
+		err = checkCabType(req.CabType)
+		if err != nil {
+			return err
+		}
+		updateInfo["CabType"] = db.StrToAttr(req.CabType)
+	}
+	//A dispatch running meanwhile sees the Version change and backs off.
+	cond := map[string]*dynamodb.AttributeValue{
+		"State":   db.StrToAttr(reservationScheduled),
+		"Version": db.Num64ToAttr(version),
+	}
+	writes := []*db.TxWrite{
+		{Update: reservationKeys(req.ID), Updates: updateInfo, Cond: cond},
+	}
+	if req.PickupTime != "" || req.Pickup != nil {
+		//The city must serve the pickup, where and when it is now.
+		pickupTime, _ := db.AttrToNum64(reservationRec["PickupTime"])
+		at := time.Unix(pickupTime, 0)
+		if req.PickupTime != "" {
+			at, _ = time.Parse(time.RFC3339, req.PickupTime)
+		}
+		pickup := attrsToPickup(reservationRec)
+		if req.Pickup != nil {
+			pickup = req.Pickup
+		}
+		_, err = checkService(db.AttrToStr(reservationRec["From"]), pickup, at)
+		if err != nil {
+			return err
+		}
+	}
+	if req.PickupTime != "" {
+		pickupTime, _ := time.Parse(time.RFC3339, req.PickupTime)
+		updateInfo["PickupTime"] = db.Num64ToAttr(pickupTime.Unix())
+		writes = append(writes, &db.TxWrite{Put: reservationDueRecord(req.ID, pickupTime.Unix())})
+	}
+	for attr, attrVal := range pickupAttrs(req.Pickup) {
+		updateInfo[attr] = attrVal
+	}
+
+	err = db.Transact(tableName, writes)
+	if db.IsConditionFailed(err) {
+		return &conflictError{kind: "reservation", id: req.ID}
+	}
+	return err
+}
+
+//CancelReservation ...
+func CancelReservation(req *mycabsapi.CancelReservationRequest) error {
+	updateInfo := map[string]*dynamodb.AttributeValue{
+		"State": db.StrToAttr(reservationCancelled),
+	}
+	cond := map[string]*dynamodb.AttributeValue{
+		"State": db.StrToAttr(reservationScheduled),
+	}
+
+	err := db.UpdateExclusive(tableName, reservationKeys(req.ID), updateInfo, cond)
+	if db.IsConditionFailed(err) {
+		reservationRec, err := loadReservation(req.ID)
+		if err != nil {
+			return err
+		}
+		return &invalidTransitionError{from: db.AttrToStr(reservationRec["State"]), to: reservationCancelled}
+	}
+	return err
+}
+
+//RunReservationScheduler dispatches due reservations till the process exits.
+func RunReservationScheduler() {
+	lead := reservationLead()
+	fmt.Printf("Reservation scheduler running, lead time: %v\n", lead)
+	indexReservations()
+	for {
+		if claimSchedulerRound(time.Now()) {
+			dispatchDueReservations(lead)
+		}
+		time.Sleep(schedulerInterval)
+	}
+}
+
+//claimSchedulerRound tells if this replica runs the round of now. The round is
+//claimed with a conditional write, only one of the replicas gets it.
+func claimSchedulerRound(now time.Time) bool {
+	round := now.Unix() / int64(schedulerInterval/time.Second)
+	schedulerKeys := map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(hkeyValScheduler),
+		db.RKeyName: db.StrToAttr("reservations"),
+	}
+	schedulerRec, err := db.Get(tableName, schedulerKeys)
+	if err != nil {
+		fmt.Printf("claimSchedulerRound: db.Get Failed. Err: %v\n", err)
+		return false
+	}
+	if len(schedulerRec) == 0 {
+		schedulerKeys["Round"] = db.Num64ToAttr(round)
+		return db.PutIfNew(tableName, schedulerKeys) == nil
+	}
+	if last, _ := db.AttrToNum64(schedulerRec["Round"]); last >= round {
+		return false
+	}
+	updateInfo := map[string]*dynamodb.AttributeValue{
+		"Round": db.Num64ToAttr(round),
+	}
+	cond := map[string]*dynamodb.AttributeValue{
+		"Round": schedulerRec["Round"],
+	}
+	return db.UpdateExclusive(tableName, schedulerKeys, updateInfo, cond) == nil
+}
+
+//dispatchDueReservations books cabs for the reservations with a pickup within lead.
+func dispatchDueReservations(lead time.Duration) {
+	dueRecords, err := db.QueryBetween(tableName, hkeyValReservationsDue,
+		reservationDueKey(0, ""), reservationDueKey(time.Now().Add(lead).Unix(), "~"))
+	if err != nil {
+		fmt.Printf("dispatchDueReservations: db.QueryBetween Failed. Err: %v\n", err)
+		return
+	}
+	for _, dueRec := range dueRecords {
+		pickupTime, _ := db.AttrToNum64(dueRec["PickupTime"])
+		dispatchReservation(db.AttrToStr(dueRec["ReservationID"]), pickupTime)
+	}
+}
+
+//indexReservations adds the due entries of the scheduled reservations made
+//before there were any, once.
+func indexReservations() {
+	indexedKeys := map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(hkeyValScheduler),
+		db.RKeyName: db.StrToAttr("reservationsindexed"),
+	}
+	indexedRec, err := db.Get(tableName, indexedKeys)
+	if err != nil || len(indexedRec) > 0 {
+		return
+	}
+
+	filter := map[string]*dynamodb.Condition{
+		"State": &dynamodb.Condition{
+			ComparisonOperator: aws.String("EQ"),
+			AttributeValueList: []*dynamodb.AttributeValue{db.StrToAttr(reservationScheduled)},
+		},
+	}
+	reservationRecords, _, err := db.QueryPage(tableName, hkeyValReservations, filter,
+		&db.Page{Attrs: []string{"Id", "PickupTime"}})
+	if err != nil {
+		fmt.Printf("indexReservations: db.QueryPage Failed. Err: %v\n", err)
+		return
+	}
+	for _, reservationRec := range reservationRecords {
+		pickupTime, _ := db.AttrToNum64(reservationRec["PickupTime"])
+		err = db.Put(tableName, reservationDueRecord(db.AttrToStr(reservationRec["Id"]), pickupTime))
+		if err != nil {
+			fmt.Printf("indexReservations: db.Put Failed. Err: %v\n", err)
+			return
+		}
+	}
+	err = db.PutIfNew(tableName, indexedKeys)
+	if err != nil && !db.IsConditionFailed(err) {
+		fmt.Printf("indexReservations: db.PutIfNew Failed. Err: %v\n", err)
+	}
+}
+
+//dispatchReservation books a cab for the reservation due at pickupTime, if no
+//other replica is doing it.
+func dispatchReservation(reservationID string, duePickupTime int64) {
+	ls, err := lease.Load(tableName, reservationKeys(reservationID))
+	if err != nil {
+		//Another replica is on it.
+		return
+	}
+	abort := make(chan int)
+	go ls.Renew(abort)
+	defer ls.Release()
+	defer close(abort)
+
+	//Read again under the lease, the reservation may have changed since the query.
+	reservationRec, err := loadReservation(reservationID)
+	if _, gone := err.(*notFoundError); gone {
+		dropReservationDue(reservationID, duePickupTime)
+		return
+	}
+	if err != nil {
+		return
+	}
+	pickupTime, _ := db.AttrToNum64(reservationRec["PickupTime"])
+	if db.AttrToStr(reservationRec["State"]) != reservationScheduled || pickupTime != duePickupTime {
+		//Done with, or due at another time.
+		dropReservationDue(reservationID, duePickupTime)
+		return
+	}
+
+	req := &mycabsapi.BookingRequest{
+		RiderID:   reservationRiderID(reservationRec),
+		From:      db.AttrToStr(reservationRec["From"]),
+		To:        db.AttrToStr(reservationRec["To"]),
+		CabType:   db.AttrToStr(reservationRec["CabType"]),
+		Pickup:    attrsToPickup(reservationRec),
+		PromoCode: attrsToPromoCode(reservationRec),
+	}
+	cab, err := bookCab(req)
+	if err != nil || cab == nil {
+		fmt.Printf("dispatchReservation: No cab for %v yet. Err: %v\n", reservationID, err)
+		if time.Now().Unix() > pickupTime+int64(reservationGrace/time.Second) {
+			updateInfo := map[string]*dynamodb.AttributeValue{
+				"State": db.StrToAttr(reservationFailed),
+			}
+			cond := map[string]*dynamodb.AttributeValue{
+				"State":   db.StrToAttr(reservationScheduled),
+				"Version": reservationRec["Version"],
+			}
+			err = db.UpdateExclusive(tableName, reservationKeys(reservationID), updateInfo, cond)
+			if err == nil {
+				dropReservationDue(reservationID, pickupTime)
+			}
+		}
+		return
+	}
+
+	updateInfo := map[string]*dynamodb.AttributeValue{
+		"State":   db.StrToAttr(reservationDispatched),
+		"CabID":   db.StrToAttr(cab.ID),
+		"CabName": db.StrToAttr(cab.Name),
+		"TripID":  db.StrToAttr(cab.TripID),
+	}
+	cond := map[string]*dynamodb.AttributeValue{
+		"State":   db.StrToAttr(reservationScheduled),
+		"Version": reservationRec["Version"],
+	}
+	err = db.UpdateExclusive(tableName, reservationKeys(reservationID), updateInfo, cond)
+	if err != nil {
+		//Cancelled or modified while the cab was booked, let the cab go.
+		fmt.Printf("dispatchReservation: %v changed during dispatch. Err: %v\n", reservationID, err)
+		cancelReq := &mycabsapi.CancelTripRequest{
+			CabID:  cab.ID,
+			Party:  partyRider,
+			Reason: "reservation " + reservationID + " changed",
+		}
+		err = CancelTrip(cancelReq)
+		if err != nil {
+			fmt.Printf("dispatchReservation: CancelTrip of %v Failed. Err: %v\n", cab.ID, err)
+		}
+		return
+	}
+	dropReservationDue(reservationID, pickupTime)
+	fmt.Printf("dispatchReservation: %v dispatched to %v\n", reservationID, cab.ID)
+}
+
+//dropReservationDue deletes the due entry, it is dropped again when next due
+//if this fails.
+func dropReservationDue(reservationID string, pickupTime int64) {
+	dueKeys := map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(hkeyValReservationsDue),
+		db.RKeyName: db.StrToAttr(reservationDueKey(pickupTime, reservationID)),
+	}
+	err := db.Delete(tableName, dueKeys, nil)
+	if err != nil {
+		fmt.Printf("dropReservationDue: db.Delete of %v Failed. Err: %v\n", reservationID, err)
+	}
+}
+
+//reservationDueRecord is the due entry of the reservation.
+func reservationDueRecord(reservationID string, pickupTime int64) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		db.HKeyName:     db.StrToAttr(hkeyValReservationsDue),
+		db.RKeyName:     db.StrToAttr(reservationDueKey(pickupTime, reservationID)),
+		"ReservationID": db.StrToAttr(reservationID),
+		"PickupTime":    db.Num64ToAttr(pickupTime),
+	}
+}
+
+//reservationDueKey orders the due entries by pickup time.
+func reservationDueKey(pickupTime int64, reservationID string) string {
+	return fmt.Sprintf("%012d/%v", pickupTime, reservationID)
+}
+
+//reservationLead is how long before the pickup a cab is booked for a reservation.
+func reservationLead() time.Duration {
+	minutes, err := strconv.Atoi(os.Getenv("MYCABS_RESERVATION_LEAD_MINUTES"))
+	if err != nil || minutes <= 0 {
+		return defaultReservationLead
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+//toReservation ...
+func toReservation(reservationRec map[string]*dynamodb.AttributeValue) mycabsapi.Reservation {
+	pickupTime, _ := db.AttrToNum64(reservationRec["PickupTime"])
+	reservation := mycabsapi.Reservation{
+		ID:         db.AttrToStr(reservationRec["Id"]),
+		RiderID:    reservationRiderID(reservationRec),
+		From:       db.AttrToStr(reservationRec["From"]),
+		To:         db.AttrToStr(reservationRec["To"]),
+		CabType:    db.AttrToStr(reservationRec["CabType"]),
+		Pickup:     attrsToPickup(reservationRec),
+		PickupTime: time.Unix(pickupTime, 0).Format(time.RFC3339),
+		State:      db.AttrToStr(reservationRec["State"]),
+		PromoCode:  attrsToPromoCode(reservationRec),
+	}
+	if attrVal, ok := reservationRec["CabID"]; ok {
+		reservation.CabID = db.AttrToStr(attrVal)
+		reservation.CabName = db.AttrToStr(reservationRec["CabName"])
+		reservation.TripID = db.AttrToStr(reservationRec["TripID"])
+	}
+	return reservation
+}
+
+//reservationRiderID is the rider of the reservation, empty for reservations from before riders.
+func reservationRiderID(reservationRec map[string]*dynamodb.AttributeValue) string {
+	if attrVal, ok := reservationRec["RiderID"]; ok {
+		return db.AttrToStr(attrVal)
+	}
+	return ""
+}
+
+//loadReservation ...
+func loadReservation(reservationID string) (map[string]*dynamodb.AttributeValue, error) {
+	reservationRec, err := db.Get(tableName, reservationKeys(reservationID))
+	if err != nil {
+		fmt.Printf("loadReservation: db.Get Failed. Err: %v\n", err)
+		return nil, err
+	}
+	if len(reservationRec) == 0 {
+		return nil, &notFoundError{kind: "reservation", id: reservationID}
+	}
+	return reservationRec, nil
+}
+
+//reservationKeys ...
+func reservationKeys(reservationID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(hkeyValReservations),
+		db.RKeyName: db.StrToAttr(reservationID),
+	}
+}
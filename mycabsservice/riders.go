@@ -0,0 +1,185 @@
+package mycabsservice
+
+import (
+	"fmt"
+	"mycabs/db"
+	"mycabs/mycabsapi"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+//Riders are kept under hkeyValRiders. ActiveTripID holds what the rider is
+//busy with: riderBooking while a booking looks for a cab, the ticket while it
+//waits on the waitlist and then the trip till it ends. It is only taken when
+//empty, so a rider holds one active trip at a time.
+
+const (
+	hkeyValRiders = "riders/"
+)
+
+const (
+	riderBooking = "booking"
+)
+
+var paymentMethods = map[string]bool{
+	"cash":   true,
+	"card":   true,
+	"wallet": true,
+}
+
+//RegisterRider ...
+func RegisterRider(req *mycabsapi.RegisterRiderRequest) (riderID string, err error) {
+	seq, err := db.Increment(tableName, counterKeys("rider"), "Counter", 1)
+	if err != nil {
+		fmt.Printf("RegisterRider: db.Increment Failed. Err: %v\n", err)
+		return "", err
+	}
+	riderID = "rider_" + strconv.Itoa(seq)
+
+	riderRecord := riderKeys(riderID)
+	riderRecord["Id"] = db.StrToAttr(riderID)
+	riderRecord["Name"] = db.StrToAttr(req.Name)
+	riderRecord["Phone"] = db.StrToAttr(req.Phone)
+	riderRecord["Email"] = db.StrToAttr(req.Email)
+	riderRecord["DefaultPayment"] = db.StrToAttr(req.DefaultPayment)
+	riderRecord["ActiveTripID"] = db.StrToAttr("")
+	riderRecord["CreatedAt"] = db.Num64ToAttr(time.Now().Unix())
+
+	err = db.Put(tableName, riderRecord)
+	if err != nil {
+		fmt.Printf("RegisterRider: db.Put Failed. Err: %v\n", err)
+		return "", err
+	}
+	return riderID, nil
+}
+
+//Rider ...
+func Rider(req *mycabsapi.RiderRequest) (*mycabsapi.Rider, error) {
+	riderRec, err := loadRider(req.RiderID)
+	if err != nil {
+		return nil, err
+	}
+	rider := &mycabsapi.Rider{
+		ID:             db.AttrToStr(riderRec["Id"]),
+		Name:           db.AttrToStr(riderRec["Name"]),
+		Phone:          db.AttrToStr(riderRec["Phone"]),
+		Email:          db.AttrToStr(riderRec["Email"]),
+		DefaultPayment: db.AttrToStr(riderRec["DefaultPayment"]),
+		ActiveTripID:   db.AttrToStr(riderRec["ActiveTripID"]),
+		CorporateID:    attrsToCorporateID(riderRec),
+	}
+	rider.Rating, rider.Ratings = attrsToRating(riderRec)
+	return rider, nil
+}
+
+//UpdateRider changes the profile fields given in the request.
+func UpdateRider(req *mycabsapi.UpdateRiderRequest) error {
+	updateInfo := map[string]*dynamodb.AttributeValue{}
+	if req.Name != "" {
+		updateInfo["Name"] = db.StrToAttr(req.Name)
+	}
+	if req.Phone != "" {
+		updateInfo["Phone"] = db.StrToAttr(req.Phone)
+	}
+	if req.Email != "" {
+		updateInfo["Email"] = db.StrToAttr(req.Email)
+	}
+	if req.DefaultPayment != "" {
+		updateInfo["DefaultPayment"] = db.StrToAttr(req.DefaultPayment)
+	}
+	cond := map[string]*dynamodb.AttributeValue{
+		"Id": db.StrToAttr(req.RiderID),
+	}
+
+	err := db.UpdateExclusive(tableName, riderKeys(req.RiderID), updateInfo, cond)
+	if db.IsConditionFailed(err) {
+		return &notFoundError{kind: "rider", id: req.RiderID}
+	}
+	return err
+}
+
+//RiderTrips lists the active or past trips of the rider, all of them if no state is asked.
+func RiderTrips(req *mycabsapi.RiderTripsRequest) (*mycabsapi.RiderTripsResponse, error) {
+	_, err := loadRider(req.RiderID)
+	if err != nil {
+		return nil, err
+	}
+
+	tripRecords, err := indexedTrips(hkeyValRiderTrips+req.RiderID+"/", 0, time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &mycabsapi.RiderTripsResponse{
+		Trips: []*mycabsapi.Trip{},
+	}
+	for _, tripRec := range tripRecords {
+		trip := toTrip(tripRec)
+		active := trip.State == tripBooked || trip.State == tripOnTrip
+		if (req.State == "active" && !active) || (req.State == "past" && active) {
+			continue
+		}
+		resp.Trips = append(resp.Trips, trip)
+	}
+	return resp, nil
+}
+
+//claimRider makes holder the active trip of the rider, if the rider has none.
+func claimRider(riderID, holder string) error {
+	err := handOverRider(riderID, "", holder)
+	if db.IsConditionFailed(err) {
+		riderRec, err := loadRider(riderID)
+		if err != nil {
+			return err
+		}
+		return &busyError{kind: "rider", id: riderID, with: db.AttrToStr(riderRec["ActiveTripID"])}
+	}
+	return err
+}
+
+//handOverRider moves the active trip of the rider from one holder to the next.
+func handOverRider(riderID, from, to string) error {
+	updateInfo := map[string]*dynamodb.AttributeValue{
+		"ActiveTripID": db.StrToAttr(to),
+	}
+	cond := map[string]*dynamodb.AttributeValue{
+		"ActiveTripID": db.StrToAttr(from),
+	}
+	err := db.UpdateExclusive(tableName, riderKeys(riderID), updateInfo, cond)
+	if err != nil && !db.IsConditionFailed(err) {
+		fmt.Printf("handOverRider: db.UpdateExclusive of %v Failed. Err: %v\n", riderID, err)
+	}
+	return err
+}
+
+//releaseRider frees the rider once holder is over.
+func releaseRider(riderID, holder string) {
+	if riderID == "" {
+		//Booked before riders were required.
+		return
+	}
+	handOverRider(riderID, holder, "")
+}
+
+//loadRider ...
+func loadRider(riderID string) (map[string]*dynamodb.AttributeValue, error) {
+	riderRec, err := db.Get(tableName, riderKeys(riderID))
+	if err != nil {
+		fmt.Printf("loadRider: db.Get Failed. Err: %v\n", err)
+		return nil, err
+	}
+	if len(riderRec) == 0 {
+		return nil, &notFoundError{kind: "rider", id: riderID}
+	}
+	return riderRec, nil
+}
+
+//riderKeys ...
+func riderKeys(riderID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(hkeyValRiders),
+		db.RKeyName: db.StrToAttr(riderID),
+	}
+}
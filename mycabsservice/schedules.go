@@ -0,0 +1,268 @@
+package mycabsservice
+
+import (
+	"fmt"
+	"mycabs/db"
+	"mycabs/geo"
+	"mycabs/mycabsapi"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+//The schedule of a city is kept under hkeyValSchedules, and its zones, all in
+//one record so they are replaced at once, under hkeyValZones, both keyed by the
+//city ID. A city with no schedule is in operation at all times, and one with no
+//zones everywhere. Both are checked when a cab is booked, or reserved for the
+//pickup time, a pickup being needed in a city with zones.
+
+const (
+	hkeyValSchedules = "schedules/"
+	hkeyValZones     = "zones/"
+	holidayFormat    = "2006-01-02"
+)
+
+//citySchedule ...
+type citySchedule struct {
+	mycabsapi.CitySchedule
+	Location *time.Location
+}
+
+//SetCitySchedule ...
+func SetCitySchedule(req *mycabsapi.SetCityScheduleRequest) error {
+	_, err := loadCity(req.CityID)
+	if err != nil {
+		return err
+	}
+	if req.Schedule == nil {
+		err = db.Delete(tableName, scheduleKeys(req.CityID), nil)
+		if err != nil {
+			fmt.Printf("SetCitySchedule: db.Delete Failed. Err: %v\n", err)
+		}
+		return err
+	}
+
+	scheduleRecord := scheduleKeys(req.CityID)
+	scheduleRecord["CityID"] = db.StrToAttr(req.CityID)
+	scheduleRecord["TimeZone"] = db.StrToAttr(req.Schedule.TimeZone)
+	scheduleRecord["FromHour"] = db.NumToAttr(req.Schedule.FromHour)
+	scheduleRecord["ToHour"] = db.NumToAttr(req.Schedule.ToHour)
+	if len(req.Schedule.Holidays) > 0 {
+		scheduleRecord["Holidays"] = db.StrSetToAttr(req.Schedule.Holidays)
+	}
+
+	err = db.Put(tableName, scheduleRecord)
+	if err != nil {
+		fmt.Printf("SetCitySchedule: db.Put Failed. Err: %v\n", err)
+	}
+	return err
+}
+
+//SetCityZones replaces the zones of the city.
+func SetCityZones(req *mycabsapi.SetCityZonesRequest) error {
+	_, err := loadCity(req.CityID)
+	if err != nil {
+		return err
+	}
+	if len(req.Zones) == 0 {
+		err = db.Delete(tableName, zonesKeys(req.CityID), nil)
+		if err != nil {
+			fmt.Printf("SetCityZones: db.Delete Failed. Err: %v\n", err)
+		}
+		return err
+	}
+
+	zonesRecord := zonesKeys(req.CityID)
+	zonesRecord["CityID"] = db.StrToAttr(req.CityID)
+	zonesRecord["Zones"] = db.StrSetToAttr(encodeZones(req.Zones))
+	err = db.Put(tableName, zonesRecord)
+	if err != nil {
+		fmt.Printf("SetCityZones: db.Put Failed. Err: %v\n", err)
+	}
+	return err
+}
+
+//ServiceAvailable tells if a cab can be booked in the city at the given time,
+//and pickup when there is one.
+func ServiceAvailable(req *mycabsapi.ServiceAvailableRequest) (*mycabsapi.ServiceAvailableResponse, error) {
+	at := time.Now()
+	if req.At != "" {
+		at, _ = time.Parse(time.RFC3339, req.At)
+	}
+
+	err := checkCity(req.CityID, true)
+	zone := ""
+	if err == nil {
+		zone, err = checkService(req.CityID, req.Pickup, at)
+	}
+	if rejected, ok := err.(*rejectedError); ok {
+		return &mycabsapi.ServiceAvailableResponse{Reason: rejected.reason}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &mycabsapi.ServiceAvailableResponse{Available: true, Zone: zone}, nil
+}
+
+//checkService makes sure the city is in operation at the given time, and that
+//the pickup is in one of its zones, if it has any. It returns the zone of the
+//pickup.
+func checkService(cityID string, pickup *mycabsapi.Location, at time.Time) (string, error) {
+	schedule, err := loadSchedule(cityID)
+	if err != nil {
+		return "", err
+	}
+	if schedule != nil {
+		local := at.In(schedule.Location)
+		if day := local.Format(holidayFormat); contains(schedule.Holidays, day) {
+			return "", &rejectedError{reason: fmt.Sprintf("city %v has no service on %v, a holiday", cityID, day)}
+		}
+		if !inHours(local.Hour(), schedule.FromHour, schedule.ToHour) {
+			return "", &rejectedError{reason: fmt.Sprintf("city %v is in operation from %d:00 to %d:00 only", cityID, schedule.FromHour, schedule.ToHour)}
+		}
+	}
+
+	zones, err := loadZones(cityID)
+	if err != nil || len(zones) == 0 {
+		return "", err
+	}
+	if pickup == nil {
+		return "", &rejectedError{reason: fmt.Sprintf("city %v is served in zones only, a pickup is needed", cityID)}
+	}
+	for _, zone := range zones {
+		if inZone(pickup, zone) {
+			return zone.Name, nil
+		}
+	}
+	return "", &rejectedError{reason: fmt.Sprintf("the pickup is outside the service zones of city %v", cityID)}
+}
+
+//inHours tells if the hour is from fromHour till toHour, which can go past
+//midnight, ex: 22 to 6. Every hour is when both are the same.
+func inHours(hour, fromHour, toHour int) bool {
+	if fromHour == toHour {
+		return true
+	}
+	if fromHour > toHour {
+		return hour >= fromHour || hour < toHour
+	}
+	return hour >= fromHour && hour < toHour
+}
+
+//inZone ...
+func inZone(loc *mycabsapi.Location, zone *mycabsapi.Zone) bool {
+	polygon := make([]geo.Point, 0, len(zone.Polygon))
+	for _, vertex := range zone.Polygon {
+		polygon = append(polygon, geo.Point{Lat: vertex.Lat, Lon: vertex.Lon})
+	}
+	return geo.InPolygon(loc.Lat, loc.Lon, polygon)
+}
+
+//loadSchedule returns the schedule of the city, nil if it has none.
+func loadSchedule(cityID string) (*citySchedule, error) {
+	scheduleRec, err := db.Get(tableName, scheduleKeys(cityID))
+	if err != nil {
+		fmt.Printf("loadSchedule: db.Get Failed. Err: %v\n", err)
+		return nil, err
+	}
+	if len(scheduleRec) == 0 {
+		return nil, nil
+	}
+
+	schedule := &citySchedule{Location: time.UTC}
+	schedule.TimeZone = db.AttrToStr(scheduleRec["TimeZone"])
+	schedule.FromHour, _ = db.AttrToNum(scheduleRec["FromHour"])
+	schedule.ToHour, _ = db.AttrToNum(scheduleRec["ToHour"])
+	if attrVal, ok := scheduleRec["Holidays"]; ok {
+		schedule.Holidays = db.AttrToStrSet(attrVal)
+	}
+	if loc, err := time.LoadLocation(schedule.TimeZone); err == nil {
+		schedule.Location = loc
+	}
+	return schedule, nil
+}
+
+//loadZones returns the zones of the city by name.
+func loadZones(cityID string) ([]*mycabsapi.Zone, error) {
+	zonesRec, err := db.Get(tableName, zonesKeys(cityID))
+	if err != nil {
+		fmt.Printf("loadZones: db.Get Failed. Err: %v\n", err)
+		return nil, err
+	}
+	if len(zonesRec) == 0 {
+		return []*mycabsapi.Zone{}, nil
+	}
+	return decodeZones(db.AttrToStrSet(zonesRec["Zones"])), nil
+}
+
+//encodeZones keeps every zone as "polygon|name", the polygon having no '|'.
+func encodeZones(zones []*mycabsapi.Zone) []string {
+	encoded := make([]string, 0, len(zones))
+	for _, zone := range zones {
+		encoded = append(encoded, encodePolygon(zone.Polygon)+"|"+zone.Name)
+	}
+	return encoded
+}
+
+//decodeZones ...
+func decodeZones(encoded []string) []*mycabsapi.Zone {
+	zones := make([]*mycabsapi.Zone, 0, len(encoded))
+	for _, polygonName := range encoded {
+		sep := strings.Index(polygonName, "|")
+		if sep < 0 {
+			continue
+		}
+		zones = append(zones, &mycabsapi.Zone{
+			Name:    polygonName[sep+1:],
+			Polygon: decodePolygon(polygonName[:sep]),
+		})
+	}
+	sort.Slice(zones, func(i, j int) bool {
+		return zones[i].Name < zones[j].Name
+	})
+	return zones
+}
+
+//encodePolygon keeps the vertices in order as "lat,lon;lat,lon;...".
+func encodePolygon(polygon []*mycabsapi.Location) string {
+	vertices := make([]string, 0, len(polygon))
+	for _, vertex := range polygon {
+		vertices = append(vertices, strconv.FormatFloat(vertex.Lat, 'f', -1, 64)+","+
+			strconv.FormatFloat(vertex.Lon, 'f', -1, 64))
+	}
+	return strings.Join(vertices, ";")
+}
+
+//decodePolygon ...
+func decodePolygon(encoded string) []*mycabsapi.Location {
+	polygon := []*mycabsapi.Location{}
+	for _, vertex := range strings.Split(encoded, ";") {
+		latLon := strings.Split(vertex, ",")
+		if len(latLon) != 2 {
+			continue
+		}
+		lat, _ := strconv.ParseFloat(latLon[0], 64)
+		lon, _ := strconv.ParseFloat(latLon[1], 64)
+		polygon = append(polygon, &mycabsapi.Location{Lat: lat, Lon: lon})
+	}
+	return polygon
+}
+
+//scheduleKeys ...
+func scheduleKeys(cityID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(hkeyValSchedules),
+		db.RKeyName: db.StrToAttr(cityID),
+	}
+}
+
+//zonesKeys ...
+func zonesKeys(cityID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(hkeyValZones),
+		db.RKeyName: db.StrToAttr(cityID),
+	}
+}
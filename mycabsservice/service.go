@@ -1,11 +1,17 @@
 package mycabsservice
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"mycabs/mycabsapi"
+	"mycabs/receipt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
 //OnboardCityHandler ...
@@ -41,147 +47,3640 @@ func OnboardCityHandler(w http.ResponseWriter, r *http.Request) {
 		if err != nil {
 			errMsg := fmt.Sprintf("OnboardCityHandler: OnboardCity Failed. Err: %v\n", err)
 			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		onboardResp := mycabsapi.OnboardCityResponse{ID: cityID}
+		resp, err := json.Marshal(onboardResp)
+		if err != nil {
+			errMsg := fmt.Sprintf("OnboardCityHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Citi Onboarded... ID: %v\n", cityID)
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("OnboardCityHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//CityDispatchHandler ...
+func CityDispatchHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("CityDispatchHandler: Received CityDispatch Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("CityDispatchHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.CityDispatchRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CityDispatchHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateCityDispatchReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CityDispatchHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = SetCityDispatch(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CityDispatchHandler: SetCityDispatch Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		fmt.Printf("City Dispatch Set.... CityID: %v Strategy: %v\n", req.CityID, req.Strategy)
+		writeResponse(w, []byte{})
+
+	default:
+		errMsg := fmt.Sprintf("CityDispatchHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//SetTariffHandler ...
+func SetTariffHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("SetTariffHandler: Received SetTariff Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("SetTariffHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.Tariff{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("SetTariffHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateSetTariffReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("SetTariffHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = SetTariff(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("SetTariffHandler: SetTariff Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		fmt.Printf("Tariff Set.... City: %v CabType: %v\n", req.CityID, req.CabType)
+		writeResponse(w, []byte{})
+
+	default:
+		errMsg := fmt.Sprintf("SetTariffHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//TariffHandler ...
+func TariffHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("TariffHandler: Received Tariff Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("TariffHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.TariffRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("TariffHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateTariffReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("TariffHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		tariff, err := Tariff(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("TariffHandler: Tariff Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		resp, err := json.Marshal(tariff)
+		if err != nil {
+			errMsg := fmt.Sprintf("TariffHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Tariff.... City: %v CabType: %v\n", req.CityID, req.CabType)
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("TariffHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//FareEstimateHandler ...
+func FareEstimateHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("FareEstimateHandler: Received FareEstimate Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("FareEstimateHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.FareEstimateRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("FareEstimateHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateFareEstimateReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("FareEstimateHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		estimate, err := FareEstimate(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("FareEstimateHandler: FareEstimate Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		resp, err := json.Marshal(estimate)
+		if err != nil {
+			errMsg := fmt.Sprintf("FareEstimateHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Fare Estimated.... From: %v To: %v\n", req.From, req.To)
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("FareEstimateHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//SurgeHandler ...
+func SurgeHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("SurgeHandler: Received Surge Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("SurgeHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.SurgeRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("SurgeHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateSurgeReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("SurgeHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		surgeResp, err := Surge(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("SurgeHandler: Surge Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		resp, err := json.Marshal(surgeResp)
+		if err != nil {
+			errMsg := fmt.Sprintf("SurgeHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Surge.... City: %v CabType: %v\n", req.CityID, req.CabType)
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("SurgeHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//CitySurgeHandler ...
+func CitySurgeHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("CitySurgeHandler: Received CitySurge Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("CitySurgeHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.CitySurgeRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CitySurgeHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateCitySurgeReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CitySurgeHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = SetCitySurge(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CitySurgeHandler: SetCitySurge Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		fmt.Printf("City Surge Set.... ID: %v\n", req.CityID)
+		writeResponse(w, []byte{})
+
+	default:
+		errMsg := fmt.Sprintf("CitySurgeHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//RegisterCabHandler ...
+func RegisterCabHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("RegisterCabHandler: Received RegisterCab Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("RegisterCabHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.RegisterCabRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RegisterCabHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateRegisterCabReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RegisterCabHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		cabID, err := RegisterCab(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RegisterCabHandler: RegisterCab Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		onboardResp := mycabsapi.OnboardCityResponse{ID: cabID}
+		resp, err := json.Marshal(onboardResp)
+		if err != nil {
+			errMsg := fmt.Sprintf("RegisterCabHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Cab Registered.... ID: %v\n", cabID)
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("RegisterCabHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//UpdateCabLocationHandler ...
+func UpdateCabLocationHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("UpdateCabLocationHandler: Received UpdateCabLocation Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("UpdateCabLocationHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.UpdateCabLocationRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("UpdateCabLocationHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateUpdateCabLocationReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("UpdateCabLocationHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = UpdateCabLocation(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("UpdateCabLocationHandler: UpdateCabLocation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		fmt.Printf("Cab Location Updated.... ID: %v\n", req.CabID)
+		writeResponse(w, []byte{})
+
+	default:
+		errMsg := fmt.Sprintf("UpdateCabLocationHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//RegisterRiderHandler ...
+func RegisterRiderHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("RegisterRiderHandler: Received RegisterRider Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("RegisterRiderHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.RegisterRiderRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RegisterRiderHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateRegisterRiderReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RegisterRiderHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		riderID, err := RegisterRider(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RegisterRiderHandler: RegisterRider Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		registerResp := mycabsapi.RegisterRiderResponse{ID: riderID}
+		resp, err := json.Marshal(registerResp)
+		if err != nil {
+			errMsg := fmt.Sprintf("RegisterRiderHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Rider Registered.... ID: %v\n", riderID)
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("RegisterRiderHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//RiderHandler ...
+func RiderHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("RiderHandler: Received Rider Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("RiderHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.RiderRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RiderHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateRiderReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RiderHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		rider, err := Rider(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RiderHandler: Rider Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		resp, err := json.Marshal(rider)
+		if err != nil {
+			errMsg := fmt.Sprintf("RiderHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Rider.... ID: %v\n", req.RiderID)
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("RiderHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//UpdateRiderHandler ...
+func UpdateRiderHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("UpdateRiderHandler: Received UpdateRider Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("UpdateRiderHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.UpdateRiderRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("UpdateRiderHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateUpdateRiderReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("UpdateRiderHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = UpdateRider(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("UpdateRiderHandler: UpdateRider Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		fmt.Printf("Rider Updated.... ID: %v\n", req.RiderID)
+		writeResponse(w, []byte{})
+
+	default:
+		errMsg := fmt.Sprintf("UpdateRiderHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//RiderTripsHandler ...
+func RiderTripsHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("RiderTripsHandler: Received RiderTrips Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("RiderTripsHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.RiderTripsRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RiderTripsHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateRiderTripsReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RiderTripsHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		tripsResp, err := RiderTrips(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RiderTripsHandler: RiderTrips Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		resp, err := json.Marshal(tripsResp)
+		if err != nil {
+			errMsg := fmt.Sprintf("RiderTripsHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Rider Trips.... ID: %v\n", req.RiderID)
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("RiderTripsHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//RegisterDriverHandler ...
+func RegisterDriverHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("RegisterDriverHandler: Received RegisterDriver Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("RegisterDriverHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.RegisterDriverRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RegisterDriverHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateRegisterDriverReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RegisterDriverHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		driverID, err := RegisterDriver(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RegisterDriverHandler: RegisterDriver Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		registerResp := mycabsapi.RegisterDriverResponse{ID: driverID}
+		resp, err := json.Marshal(registerResp)
+		if err != nil {
+			errMsg := fmt.Sprintf("RegisterDriverHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Driver Registered.... ID: %v\n", driverID)
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("RegisterDriverHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//DriverHandler ...
+func DriverHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("DriverHandler: Received Driver Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("DriverHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.DriverRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("DriverHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateDriverReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("DriverHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		driver, err := Driver(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("DriverHandler: Driver Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		resp, err := json.Marshal(driver)
+		if err != nil {
+			errMsg := fmt.Sprintf("DriverHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Driver.... ID: %v\n", req.DriverID)
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("DriverHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//ClockInHandler ...
+func ClockInHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("ClockInHandler: Received ClockIn Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("ClockInHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.ClockInRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ClockInHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateClockInReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ClockInHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = ClockIn(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ClockInHandler: ClockIn Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		fmt.Printf("Driver Clocked In.... ID: %v Cab: %v\n", req.DriverID, req.CabID)
+		writeResponse(w, []byte{})
+
+	default:
+		errMsg := fmt.Sprintf("ClockInHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//ClockOutHandler ...
+func ClockOutHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("ClockOutHandler: Received ClockOut Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("ClockOutHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.ClockOutRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ClockOutHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateClockOutReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ClockOutHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = ClockOut(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ClockOutHandler: ClockOut Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		fmt.Printf("Driver Clocked Out.... ID: %v\n", req.DriverID)
+		writeResponse(w, []byte{})
+
+	default:
+		errMsg := fmt.Sprintf("ClockOutHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//DriverReportHandler ...
+func DriverReportHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("DriverReportHandler: Received DriverReport Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("DriverReportHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.DriverReportRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("DriverReportHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateDriverReportReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("DriverReportHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		report, err := DriverReport(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("DriverReportHandler: DriverReport Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		resp, err := json.Marshal(report)
+		if err != nil {
+			errMsg := fmt.Sprintf("DriverReportHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Driver Report.... ID: %v\n", req.DriverID)
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("DriverReportHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//RateDriverHandler ...
+func RateDriverHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("RateDriverHandler: Received RateDriver Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("RateDriverHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.RateDriverRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RateDriverHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateRateDriverReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RateDriverHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = RateDriver(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RateDriverHandler: RateDriver Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		fmt.Printf("Driver Rated.... Trip: %v Stars: %v\n", req.TripID, req.Stars)
+		writeResponse(w, []byte{})
+
+	default:
+		errMsg := fmt.Sprintf("RateDriverHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//RateRiderHandler ...
+func RateRiderHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("RateRiderHandler: Received RateRider Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("RateRiderHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.RateRiderRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RateRiderHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateRateRiderReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RateRiderHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = RateRider(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RateRiderHandler: RateRider Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		fmt.Printf("Rider Rated.... Trip: %v Stars: %v\n", req.TripID, req.Stars)
+		writeResponse(w, []byte{})
+
+	default:
+		errMsg := fmt.Sprintf("RateRiderHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//CabRatingHandler ...
+func CabRatingHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("CabRatingHandler: Received CabRating Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("CabRatingHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.CabRatingRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CabRatingHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateCabRatingReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CabRatingHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		rating, err := CabRating(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CabRatingHandler: CabRating Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		resp, err := json.Marshal(rating)
+		if err != nil {
+			errMsg := fmt.Sprintf("CabRatingHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Cab Rating.... ID: %v\n", req.CabID)
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("CabRatingHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//CityRatingHandler ...
+func CityRatingHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("CityRatingHandler: Received CityRating Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("CityRatingHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.CityRatingRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CityRatingHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateCityRatingReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CityRatingHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = SetCityRating(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CityRatingHandler: SetCityRating Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		fmt.Printf("City Rating Thresholds Set.... ID: %v\n", req.CityID)
+		writeResponse(w, []byte{})
+
+	default:
+		errMsg := fmt.Sprintf("CityRatingHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//SettleTripHandler ...
+func SettleTripHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("SettleTripHandler: Received SettleTrip Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("SettleTripHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.SettleTripRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("SettleTripHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateSettleTripReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("SettleTripHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		settlement, err := SettleTrip(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("SettleTripHandler: SettleTrip Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		resp, err := json.Marshal(settlement)
+		if err != nil {
+			errMsg := fmt.Sprintf("SettleTripHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Trip Settled.... ID: %v\n", req.TripID)
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("SettleTripHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//RefundTripHandler ...
+func RefundTripHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("RefundTripHandler: Received RefundTrip Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("RefundTripHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.RefundTripRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RefundTripHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateRefundTripReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RefundTripHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		refund, err := RefundTrip(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RefundTripHandler: RefundTrip Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		resp, err := json.Marshal(refund)
+		if err != nil {
+			errMsg := fmt.Sprintf("RefundTripHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Trip Refunded.... ID: %v\n", req.TripID)
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("RefundTripHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//BalanceHandler ...
+func BalanceHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("BalanceHandler: Received Balance Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("BalanceHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.BalanceRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("BalanceHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateBalanceReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("BalanceHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		balance, err := Balance(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("BalanceHandler: Balance Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		resp, err := json.Marshal(balance)
+		if err != nil {
+			errMsg := fmt.Sprintf("BalanceHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Balance.... Account: %v\n", req.Account)
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("BalanceHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//ReceiptHandler ...
+func ReceiptHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("ReceiptHandler: Received Receipt Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("ReceiptHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.ReceiptRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ReceiptHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateReceiptReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ReceiptHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		rcpt, err := Receipt(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ReceiptHandler: Receipt Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		switch req.Format {
+		case "html":
+			page, err := receipt.HTML(rcpt)
+			if err != nil {
+				errMsg := fmt.Sprintf("ReceiptHandler: Page Building Failed. Err: %v\n", err)
+				fmt.Printf(errMsg)
+				writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+				return
+			}
+			writeDocument(w, "text/html; charset=utf-8", page)
+
+		case "pdf":
+			writeDocument(w, "application/pdf", receipt.PDF(rcpt))
+
+		default:
+			resp, err := json.Marshal(rcpt)
+			if err != nil {
+				errMsg := fmt.Sprintf("ReceiptHandler: Response Building Failed. Err: %v\n", err)
+				fmt.Printf(errMsg)
+				writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+				return
+			}
+			writeResponse(w, resp)
+		}
+		fmt.Printf("Receipt.... Trip: %v Format: %v\n", req.TripID, req.Format)
+
+	default:
+		errMsg := fmt.Sprintf("ReceiptHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//InvoiceHandler ...
+func InvoiceHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("InvoiceHandler: Received Invoice Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("InvoiceHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.InvoiceRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("InvoiceHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateInvoiceReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("InvoiceHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		inv, err := Invoice(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("InvoiceHandler: Invoice Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		switch req.Format {
+		case "html":
+			page, err := receipt.InvoiceHTML(inv)
+			if err != nil {
+				errMsg := fmt.Sprintf("InvoiceHandler: Page Building Failed. Err: %v\n", err)
+				fmt.Printf(errMsg)
+				writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+				return
+			}
+			writeDocument(w, "text/html; charset=utf-8", page)
+
+		case "pdf":
+			writeDocument(w, "application/pdf", receipt.InvoicePDF(inv))
+
+		default:
+			resp, err := json.Marshal(inv)
+			if err != nil {
+				errMsg := fmt.Sprintf("InvoiceHandler: Response Building Failed. Err: %v\n", err)
+				fmt.Printf(errMsg)
+				writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+				return
+			}
+			writeResponse(w, resp)
+		}
+		fmt.Printf("Invoice.... Rider: %v Month: %v\n", req.RiderID, req.Month)
+
+	default:
+		errMsg := fmt.Sprintf("InvoiceHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//CreatePromoHandler ...
+func CreatePromoHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("CreatePromoHandler: Received CreatePromo Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("CreatePromoHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.Promo{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CreatePromoHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateCreatePromoReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CreatePromoHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = CreatePromo(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CreatePromoHandler: CreatePromo Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		fmt.Printf("Promo Created... Code: %v\n", req.Code)
+		writeResponse(w, []byte{})
+
+	default:
+		errMsg := fmt.Sprintf("CreatePromoHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//PromoHandler ...
+func PromoHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("PromoHandler: Received Promo Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("PromoHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.PromoRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("PromoHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validatePromoReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("PromoHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		promo, err := Promo(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("PromoHandler: Promo Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		resp, err := json.Marshal(promo)
+		if err != nil {
+			errMsg := fmt.Sprintf("PromoHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Promo Fetched... Code: %v\n", req.Code)
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("PromoHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//EndPromoHandler ...
+func EndPromoHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("EndPromoHandler: Received EndPromo Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("EndPromoHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.PromoRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("EndPromoHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validatePromoReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("EndPromoHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = EndPromo(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("EndPromoHandler: EndPromo Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		fmt.Printf("Promo Ended... Code: %v\n", req.Code)
+		writeResponse(w, []byte{})
+
+	default:
+		errMsg := fmt.Sprintf("EndPromoHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//CreateCorporateHandler ...
+func CreateCorporateHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("CreateCorporateHandler: Received CreateCorporate Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("CreateCorporateHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.CreateCorporateRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CreateCorporateHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateCreateCorporateReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CreateCorporateHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		corporateID, err := CreateCorporate(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CreateCorporateHandler: CreateCorporate Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		createResp := mycabsapi.CreateCorporateResponse{ID: corporateID}
+		resp, err := json.Marshal(createResp)
+		if err != nil {
+			errMsg := fmt.Sprintf("CreateCorporateHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Corporate Created.... ID: %v\n", corporateID)
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("CreateCorporateHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//CorporateHandler ...
+func CorporateHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("CorporateHandler: Received Corporate Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("CorporateHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.CorporateRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CorporateHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateCorporateReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CorporateHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		corporate, err := Corporate(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CorporateHandler: Corporate Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		resp, err := json.Marshal(corporate)
+		if err != nil {
+			errMsg := fmt.Sprintf("CorporateHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Corporate Fetched... ID: %v\n", req.CorporateID)
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("CorporateHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//SetCorporatePolicyHandler ...
+func SetCorporatePolicyHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("SetCorporatePolicyHandler: Received SetCorporatePolicy Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("SetCorporatePolicyHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.SetCorporatePolicyRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("SetCorporatePolicyHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateSetCorporatePolicyReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("SetCorporatePolicyHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = SetCorporatePolicy(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("SetCorporatePolicyHandler: SetCorporatePolicy Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		fmt.Printf("Corporate Policy Set... ID: %v\n", req.CorporateID)
+		writeResponse(w, []byte{})
+
+	default:
+		errMsg := fmt.Sprintf("SetCorporatePolicyHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//AddCorporateMemberHandler ...
+func AddCorporateMemberHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("AddCorporateMemberHandler: Received AddCorporateMember Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("AddCorporateMemberHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.CorporateMemberRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("AddCorporateMemberHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateCorporateMemberReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("AddCorporateMemberHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = AddCorporateMember(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("AddCorporateMemberHandler: AddCorporateMember Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		fmt.Printf("Corporate Member Added... ID: %v Rider: %v\n", req.CorporateID, req.RiderID)
+		writeResponse(w, []byte{})
+
+	default:
+		errMsg := fmt.Sprintf("AddCorporateMemberHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//RemoveCorporateMemberHandler ...
+func RemoveCorporateMemberHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("RemoveCorporateMemberHandler: Received RemoveCorporateMember Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("RemoveCorporateMemberHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.CorporateMemberRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RemoveCorporateMemberHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateCorporateMemberReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RemoveCorporateMemberHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = RemoveCorporateMember(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RemoveCorporateMemberHandler: RemoveCorporateMember Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		fmt.Printf("Corporate Member Removed... ID: %v Rider: %v\n", req.CorporateID, req.RiderID)
+		writeResponse(w, []byte{})
+
+	default:
+		errMsg := fmt.Sprintf("RemoveCorporateMemberHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//CorporateStatementHandler ...
+func CorporateStatementHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("CorporateStatementHandler: Received CorporateStatement Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("CorporateStatementHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.StatementRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CorporateStatementHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateStatementReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CorporateStatementHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		stmt, err := CorporateStatement(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CorporateStatementHandler: CorporateStatement Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		switch req.Format {
+		case "html":
+			page, err := receipt.StatementHTML(stmt)
+			if err != nil {
+				errMsg := fmt.Sprintf("CorporateStatementHandler: Page Building Failed. Err: %v\n", err)
+				fmt.Printf(errMsg)
+				writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+				return
+			}
+			writeDocument(w, "text/html; charset=utf-8", page)
+
+		case "pdf":
+			writeDocument(w, "application/pdf", receipt.StatementPDF(stmt))
+
+		default:
+			resp, err := json.Marshal(stmt)
+			if err != nil {
+				errMsg := fmt.Sprintf("CorporateStatementHandler: Response Building Failed. Err: %v\n", err)
+				fmt.Printf(errMsg)
+				writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+				return
+			}
+			writeResponse(w, resp)
+		}
+		fmt.Printf("Statement.... Corporate: %v Month: %v\n", req.CorporateID, req.Month)
+
+	default:
+		errMsg := fmt.Sprintf("CorporateStatementHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//SetCabTypeHandler ...
+func SetCabTypeHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("SetCabTypeHandler: Received SetCabType Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("SetCabTypeHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.CabType{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("SetCabTypeHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateSetCabTypeReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("SetCabTypeHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = SetCabType(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("SetCabTypeHandler: SetCabType Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		fmt.Printf("Cab Type Set... Name: %v\n", req.Name)
+		writeResponse(w, []byte{})
+
+	default:
+		errMsg := fmt.Sprintf("SetCabTypeHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//CabTypeHandler ...
+func CabTypeHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("CabTypeHandler: Received CabType Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("CabTypeHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.CabTypeRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CabTypeHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateCabTypeReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CabTypeHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		cabType, err := CabType(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CabTypeHandler: CabType Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		resp, err := json.Marshal(cabType)
+		if err != nil {
+			errMsg := fmt.Sprintf("CabTypeHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Cab Type Fetched... Name: %v\n", req.Name)
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("CabTypeHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//ListCabTypesHandler ...
+func ListCabTypesHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("ListCabTypesHandler: Received ListCabTypes Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("ListCabTypesHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.ListCabTypesRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ListCabTypesHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateListCabTypesReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ListCabTypesHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		listResp, err := ListCabTypes(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ListCabTypesHandler: ListCabTypes Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		resp, err := json.Marshal(listResp)
+		if err != nil {
+			errMsg := fmt.Sprintf("ListCabTypesHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Cab Types Listed... %v\n", len(listResp.CabTypes))
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("ListCabTypesHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//DeleteCabTypeHandler ...
+func DeleteCabTypeHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("DeleteCabTypeHandler: Received DeleteCabType Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("DeleteCabTypeHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.CabTypeRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("DeleteCabTypeHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateCabTypeReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("DeleteCabTypeHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = DeleteCabType(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("DeleteCabTypeHandler: DeleteCabType Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		fmt.Printf("Cab Type Deleted... Name: %v\n", req.Name)
+		writeResponse(w, []byte{})
+
+	default:
+		errMsg := fmt.Sprintf("DeleteCabTypeHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//ListCitiesHandler ...
+func ListCitiesHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("ListCitiesHandler: Received ListCities Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("ListCitiesHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.ListCitiesRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ListCitiesHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateListCitiesReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ListCitiesHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		citiesResp, err := ListCities(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ListCitiesHandler: ListCities Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		resp, err := json.Marshal(citiesResp)
+		if err != nil {
+			errMsg := fmt.Sprintf("ListCitiesHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Cities Listed... Count: %v\n", len(citiesResp.Cities))
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("ListCitiesHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//CityHandler ...
+func CityHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("CityHandler: Received City Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("CityHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.CityRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CityHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateCityReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CityHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		city, err := City(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CityHandler: City Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		resp, err := json.Marshal(city)
+		if err != nil {
+			errMsg := fmt.Sprintf("CityHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("City Fetched... ID: %v\n", req.CityID)
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("CityHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//RenameCityHandler ...
+func RenameCityHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("RenameCityHandler: Received RenameCity Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("RenameCityHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.RenameCityRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RenameCityHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateRenameCityReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RenameCityHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = RenameCity(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RenameCityHandler: RenameCity Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		fmt.Printf("City Renamed... ID: %v\n", req.CityID)
+		writeResponse(w, []byte{})
+
+	default:
+		errMsg := fmt.Sprintf("RenameCityHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//DeactivateCityHandler ...
+func DeactivateCityHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("DeactivateCityHandler: Received DeactivateCity Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("DeactivateCityHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.CityRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("DeactivateCityHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateCityReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("DeactivateCityHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = DeactivateCity(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("DeactivateCityHandler: DeactivateCity Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		fmt.Printf("City Deactivated... ID: %v\n", req.CityID)
+		writeResponse(w, []byte{})
+
+	default:
+		errMsg := fmt.Sprintf("DeactivateCityHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//ActivateCityHandler ...
+func ActivateCityHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("ActivateCityHandler: Received ActivateCity Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("ActivateCityHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.CityRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ActivateCityHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateCityReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ActivateCityHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = ActivateCity(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ActivateCityHandler: ActivateCity Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		fmt.Printf("City Activated... ID: %v\n", req.CityID)
+		writeResponse(w, []byte{})
+
+	default:
+		errMsg := fmt.Sprintf("ActivateCityHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//SetCityScheduleHandler ...
+func SetCityScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("SetCityScheduleHandler: Received SetCitySchedule Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("SetCityScheduleHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.SetCityScheduleRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("SetCityScheduleHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateSetCityScheduleReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("SetCityScheduleHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = SetCitySchedule(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("SetCityScheduleHandler: SetCitySchedule Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		fmt.Printf("City Schedule Set... ID: %v\n", req.CityID)
+		writeResponse(w, []byte{})
+
+	default:
+		errMsg := fmt.Sprintf("SetCityScheduleHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//SetCityZonesHandler ...
+func SetCityZonesHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("SetCityZonesHandler: Received SetCityZones Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("SetCityZonesHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.SetCityZonesRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("SetCityZonesHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateSetCityZonesReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("SetCityZonesHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = SetCityZones(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("SetCityZonesHandler: SetCityZones Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		fmt.Printf("City Zones Set... ID: %v Zones: %v\n", req.CityID, len(req.Zones))
+		writeResponse(w, []byte{})
+
+	default:
+		errMsg := fmt.Sprintf("SetCityZonesHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//ServiceAvailableHandler ...
+func ServiceAvailableHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("ServiceAvailableHandler: Received ServiceAvailable Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("ServiceAvailableHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.ServiceAvailableRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ServiceAvailableHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateServiceAvailableReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ServiceAvailableHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		availableResp, err := ServiceAvailable(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ServiceAvailableHandler: ServiceAvailable Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		resp, err := json.Marshal(availableResp)
+		if err != nil {
+			errMsg := fmt.Sprintf("ServiceAvailableHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Service Availability Checked... City: %v Available: %v\n", req.CityID, availableResp.Available)
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("ServiceAvailableHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//SearchCabsHandler ...
+func SearchCabsHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("SearchCabsHandler: Received SearchCabs Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("SearchCabsHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.SearchCabsRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("SearchCabsHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateSearchCabsReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("SearchCabsHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		searchResp, err := SearchCabs(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("SearchCabsHandler: SearchCabs Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		resp, err := json.Marshal(searchResp)
+		if err != nil {
+			errMsg := fmt.Sprintf("SearchCabsHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Cabs Searched... Found: %v\n", len(searchResp.Cabs))
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("SearchCabsHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//UpdateCabHandler ...
+func UpdateCabHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("UpdateCabHandler: Received UpdateCab Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("UpdateCabHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.UpdateCabRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("UpdateCabHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateUpdateCabReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("UpdateCabHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = UpdateCab(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("UpdateCabHandler: UpdateCab Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		fmt.Printf("Cab Updated... ID: %v\n", req.CabID)
+		writeResponse(w, []byte{})
+
+	default:
+		errMsg := fmt.Sprintf("UpdateCabHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//DecommissionCabHandler ...
+func DecommissionCabHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("DecommissionCabHandler: Received DecommissionCab Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("DecommissionCabHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.DecommissionCabRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("DecommissionCabHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateDecommissionCabReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("DecommissionCabHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = DecommissionCab(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("DecommissionCabHandler: DecommissionCab Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		fmt.Printf("Cab Decommissioned... ID: %v\n", req.CabID)
+		writeResponse(w, []byte{})
+
+	default:
+		errMsg := fmt.Sprintf("DecommissionCabHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//ImportFleetHandler takes the file to import as the body, and the format
+//(csv or jsonl) and dryrun in the query, ex: /api/ImportFleet?format=csv&dryrun=true
+func ImportFleetHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("ImportFleetHandler: Received ImportFleet Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		format := strings.ToLower(r.URL.Query().Get("format"))
+		if format == "" {
+			format = importFormatCSV
+		}
+		dryRun := false
+		if param := r.URL.Query().Get("dryrun"); param != "" {
+			var err error
+			dryRun, err = strconv.ParseBool(param)
+			if err != nil {
+				errMsg := fmt.Sprintf("ImportFleetHandler: Request Validation Failed. Err: %v\n", err)
+				fmt.Printf(errMsg)
+				writeErrorResponse(w, http.StatusBadRequest, errMsg)
+				return
+			}
+		}
+
+		report, err := ImportFleet(r.Body, format, dryRun)
+		if err != nil {
+			errMsg := fmt.Sprintf("ImportFleetHandler: ImportFleet Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		resp, err := json.Marshal(report)
+		if err != nil {
+			errMsg := fmt.Sprintf("ImportFleetHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Fleet Imported... Imported: %v Failed: %v DryRun: %v\n", report.Imported, report.Failed, dryRun)
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("ImportFleetHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//RecommendRebalanceHandler ...
+func RecommendRebalanceHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("RecommendRebalanceHandler: Received RecommendRebalance Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("RecommendRebalanceHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.RecommendRebalanceRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RecommendRebalanceHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateRecommendRebalanceReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RecommendRebalanceHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		plan, err := RecommendRebalance(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RecommendRebalanceHandler: RecommendRebalance Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		resp, err := json.Marshal(plan)
+		if err != nil {
+			errMsg := fmt.Sprintf("RecommendRebalanceHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Rebalance Recommended... ID: %v Moves: %v\n", plan.ID, len(plan.Moves))
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("RecommendRebalanceHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//RebalancePlanHandler ...
+func RebalancePlanHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("RebalancePlanHandler: Received RebalancePlan Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("RebalancePlanHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.RebalancePlanRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RebalancePlanHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateRebalancePlanReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RebalancePlanHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		plan, err := RebalancePlan(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("RebalancePlanHandler: RebalancePlan Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		resp, err := json.Marshal(plan)
+		if err != nil {
+			errMsg := fmt.Sprintf("RebalancePlanHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Rebalance Plan Fetched... ID: %v\n", req.PlanID)
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("RebalancePlanHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//ApproveRebalanceHandler ...
+func ApproveRebalanceHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("ApproveRebalanceHandler: Received ApproveRebalance Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("ApproveRebalanceHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.RebalancePlanRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ApproveRebalanceHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateRebalancePlanReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ApproveRebalanceHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		plan, err := ApproveRebalance(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ApproveRebalanceHandler: ApproveRebalance Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		resp, err := json.Marshal(plan)
+		if err != nil {
+			errMsg := fmt.Sprintf("ApproveRebalanceHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Rebalance Approved... ID: %v Moves: %v\n", req.PlanID, len(plan.Moves))
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("ApproveRebalanceHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//DemandAnalyticsHandler ...
+func DemandAnalyticsHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("DemandAnalyticsHandler: Received DemandAnalytics Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("DemandAnalyticsHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.DemandAnalyticsRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("DemandAnalyticsHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateDemandAnalyticsReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("DemandAnalyticsHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		analyticsResp, err := DemandAnalytics(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("DemandAnalyticsHandler: DemandAnalytics Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		resp, err := json.Marshal(analyticsResp)
+		if err != nil {
+			errMsg := fmt.Sprintf("DemandAnalyticsHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Demand Analytics Done... From: %v To: %v Bookings: %v\n", req.From, req.To, analyticsResp.Bookings)
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("DemandAnalyticsHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//CabLocationsHandler ...
+func CabLocationsHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("CabLocationsHandler: Received CabLocations Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("CabLocationsHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.CabLocationsRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CabLocationsHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateCabLocationsReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CabLocationsHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		locationsResp := mycabsapi.CabLocationsResponse{
+			Results: IngestPings(req.Pings),
+		}
+
+		resp, err := json.Marshal(locationsResp)
+		if err != nil {
+			errMsg := fmt.Sprintf("CabLocationsHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Cab Locations Ingested.... Pings: %v\n", len(req.Pings))
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("CabLocationsHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//CabLocationStreamHandler takes one ping per line and streams back one result
+//per line, as the pings are processed.
+func CabLocationStreamHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("CabLocationStreamHandler: Received CabLocationStream Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		w.Header().Add("content-type", "application/x-ndjson")
+		w.Header().Add("charset", "utf-8")
+		flusher, _ := w.(http.Flusher)
+		encoder := json.NewEncoder(w)
+
+		count := 0
+		pings := make([]mycabsapi.Ping, 0, maxPingBatch)
+		flush := func() {
+			for _, result := range IngestPings(pings) {
+				encoder.Encode(result)
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+			count += len(pings)
+			pings = pings[:0]
+		}
+
+		//The lines are read aside, so that the pings waiting for a full batch
+		//are written every pingFlushInterval on a slow stream.
+		lines := make(chan []byte)
+		var readErr error
+		go func() {
+			defer close(lines)
+			scanner := bufio.NewScanner(r.Body)
+			for scanner.Scan() {
+				lines <- append([]byte{}, bytes.TrimSpace(scanner.Bytes())...)
+			}
+			readErr = scanner.Err()
+		}()
+		ticker := time.NewTicker(pingFlushInterval)
+		defer ticker.Stop()
+
+	stream:
+		for {
+			select {
+			case line, ok := <-lines:
+				if !ok {
+					break stream
+				}
+				if len(line) == 0 {
+					continue
+				}
+				ping := mycabsapi.Ping{}
+				err := json.Unmarshal(line, &ping)
+				if err != nil {
+					//Keep the results in the order of the lines.
+					flush()
+					encoder.Encode(mycabsapi.PingResult{Error: "malformed ping"})
+					continue
+				}
+				pings = append(pings, ping)
+				if len(pings) == maxPingBatch {
+					flush()
+				}
+			case <-ticker.C:
+				if len(pings) > 0 {
+					flush()
+				}
+			}
+		}
+		flush()
+		if readErr != nil {
+			fmt.Printf("CabLocationStreamHandler: Request Read Failed. Err: %v\n", readErr)
+		}
+
+		fmt.Printf("Cab Location Stream Ended.... Pings: %v\n", count)
+
+	default:
+		errMsg := fmt.Sprintf("CabLocationStreamHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//TripTrailHandler ...
+func TripTrailHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("TripTrailHandler: Received TripTrail Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("TripTrailHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.TripTrailRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("TripTrailHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateTripTrailReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("TripTrailHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		trailResp, err := TripTrail(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("TripTrailHandler: TripTrail Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		resp, err := json.Marshal(trailResp)
+		if err != nil {
+			errMsg := fmt.Sprintf("TripTrailHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Trip Trail.... ID: %v\n", req.TripID)
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("TripTrailHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//BookCabHandler ...
+func BookCabHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("BookCabHandler: Received BookCab Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("BookCabHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.BookingRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("BookCabHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateBookingReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("BookCabHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		cab, err := BookCab(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("BookCabHandler: RegisterCab Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		if cab == nil {
+			fmt.Printf("BookCabHandler: No cabs were found, waitlisting the booking\n")
+			ticketID, err := JoinWaitlist(req)
+			if err != nil {
+				errMsg := fmt.Sprintf("BookCabHandler: JoinWaitlist Failed. Err: %v\n", err)
+				fmt.Printf(errMsg)
+				writeErrorResponse(w, errorStatus(err), errMsg)
+				return
+			}
+
+			resp, err := json.Marshal(mycabsapi.BookingResponse{TicketID: ticketID})
+			if err != nil {
+				errMsg := fmt.Sprintf("BookCabHandler: Response Building Failed. Err: %v\n", err)
+				fmt.Printf(errMsg)
+				writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+				return
+			}
+
+			fmt.Printf("Booking Waitlisted... Ticket: %v\n", ticketID)
+			writeResponseWithStatus(w, http.StatusAccepted, resp)
+			return
+		}
+
+		bookingResp := mycabsapi.BookingResponse{
+			TripID:   cab.TripID,
+			CabID:    cab.ID,
+			DriverID: cab.DriverID,
+			CabName:  cab.Name,
+			CabType:  cab.Type,
+			Distance: cab.Distance,
+		}
+		resp, err := json.Marshal(bookingResp)
+		if err != nil {
+			errMsg := fmt.Sprintf("BookCabHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Cab Booked... ID: %v\n", bookingResp)
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("BookCabHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//BookingTicketHandler ...
+func BookingTicketHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("BookingTicketHandler: Received BookingTicket Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("BookingTicketHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.BookingTicketRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("BookingTicketHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateBookingTicketReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("BookingTicketHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		ticketResp, err := BookingTicket(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("BookingTicketHandler: BookingTicket Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		resp, err := json.Marshal(ticketResp)
+		if err != nil {
+			errMsg := fmt.Sprintf("BookingTicketHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Booking Ticket Fetch Done\n")
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("BookingTicketHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//CancelBookingTicketHandler ...
+func CancelBookingTicketHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("CancelBookingTicketHandler: Received CancelBookingTicket Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("CancelBookingTicketHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.BookingTicketRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CancelBookingTicketHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateBookingTicketReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CancelBookingTicketHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = CancelBookingTicket(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CancelBookingTicketHandler: CancelBookingTicket Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		fmt.Printf("Booking Ticket Cancelled.... ID: %v\n", req.TicketID)
+		writeResponse(w, []byte{})
+
+	default:
+		errMsg := fmt.Sprintf("CancelBookingTicketHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//ReserveCabHandler ...
+func ReserveCabHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("ReserveCabHandler: Received ReserveCab Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("ReserveCabHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.ReserveCabRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ReserveCabHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateReserveCabReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ReserveCabHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		reservationID, err := ReserveCab(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ReserveCabHandler: ReserveCab Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		reserveResp := mycabsapi.ReserveCabResponse{ID: reservationID}
+		resp, err := json.Marshal(reserveResp)
+		if err != nil {
+			errMsg := fmt.Sprintf("ReserveCabHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Cab Reserved.... ID: %v\n", reservationID)
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("ReserveCabHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//ListReservationsHandler ...
+func ListReservationsHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("ListReservationsHandler: Received ListReservations Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("ListReservationsHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.ListReservationsRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ListReservationsHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateListReservationsReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ListReservationsHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		reservationsResp, err := ListReservations(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ListReservationsHandler: ListReservations Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		resp, err := json.Marshal(reservationsResp)
+		if err != nil {
+			errMsg := fmt.Sprintf("ListReservationsHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
 			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
 			return
 		}
 
-		onboardResp := mycabsapi.OnboardCityResponse{ID: cityID}
-		resp, err := json.Marshal(onboardResp)
+		fmt.Printf("Reservations Fetch Done\n")
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("ListReservationsHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//ModifyReservationHandler ...
+func ModifyReservationHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("ModifyReservationHandler: Received ModifyReservation Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("ModifyReservationHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.ModifyReservationRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ModifyReservationHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateModifyReservationReq(req)
 		if err != nil {
-			errMsg := fmt.Sprintf("OnboardCityHandler: Response Building Failed. Err: %v\n", err)
+			errMsg := fmt.Sprintf("ModifyReservationHandler: Request Validation Failed. Err: %v\n", err)
 			fmt.Printf(errMsg)
-			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
 			return
 		}
 
-		fmt.Printf("Citi Onboarded... ID: %v\n", cityID)
-		writeResponse(w, resp)
+		err = ModifyReservation(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ModifyReservationHandler: ModifyReservation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		fmt.Printf("Reservation Modified.... ID: %v\n", req.ID)
+		writeResponse(w, []byte{})
 
 	default:
-		errMsg := fmt.Sprintf("OnboardCityHandler: Invalide Request Method. %v\n", method)
+		errMsg := fmt.Sprintf("ModifyReservationHandler: Invalide Request Method. %v\n", method)
 		fmt.Printf(errMsg)
 		writeErrorResponse(w, http.StatusBadRequest, errMsg)
 		return
 	}
 }
 
-//RegisterCabHandler ...
-func RegisterCabHandler(w http.ResponseWriter, r *http.Request) {
-	fmt.Println("RegisterCabHandler: Received RegisterCab Request")
+//CancelReservationHandler ...
+func CancelReservationHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("CancelReservationHandler: Received CancelReservation Request")
 	switch method := r.Method; method {
 	case http.MethodPost:
 		body, err := ioutil.ReadAll(r.Body)
 		if err != nil {
-			errMsg := fmt.Sprintf("RegisterCabHandler: Request Read Failed. Err: %v\n", err)
+			errMsg := fmt.Sprintf("CancelReservationHandler: Request Read Failed. Err: %v\n", err)
 			fmt.Printf(errMsg)
 			writeErrorResponse(w, http.StatusBadRequest, errMsg)
 			return
 		}
-		req := &mycabsapi.RegisterCabRequest{}
+		req := &mycabsapi.CancelReservationRequest{}
 		err = json.Unmarshal(body, req)
 		if err != nil {
-			errMsg := fmt.Sprintf("RegisterCabHandler: Request Processing Failed. Err: %v\n", err)
+			errMsg := fmt.Sprintf("CancelReservationHandler: Request Processing Failed. Err: %v\n", err)
 			fmt.Printf(errMsg)
 			writeErrorResponse(w, http.StatusBadRequest, errMsg)
 			return
 		}
 
-		err = validateRegisterCabReq(req)
+		err = validateCancelReservationReq(req)
 		if err != nil {
-			errMsg := fmt.Sprintf("RegisterCabHandler: Request Validation Failed. Err: %v\n", err)
+			errMsg := fmt.Sprintf("CancelReservationHandler: Request Validation Failed. Err: %v\n", err)
 			fmt.Printf(errMsg)
 			writeErrorResponse(w, http.StatusBadRequest, errMsg)
 			return
 		}
 
-		cabID, err := RegisterCab(req)
-		if err != nil {
-			errMsg := fmt.Sprintf("RegisterCabHandler: RegisterCab Failed. Err: %v\n", err)
-			fmt.Printf(errMsg)
-			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
-			return
-		}
-
-		onboardResp := mycabsapi.OnboardCityResponse{ID: cabID}
-		resp, err := json.Marshal(onboardResp)
+		err = CancelReservation(req)
 		if err != nil {
-			errMsg := fmt.Sprintf("RegisterCabHandler: Response Building Failed. Err: %v\n", err)
+			errMsg := fmt.Sprintf("CancelReservationHandler: CancelReservation Failed. Err: %v\n", err)
 			fmt.Printf(errMsg)
-			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
 			return
 		}
 
-		fmt.Printf("Cab Registered.... ID: %v\n", cabID)
-		writeResponse(w, resp)
+		fmt.Printf("Reservation Cancelled.... ID: %v\n", req.ID)
+		writeResponse(w, []byte{})
 
 	default:
-		errMsg := fmt.Sprintf("RegisterCabHandler: Invalide Request Method. %v\n", method)
+		errMsg := fmt.Sprintf("CancelReservationHandler: Invalide Request Method. %v\n", method)
 		fmt.Printf(errMsg)
 		writeErrorResponse(w, http.StatusBadRequest, errMsg)
 		return
 	}
 }
 
-//BookCabHandler ...
-func BookCabHandler(w http.ResponseWriter, r *http.Request) {
-	fmt.Println("BookCabHandler: Received BookCab Request")
+//CabArrivingHandler ...
+func CabArrivingHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("CabArrivingHandler: Received CabArriving Request")
 	switch method := r.Method; method {
 	case http.MethodPost:
 		body, err := ioutil.ReadAll(r.Body)
 		if err != nil {
-			errMsg := fmt.Sprintf("BookCabHandler: Request Read Failed. Err: %v\n", err)
+			errMsg := fmt.Sprintf("CabArrivingHandler: Request Read Failed. Err: %v\n", err)
 			fmt.Printf(errMsg)
 			writeErrorResponse(w, http.StatusBadRequest, errMsg)
 			return
 		}
-		req := &mycabsapi.BookingRequest{}
+		req := &mycabsapi.CabArrivingRequest{}
 		err = json.Unmarshal(body, req)
 		if err != nil {
-			errMsg := fmt.Sprintf("BookCabHandler: Request Processing Failed. Err: %v\n", err)
+			errMsg := fmt.Sprintf("CabArrivingHandler: Request Processing Failed. Err: %v\n", err)
 			fmt.Printf(errMsg)
 			writeErrorResponse(w, http.StatusBadRequest, errMsg)
 			return
 		}
 
-		err = validateBookingReq(req)
+		err = validateCabArrivingReq(req)
 		if err != nil {
-			errMsg := fmt.Sprintf("BookCabHandler: Request Validation Failed. Err: %v\n", err)
+			errMsg := fmt.Sprintf("CabArrivingHandler: Request Validation Failed. Err: %v\n", err)
 			fmt.Printf(errMsg)
 			writeErrorResponse(w, http.StatusBadRequest, errMsg)
 			return
 		}
 
-		cab, err := BookCab(req)
+		err = CabArriving(req)
 		if err != nil {
-			errMsg := fmt.Sprintf("BookCabHandler: RegisterCab Failed. Err: %v\n", err)
+			errMsg := fmt.Sprintf("CabArrivingHandler: CabArriving Failed. Err: %v\n", err)
 			fmt.Printf(errMsg)
-			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
 			return
 		}
 
-		if cab == nil {
-			fmt.Printf("BookCabHandler: No cabs were found\n")
-			writeResponse(w, []byte{})
+		fmt.Printf("Cab Arriving.... ID: %v\n", req.CabID)
+		writeResponse(w, []byte{})
+
+	default:
+		errMsg := fmt.Sprintf("CabArrivingHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//PickupRiderHandler ...
+func PickupRiderHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("PickupRiderHandler: Received PickupRider Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("PickupRiderHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.PickupRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("PickupRiderHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
 			return
 		}
 
-		bookingResp := mycabsapi.BookingResponse{
-			CabID:   cab.ID,
-			CabName: cab.Name,
+		err = validatePickupReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("PickupRiderHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
 		}
-		resp, err := json.Marshal(bookingResp)
+
+		err = PickupRider(req)
 		if err != nil {
-			errMsg := fmt.Sprintf("BookCabHandler: Response Building Failed. Err: %v\n", err)
+			errMsg := fmt.Sprintf("PickupRiderHandler: PickupRider Failed. Err: %v\n", err)
 			fmt.Printf(errMsg)
-			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
 			return
 		}
 
-		fmt.Printf("Cab Booked... ID: %v\n", bookingResp)
-		writeResponse(w, resp)
+		fmt.Printf("Rider Picked Up.... ID: %v\n", req.CabID)
+		writeResponse(w, []byte{})
 
 	default:
-		errMsg := fmt.Sprintf("BookCabHandler: Invalide Request Method. %v\n", method)
+		errMsg := fmt.Sprintf("PickupRiderHandler: Invalide Request Method. %v\n", method)
 		fmt.Printf(errMsg)
 		writeErrorResponse(w, http.StatusBadRequest, errMsg)
 		return
@@ -217,16 +3716,24 @@ func EndTripHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		err = EndTrip(req)
+		endTripResp, err := EndTrip(req)
 		if err != nil {
 			errMsg := fmt.Sprintf("EndTripHandler: EndTrip Failed. Err: %v\n", err)
 			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		resp, err := json.Marshal(endTripResp)
+		if err != nil {
+			errMsg := fmt.Sprintf("EndTripHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
 			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
 			return
 		}
 
 		fmt.Printf("Trip Ended.... ID: %v\n", req.CabID)
-		writeResponse(w, []byte{})
+		writeResponse(w, resp)
 
 	default:
 		errMsg := fmt.Sprintf("EndTripHandler: Invalide Request Method. %v\n", method)
@@ -236,6 +3743,110 @@ func EndTripHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+//TripHandler ...
+func TripHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("TripHandler: Received Trip Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("TripHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.TripRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("TripHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateTripReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("TripHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		trip, err := Trip(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("TripHandler: Trip Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		resp, err := json.Marshal(trip)
+		if err != nil {
+			errMsg := fmt.Sprintf("TripHandler: Response Building Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		fmt.Printf("Trip.... ID: %v\n", req.TripID)
+		writeResponse(w, resp)
+
+	default:
+		errMsg := fmt.Sprintf("TripHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//CancelTripHandler ...
+func CancelTripHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("CancelTripHandler: Received CancelTrip Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("CancelTripHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.CancelTripRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CancelTripHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateCancelTripReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CancelTripHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = CancelTrip(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("CancelTripHandler: CancelTrip Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		fmt.Printf("Trip Cancelled.... ID: %v By: %v\n", req.CabID, req.Party)
+		writeResponse(w, []byte{})
+
+	default:
+		errMsg := fmt.Sprintf("CancelTripHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
 //DeActivateCabHandler ...
 func DeActivateCabHandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Println("DeActivateCabHandler: Received DeActivateCab Request")
@@ -269,7 +3880,7 @@ func DeActivateCabHandler(w http.ResponseWriter, r *http.Request) {
 		if err != nil {
 			errMsg := fmt.Sprintf("DeActivateCabHandler: DeActivateCab Failed. Err: %v\n", err)
 			fmt.Printf(errMsg)
-			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
 			return
 		}
 
@@ -317,7 +3928,7 @@ func ActivateCabHandler(w http.ResponseWriter, r *http.Request) {
 		if err != nil {
 			errMsg := fmt.Sprintf("ActivateCabHandler: ActivateCab Failed. Err: %v\n", err)
 			fmt.Printf(errMsg)
-			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
 			return
 		}
 
@@ -365,7 +3976,7 @@ func ChangeCityHandler(w http.ResponseWriter, r *http.Request) {
 		if err != nil {
 			errMsg := fmt.Sprintf("ChangeCityHandler: ChangeCity Failed. Err: %v\n", err)
 			fmt.Printf(errMsg)
-			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
 			return
 		}
 
@@ -380,6 +3991,102 @@ func ChangeCityHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+//SendToMaintenanceHandler ...
+func SendToMaintenanceHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("SendToMaintenanceHandler: Received SendToMaintenance Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("SendToMaintenanceHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.MaintenanceRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("SendToMaintenanceHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateMaintenanceReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("SendToMaintenanceHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = SendToMaintenance(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("SendToMaintenanceHandler: SendToMaintenance Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		fmt.Printf("Sent To Maintenance.... ID: %v\n", req.CabID)
+		writeResponse(w, []byte{})
+
+	default:
+		errMsg := fmt.Sprintf("SendToMaintenanceHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
+//ReturnFromMaintenanceHandler ...
+func ReturnFromMaintenanceHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("ReturnFromMaintenanceHandler: Received ReturnFromMaintenance Request")
+	switch method := r.Method; method {
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			errMsg := fmt.Sprintf("ReturnFromMaintenanceHandler: Request Read Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		req := &mycabsapi.ReturnFromMaintenanceRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ReturnFromMaintenanceHandler: Request Processing Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = validateReturnFromMaintenanceReq(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ReturnFromMaintenanceHandler: Request Validation Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
+
+		err = ReturnFromMaintenance(req)
+		if err != nil {
+			errMsg := fmt.Sprintf("ReturnFromMaintenanceHandler: ReturnFromMaintenance Failed. Err: %v\n", err)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
+			return
+		}
+
+		fmt.Printf("Returned From Maintenance.... ID: %v State: %v\n", req.CabID, req.State)
+		writeResponse(w, []byte{})
+
+	default:
+		errMsg := fmt.Sprintf("ReturnFromMaintenanceHandler: Invalide Request Method. %v\n", method)
+		fmt.Printf(errMsg)
+		writeErrorResponse(w, http.StatusBadRequest, errMsg)
+		return
+	}
+}
+
 //CabHistoryHandler ...
 func CabHistoryHandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Println("CabHistoryHandler: Received CabHistory Request")
@@ -405,7 +4112,7 @@ func CabHistoryHandler(w http.ResponseWriter, r *http.Request) {
 		if err != nil {
 			errMsg := fmt.Sprintf("CabHistoryHandler: CabHistory Failed. Err: %v\n", err)
 			fmt.Printf(errMsg)
-			writeErrorResponse(w, http.StatusInternalServerError, errMsg)
+			writeErrorResponse(w, errorStatus(err), errMsg)
 			return
 		}
 
@@ -433,8 +4140,18 @@ func DemandCityHandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Println("DemandCityHandler: Received CabHistory Request")
 	switch method := r.Method; method {
 	case http.MethodPost:
+		window := strings.ToLower(r.URL.Query().Get("window"))
+		if window == "" {
+			window = "day"
+		}
+		if _, ok := demandWindows[window]; !ok {
+			errMsg := fmt.Sprintf("DemandCityHandler: Request Validation Failed. Err: window must be hour, day or week, not %q\n", window)
+			fmt.Printf(errMsg)
+			writeErrorResponse(w, http.StatusBadRequest, errMsg)
+			return
+		}
 
-		demandCityResp, err := DemandedCity()
+		demandCityResp, err := DemandedCity(window)
 		if err != nil {
 			errMsg := fmt.Sprintf("DemandCityHandler: DemandCity Failed. Err: %v\n", err)
 			fmt.Printf(errMsg)
@@ -442,7 +4159,7 @@ func DemandCityHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		if demandCityResp == nil {
-			fmt.Printf("DemandCityHandler: No cities were found\n")
+			fmt.Printf("DemandCityHandler: No city was booked in the %v\n", window)
 			writeResponse(w, []byte{})
 			return
 		}
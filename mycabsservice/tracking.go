@@ -0,0 +1,243 @@
+package mycabsservice
+
+import (
+	"fmt"
+	"mycabs/db"
+	"mycabs/geo"
+	"mycabs/mycabsapi"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+//Devices of the cabs push GPS pings in batches. Only the newest ping of a cab
+//in a batch is written to the cab, as a partial update which is skipped when
+//the cab already has a newer position. While the cab is on a trip the pings
+//also leave breadcrumbs in the trail partition of the trip, at most one every
+//trailInterval. Breadcrumbs expire after trailRetention, through the TTL of
+//the table on ExpiresAt.
+
+const (
+	hkeyValTrail = "trail/"
+)
+
+const (
+	maxPingBatch      = 500
+	maxPingAge        = 2 * time.Minute  //Older pings are of no use for dispatch.
+	maxPingSkew       = 30 * time.Second //Allowed clock drift of the devices.
+	pingFlushInterval = time.Second      //Longest a streamed ping waits for its batch.
+	trailInterval     = 15 * time.Second
+	trailRetention    = 90 * 24 * time.Hour
+	pingWorkers       = 8
+)
+
+//IngestPings updates the cabs with the pings and returns a result per ping.
+func IngestPings(pings []mycabsapi.Ping) []mycabsapi.PingResult {
+	results := make([]mycabsapi.PingResult, len(pings))
+	cabPings := map[string][]int{}
+	cabIDs := []string{}
+	now := unixMillis(time.Now())
+	for idx, ping := range pings {
+		results[idx] = mycabsapi.PingResult{CabID: ping.CabID, Time: ping.Time}
+		if reason := checkPing(&ping, now); reason != "" {
+			results[idx].Error = reason
+			continue
+		}
+		prev := cabPings[ping.CabID]
+		if len(prev) > 0 && pings[prev[len(prev)-1]].Time >= ping.Time {
+			results[idx].Error = "out of order"
+			continue
+		}
+		if len(prev) == 0 {
+			cabIDs = append(cabIDs, ping.CabID)
+		}
+		cabPings[ping.CabID] = append(prev, idx)
+	}
+	if len(cabIDs) == 0 {
+		return results
+	}
+
+	keys := make([]map[string]*dynamodb.AttributeValue, 0, len(cabIDs))
+	for _, cabID := range cabIDs {
+		keys = append(keys, cabKeys(cabID))
+	}
+	cabRecords, err := db.BatchGet(tableName, keys, []string{"Id", "TripID", "LocationTime", "TrailTime", "GeoCell"})
+	if err != nil {
+		fmt.Printf("IngestPings: db.BatchGet Failed. Err: %v\n", err)
+		for _, cabID := range cabIDs {
+			rejectPings(results, cabPings[cabID], "temporarily unavailable")
+		}
+		return results
+	}
+	cabRecs := make(map[string]map[string]*dynamodb.AttributeValue, len(cabRecords))
+	for _, cabRec := range cabRecords {
+		cabRecs[db.AttrToStr(cabRec["Id"])] = cabRec
+	}
+
+	//Every cab is written by one worker, so the results of a cab are only
+	//touched by that worker.
+	work := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < pingWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for cabID := range work {
+				ingestCabPings(cabID, cabRecs[cabID], pings, cabPings[cabID], results)
+			}
+		}()
+	}
+	for _, cabID := range cabIDs {
+		work <- cabID
+	}
+	close(work)
+	wg.Wait()
+	return results
+}
+
+//ingestCabPings writes the pings at idxs, oldest first, of one cab.
+func ingestCabPings(cabID string, cabRec map[string]*dynamodb.AttributeValue, pings []mycabsapi.Ping, idxs []int, results []mycabsapi.PingResult) {
+	if cabRec == nil {
+		rejectPings(results, idxs, "unknown cab")
+		return
+	}
+	locationTime := int64(0)
+	if attrVal, ok := cabRec["LocationTime"]; ok {
+		locationTime, _ = db.AttrToNum64(attrVal)
+	}
+	fresh := make([]int, 0, len(idxs))
+	for _, idx := range idxs {
+		if pings[idx].Time <= locationTime {
+			results[idx].Error = "older than last known position"
+			continue
+		}
+		fresh = append(fresh, idx)
+	}
+	if len(fresh) == 0 {
+		return
+	}
+
+	latest := &pings[fresh[len(fresh)-1]]
+	updateInfo := locationAttrs(&mycabsapi.Location{Lat: latest.Lat, Lon: latest.Lon})
+	updateInfo["Heading"] = db.FloatToAttr(latest.Heading)
+	updateInfo["LocationTime"] = db.Num64ToAttr(latest.Time)
+	err := db.UpdateIfNewer(tableName, cabKeys(cabID), updateInfo, "LocationTime", latest.Time)
+	if db.IsConditionFailed(err) {
+		//Another batch got a newer ping in meanwhile.
+		rejectPings(results, fresh, "older than last known position")
+		return
+	}
+	if err != nil {
+		fmt.Printf("ingestCabPings: db.UpdateIfNewer of %v Failed. Err: %v\n", cabID, err)
+		rejectPings(results, fresh, "temporarily unavailable")
+		return
+	}
+	for _, idx := range fresh {
+		results[idx].Accepted = true
+	}
+	moveCabCell(cabID, cabGeoCell(cabRec), db.AttrToStr(updateInfo["GeoCell"]))
+
+	attrVal, ok := cabRec["TripID"]
+	if !ok || db.AttrToStr(attrVal) == "" {
+		return
+	}
+	tripID := db.AttrToStr(attrVal)
+	trailTime := int64(0)
+	if attrVal, ok := cabRec["TrailTime"]; ok {
+		trailTime, _ = db.AttrToNum64(attrVal)
+	}
+	lastTrail := trailTime
+	for _, idx := range fresh {
+		ping := &pings[idx]
+		if ping.Time-lastTrail < int64(trailInterval/time.Millisecond) {
+			continue
+		}
+		err = db.Put(tableName, trailRecord(tripID, ping))
+		if err != nil {
+			fmt.Printf("ingestCabPings: db.Put of trail %v Failed. Err: %v\n", tripID, err)
+			break
+		}
+		lastTrail = ping.Time
+	}
+	if lastTrail != trailTime {
+		updateInfo := map[string]*dynamodb.AttributeValue{
+			"TrailTime": db.Num64ToAttr(lastTrail),
+		}
+		err = db.Update(tableName, cabKeys(cabID), updateInfo)
+		if err != nil {
+			fmt.Printf("ingestCabPings: db.Update of %v Failed. Err: %v\n", cabID, err)
+		}
+	}
+}
+
+//TripTrail returns the breadcrumbs of the trip, oldest first.
+func TripTrail(req *mycabsapi.TripTrailRequest) (*mycabsapi.TripTrailResponse, error) {
+	trailRecords, err := db.Query(tableName, trailHKey(req.TripID), nil)
+	if err != nil {
+		fmt.Printf("TripTrail: db.Query Failed. Err: %v\n", err)
+		return nil, err
+	}
+
+	resp := &mycabsapi.TripTrailResponse{
+		TripID: req.TripID,
+		Trail:  []mycabsapi.Ping{},
+	}
+	for _, trailRec := range trailRecords {
+		lat, _ := db.AttrToFloat(trailRec["Lat"])
+		lon, _ := db.AttrToFloat(trailRec["Lon"])
+		heading, _ := db.AttrToFloat(trailRec["Heading"])
+		pingTime, _ := db.AttrToNum64(trailRec["Time"])
+		resp.Trail = append(resp.Trail, mycabsapi.Ping{
+			CabID:   db.AttrToStr(trailRec["CabID"]),
+			Lat:     lat,
+			Lon:     lon,
+			Heading: heading,
+			Time:    pingTime,
+		})
+	}
+	return resp, nil
+}
+
+//checkPing returns why the ping is rejected, empty if it is fine.
+func checkPing(ping *mycabsapi.Ping, now int64) string {
+	switch {
+	case ping.CabID == "":
+		return "cabid cannot be empty"
+	case !geo.ValidPoint(ping.Lat, ping.Lon):
+		return "invalid lat/lon"
+	case ping.Heading < 0 || ping.Heading >= 360:
+		return "invalid heading"
+	case ping.Time < now-int64(maxPingAge/time.Millisecond):
+		return "stale"
+	case ping.Time > now+int64(maxPingSkew/time.Millisecond):
+		return "time in the future"
+	}
+	return ""
+}
+
+//rejectPings ...
+func rejectPings(results []mycabsapi.PingResult, idxs []int, reason string) {
+	for _, idx := range idxs {
+		results[idx].Error = reason
+	}
+}
+
+//trailRecord is the breadcrumb of the ping, keyed by its time.
+func trailRecord(tripID string, ping *mycabsapi.Ping) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(trailHKey(tripID)),
+		db.RKeyName: db.StrToAttr(fmt.Sprintf("%015d", ping.Time)),
+		"CabID":     db.StrToAttr(ping.CabID),
+		"Lat":       db.FloatToAttr(ping.Lat),
+		"Lon":       db.FloatToAttr(ping.Lon),
+		"Heading":   db.FloatToAttr(ping.Heading),
+		"Time":      db.Num64ToAttr(ping.Time),
+		"ExpiresAt": db.Num64ToAttr(ping.Time/1000 + int64(trailRetention/time.Second)),
+	}
+}
+
+//trailHKey is the partition holding the breadcrumbs of a trip.
+func trailHKey(tripID string) string {
+	return hkeyValTrail + tripID + "/"
+}
@@ -0,0 +1,410 @@
+package mycabsservice
+
+import (
+	"fmt"
+	"mycabs/db"
+	"mycabs/fare"
+	"mycabs/geo"
+	"mycabs/mycabsapi"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+//Every booking gets a trip record under hkeyValTrips. The cab is the source of
+//truth while the trip runs, the trip record follows it and keeps the fare once
+//the trip is over. The trips of a rider are indexed under hkeyValRiderTrips
+//and the ones of a driver under hkeyValDriverTrips, in the order they were
+//booked, so that they are read without going through the trips of everyone
+//else. Completed trips are also indexed under hkeyValBilledTrips of the rider
+//or corporate account paying them, in the order they ended.
+
+const (
+	hkeyValTrips       = "trips/"
+	hkeyValRiderTrips  = "ridertrips/"
+	hkeyValDriverTrips = "drivertrips/"
+	hkeyValBilledTrips = "billedtrips/"
+)
+
+const (
+	tripBooked    = "BOOKED"
+	tripOnTrip    = "ON_TRIP"
+	tripCompleted = "COMPLETED"
+	tripCancelled = "CANCELLED"
+)
+
+//Trip ...
+func Trip(req *mycabsapi.TripRequest) (*mycabsapi.Trip, error) {
+	tripRec, err := loadTrip(req.TripID)
+	if err != nil {
+		return nil, err
+	}
+	return toTrip(tripRec), nil
+}
+
+//tripWrites record the trip booked on the cab, with the spend held on its
+//corporate account if any, and its index entries. They are written along
+//with the cab taking the trip.
+func tripWrites(tripID string, req *mycabsapi.BookingRequest, candidate *CandidateCab, bookedAt time.Time, held int64) []*db.TxWrite {
+	tripRecord := make(map[string]*dynamodb.AttributeValue)
+	tripRecord[db.HKeyName] = db.StrToAttr(hkeyValTrips)
+	tripRecord[db.RKeyName] = db.StrToAttr(tripID)
+	tripRecord["Id"] = db.StrToAttr(tripID)
+	tripRecord["State"] = db.StrToAttr(tripBooked)
+	tripRecord["RiderID"] = db.StrToAttr(req.RiderID)
+	tripRecord["CabID"] = db.StrToAttr(candidate.ID)
+	tripRecord["CabType"] = db.StrToAttr(candidate.Type)
+	tripRecord["DriverID"] = db.StrToAttr(candidate.DriverID)
+	tripRecord["From"] = db.StrToAttr(req.From)
+	tripRecord["To"] = db.StrToAttr(req.To)
+	tripRecord["BookedAt"] = db.Num64ToAttr(bookedAt.Unix())
+	if candidate.Type != req.CabType {
+		//Upgraded, the rider pays for the type booked.
+		tripRecord["BookedType"] = db.StrToAttr(req.CabType)
+	}
+	//The rider pays the surge of the time of booking.
+	tripRecord["Surge"] = db.FloatToAttr(surgeMultiplier(req.From, req.CabType))
+	for attr, attrVal := range pickupAttrs(req.Pickup) {
+		tripRecord[attr] = attrVal
+	}
+	for attr, attrVal := range promoAttrs(req.PromoCode) {
+		tripRecord[attr] = attrVal
+	}
+	for attr, attrVal := range corporateAttrs(req.CorporateID) {
+		tripRecord[attr] = attrVal
+	}
+	if held > 0 {
+		tripRecord["CorporateHeld"] = db.Num64ToAttr(held)
+	}
+
+	writes := []*db.TxWrite{&db.TxWrite{Put: tripRecord}}
+	for _, indexRec := range tripIndexRecords(tripRecord) {
+		writes = append(writes, &db.TxWrite{Put: indexRec})
+	}
+	return writes
+}
+
+//startTrip ...
+func startTrip(tripID string, pickedUpAt time.Time) {
+	updateInfo := map[string]*dynamodb.AttributeValue{
+		"State":      db.StrToAttr(tripOnTrip),
+		"PickedUpAt": db.Num64ToAttr(pickedUpAt.Unix()),
+	}
+	updateTrip(tripID, updateInfo)
+}
+
+//cancelTrip ...
+func cancelTrip(tripID, party, reason string) {
+	cancelledAt := time.Now()
+	updateInfo := map[string]*dynamodb.AttributeValue{
+		"State":       db.StrToAttr(tripCancelled),
+		"CancelledBy": db.StrToAttr(party),
+		"EndedAt":     db.Num64ToAttr(cancelledAt.Unix()),
+	}
+	if reason != "" {
+		updateInfo["Reason"] = db.StrToAttr(reason)
+	}
+	if tripRec, err := loadTrip(tripID); err == nil {
+		releaseRider(tripRiderID(tripRec), tripID)
+		fee := chargeCancellation(tripRec, party, cancelledAt)
+		for attr, attrVal := range fareAttrs(fee) {
+			updateInfo[attr] = attrVal
+		}
+		spent := int64(0)
+		if fee != nil {
+			spent = fee.Total
+		}
+		bookedAt, _ := db.AttrToNum64(tripRec["BookedAt"])
+		settleCorporateSpend(attrsToCorporateID(tripRec), corporateHeld(tripRec), time.Unix(bookedAt, 0), cancelledAt, spent)
+	}
+	updateTrip(tripID, updateInfo)
+}
+
+//completeTrip works out the distance, duration and fare of the trip the cab
+//has just ended in cityID, and stores them on the trip.
+func completeTrip(cabRec map[string]*dynamodb.AttributeValue, cityID string, distance float64, endTime time.Time) *mycabsapi.EndTripResponse {
+	tripID := cabTripID(cabRec)
+	resp := &mycabsapi.EndTripResponse{
+		TripID: tripID,
+	}
+	if tripID == "" {
+		//Booked before trips were recorded.
+		return resp
+	}
+
+	pickedUpAt := int64(0)
+	if attrVal, ok := cabRec["PickedUpAt"]; ok {
+		pickedUpAt, _ = db.AttrToNum64(attrVal)
+		resp.Duration = endTime.Unix() - pickedUpAt
+	}
+	resp.Distance = distance
+	if resp.Distance == 0 {
+		resp.Distance = trailDistance(tripID, cabRec, pickedUpAt)
+	}
+
+	fromCityID := db.AttrToStr(cabRec["CityID"])
+	cabType := db.AttrToStr(cabRec["Type"])
+	multiplier := 1.0
+	promoCode, riderID, corporateID, bookedAt := "", "", "", endTime
+	held := int64(0)
+	if tripRec, err := loadTrip(tripID); err == nil {
+		if attrVal, ok := tripRec["Surge"]; ok {
+			multiplier, _ = db.AttrToFloat(attrVal)
+		}
+		promoCode, riderID = attrsToPromoCode(tripRec), tripRiderID(tripRec)
+		corporateID, held = attrsToCorporateID(tripRec), corporateHeld(tripRec)
+		if attrVal, ok := tripRec["BookedType"]; ok {
+			cabType = db.AttrToStr(attrVal)
+		}
+		if attrVal, ok := tripRec["BookedAt"]; ok {
+			booked, _ := db.AttrToNum64(attrVal)
+			bookedAt = time.Unix(booked, 0)
+		}
+		releaseRider(riderID, tripID)
+	}
+
+	tariff, err := loadTariff(fromCityID, cabType)
+	if err == nil {
+		resp.Fare = toFare(tariff.Compute(&fare.Trip{
+			From:     fromCityID,
+			To:       cityID,
+			Meters:   resp.Distance,
+			Duration: time.Duration(resp.Duration) * time.Second,
+			Surge:    multiplier,
+			Discount: redeemPromo(promoCode, riderID, fromCityID, cabType, bookedAt),
+		}))
+	} else {
+		fmt.Printf("completeTrip: No fare for %v. Err: %v\n", tripID, err)
+	}
+
+	updateInfo := map[string]*dynamodb.AttributeValue{
+		"State":    db.StrToAttr(tripCompleted),
+		"To":       db.StrToAttr(cityID),
+		"EndedAt":  db.Num64ToAttr(endTime.Unix()),
+		"Distance": db.FloatToAttr(resp.Distance),
+		"Duration": db.Num64ToAttr(resp.Duration),
+	}
+	for attr, attrVal := range fareAttrs(resp.Fare) {
+		updateInfo[attr] = attrVal
+	}
+	if tariff != nil && tariff.TaxRate > 0 {
+		//The receipt shows the tax of the tariff the trip was charged on.
+		updateInfo["TaxName"] = db.StrToAttr(tariff.TaxName)
+		updateInfo["TaxRate"] = db.FloatToAttr(tariff.TaxRate)
+	}
+	writes := []*db.TxWrite{&db.TxWrite{Update: tripKeys(tripID), Updates: updateInfo}}
+	payerID := corporateID
+	if payerID == "" {
+		payerID = riderID
+	}
+	if payerID != "" {
+		writes = append(writes, &db.TxWrite{Put: tripIndexRecord(hkeyValBilledTrips+payerID+"/", endTime.Unix(), tripID)})
+	}
+	err = db.Transact(tableName, writes)
+	if err != nil {
+		fmt.Printf("completeTrip: db.Transact of %v Failed. Err: %v\n", tripID, err)
+	}
+	spent := int64(0)
+	if resp.Fare != nil {
+		spent = resp.Fare.Total
+	}
+	settleCorporateSpend(corporateID, held, bookedAt, endTime, spent)
+	return resp
+}
+
+//trailDistance is the length in meters of the trail of the trip since the
+//pickup, up to the last known location of the cab.
+func trailDistance(tripID string, cabRec map[string]*dynamodb.AttributeValue, pickedUpAt int64) float64 {
+	filter := map[string]*dynamodb.Condition{
+		"Time": &dynamodb.Condition{
+			ComparisonOperator: aws.String("GE"),
+			AttributeValueList: []*dynamodb.AttributeValue{db.Num64ToAttr(pickedUpAt * 1000)},
+		},
+	}
+	trailRecords, err := db.Query(tableName, trailHKey(tripID), filter)
+	if err != nil {
+		fmt.Printf("trailDistance: db.Query Failed. Err: %v\n", err)
+		return 0
+	}
+
+	points := make([]*mycabsapi.Location, 0, len(trailRecords)+1)
+	lastTime := int64(0)
+	for _, trailRec := range trailRecords {
+		points = append(points, attrsToPoint(trailRec, "Lat", "Lon"))
+		lastTime, _ = db.AttrToNum64(trailRec["Time"])
+	}
+	if attrVal, ok := cabRec["LocationTime"]; ok {
+		locationTime, _ := db.AttrToNum64(attrVal)
+		if loc := attrsToLocation(cabRec); loc != nil && locationTime > lastTime {
+			points = append(points, loc)
+		}
+	}
+
+	meters := 0.0
+	for i := 1; i < len(points); i++ {
+		if points[i-1] == nil || points[i] == nil {
+			continue
+		}
+		meters += geo.Distance(points[i-1].Lat, points[i-1].Lon, points[i].Lat, points[i].Lon)
+	}
+	return meters
+}
+
+//updateTrip ...
+func updateTrip(tripID string, updateInfo map[string]*dynamodb.AttributeValue) {
+	if tripID == "" {
+		return
+	}
+	err := db.Update(tableName, tripKeys(tripID), updateInfo)
+	if err != nil {
+		fmt.Printf("updateTrip: db.Update of %v Failed. Err: %v\n", tripID, err)
+	}
+}
+
+//toTrip ...
+func toTrip(tripRec map[string]*dynamodb.AttributeValue) *mycabsapi.Trip {
+	trip := &mycabsapi.Trip{
+		ID:       db.AttrToStr(tripRec["Id"]),
+		State:    db.AttrToStr(tripRec["State"]),
+		RiderID:  tripRiderID(tripRec),
+		CabID:    db.AttrToStr(tripRec["CabID"]),
+		DriverID: tripDriverID(tripRec),
+		CabType:  db.AttrToStr(tripRec["CabType"]),
+		From:     db.AttrToStr(tripRec["From"]),
+		To:       db.AttrToStr(tripRec["To"]),
+		BookedAt: attrToTime(tripRec["BookedAt"]),
+		Fare:     attrsToFare(tripRec),
+	}
+	trip.PromoCode = attrsToPromoCode(tripRec)
+	trip.CorporateID = attrsToCorporateID(tripRec)
+	if attrVal, ok := tripRec["BookedType"]; ok {
+		trip.BookedType = db.AttrToStr(attrVal)
+	}
+	trip.PickedUpAt = attrToTime(tripRec["PickedUpAt"])
+	trip.EndedAt = attrToTime(tripRec["EndedAt"])
+	if attrVal, ok := tripRec["RiderStars"]; ok {
+		trip.RiderStars, _ = db.AttrToNum(attrVal)
+	}
+	if attrVal, ok := tripRec["DriverStars"]; ok {
+		trip.DriverStars, _ = db.AttrToNum(attrVal)
+	}
+	if attrVal, ok := tripRec["Distance"]; ok {
+		trip.Distance, _ = db.AttrToFloat(attrVal)
+		trip.Duration, _ = db.AttrToNum64(tripRec["Duration"])
+	}
+	return trip
+}
+
+//tripRiderID is the rider of the trip, empty for trips from before riders.
+func tripRiderID(tripRec map[string]*dynamodb.AttributeValue) string {
+	if attrVal, ok := tripRec["RiderID"]; ok {
+		return db.AttrToStr(attrVal)
+	}
+	return ""
+}
+
+//tripDriverID is the driver of the trip, empty for trips from before drivers.
+func tripDriverID(tripRec map[string]*dynamodb.AttributeValue) string {
+	if attrVal, ok := tripRec["DriverID"]; ok {
+		return db.AttrToStr(attrVal)
+	}
+	return ""
+}
+
+//cabTripID is the trip the cab is on, empty if it is on none.
+func cabTripID(cabRec map[string]*dynamodb.AttributeValue) string {
+	if attrVal, ok := cabRec["TripID"]; ok {
+		return db.AttrToStr(attrVal)
+	}
+	return ""
+}
+
+//attrToTime formats a unix time attribute, empty if it is not set.
+func attrToTime(attrVal *dynamodb.AttributeValue) string {
+	if attrVal == nil {
+		return ""
+	}
+	t, err := db.AttrToNum64(attrVal)
+	if err != nil || t == 0 {
+		return ""
+	}
+	return time.Unix(t, 0).Format(time.RFC3339)
+}
+
+//loadTrip ...
+func loadTrip(tripID string) (map[string]*dynamodb.AttributeValue, error) {
+	tripRec, err := db.Get(tableName, tripKeys(tripID))
+	if err != nil {
+		fmt.Printf("loadTrip: db.Get Failed. Err: %v\n", err)
+		return nil, err
+	}
+	if len(tripRec) == 0 {
+		return nil, &notFoundError{kind: "trip", id: tripID}
+	}
+	return tripRec, nil
+}
+
+//tripKeys ...
+func tripKeys(tripID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(hkeyValTrips),
+		db.RKeyName: db.StrToAttr(tripID),
+	}
+}
+
+//tripIndexRecords are the entries of the trip in the rider and driver trip
+//indexes.
+func tripIndexRecords(tripRec map[string]*dynamodb.AttributeValue) []map[string]*dynamodb.AttributeValue {
+	tripID := db.AttrToStr(tripRec["Id"])
+	bookedAt, _ := db.AttrToNum64(tripRec["BookedAt"])
+	indexRecords := []map[string]*dynamodb.AttributeValue{}
+	if riderID := tripRiderID(tripRec); riderID != "" {
+		indexRecords = append(indexRecords, tripIndexRecord(hkeyValRiderTrips+riderID+"/", bookedAt, tripID))
+	}
+	if driverID := tripDriverID(tripRec); driverID != "" {
+		indexRecords = append(indexRecords, tripIndexRecord(hkeyValDriverTrips+driverID+"/", bookedAt, tripID))
+	}
+	return indexRecords
+}
+
+//tripIndexRecord points from the index partition hkey to the trip, in the
+//order of at.
+func tripIndexRecord(hkey string, at int64, tripID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(hkey),
+		db.RKeyName: db.StrToAttr(fmt.Sprintf("%012d/%v", at, tripID)),
+		"TripID":    db.StrToAttr(tripID),
+	}
+}
+
+//indexedTrips loads the trips of the index partition hkey from from to to,
+//unix seconds both included, in the order of the index.
+func indexedTrips(hkey string, from, to int64) ([]map[string]*dynamodb.AttributeValue, error) {
+	indexRecords, err := db.QueryBetween(tableName, hkey, fmt.Sprintf("%012d", from), fmt.Sprintf("%012d/~", to))
+	if err != nil {
+		fmt.Printf("indexedTrips: db.QueryBetween of %v Failed. Err: %v\n", hkey, err)
+		return nil, err
+	}
+	order := make(map[string]int, len(indexRecords))
+	keys := make([]map[string]*dynamodb.AttributeValue, 0, len(indexRecords))
+	for _, indexRec := range indexRecords {
+		tripID := db.AttrToStr(indexRec["TripID"])
+		if _, ok := order[tripID]; ok {
+			continue
+		}
+		order[tripID] = len(keys)
+		keys = append(keys, tripKeys(tripID))
+	}
+
+	tripRecords, err := db.BatchGet(tableName, keys, nil)
+	if err != nil {
+		fmt.Printf("indexedTrips: db.BatchGet of %v Failed. Err: %v\n", hkey, err)
+		return nil, err
+	}
+	sort.Slice(tripRecords, func(i, j int) bool {
+		return order[db.AttrToStr(tripRecords[i]["Id"])] < order[db.AttrToStr(tripRecords[j]["Id"])]
+	})
+	return tripRecords, nil
+}
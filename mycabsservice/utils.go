@@ -2,18 +2,42 @@ package mycabsservice
 
 import (
 	"errors"
+	"fmt"
+	"mycabs/geo"
+	"mycabs/ledger"
 	"mycabs/mycabsapi"
+	"mycabs/rebalance"
 	"net/http"
+	"regexp"
+	"strings"
+	"time"
 )
 
 /////////////////-------------Some Utility Functions-------------////////////
 
+var promoCodePattern = regexp.MustCompile(`^[A-Za-z0-9]{3,20}$`)
+
+var cabTypePattern = regexp.MustCompile(`^[a-z0-9-]{1,30}$`)
+
 func writeResponse(w http.ResponseWriter, jsonResp []byte) {
 	w.Header().Add("content-type", "application/json")
 	w.Header().Add("charset", "utf-8")
 	w.Write(jsonResp)
 }
 
+func writeResponseWithStatus(w http.ResponseWriter, httpStatus int, jsonResp []byte) {
+	w.Header().Add("content-type", "application/json")
+	w.Header().Add("charset", "utf-8")
+	w.WriteHeader(httpStatus)
+	w.Write(jsonResp)
+}
+
+//writeDocument writes a receipt or invoice rendered for printing.
+func writeDocument(w http.ResponseWriter, contentType string, doc []byte) {
+	w.Header().Add("content-type", contentType)
+	w.Write(doc)
+}
+
 func writeErrorResponse(w http.ResponseWriter, httpStatus int, errMsg string) {
 	w.Header().Add("errormsg", errMsg)
 	w.WriteHeader(httpStatus)
@@ -25,6 +49,174 @@ func validateOnboardCityReq(req *mycabsapi.OnboardCityRequest) error {
 	if req.Name == "" {
 		return errors.New("validateOnboardCityReq: Name Cannot be Empty")
 	}
+	if req.LaunchPromo != nil {
+		return validateCreatePromoReq(req.LaunchPromo)
+	}
+	return nil
+}
+
+//validateCityReq ...
+func validateCityReq(req *mycabsapi.CityRequest) error {
+	if req.CityID == "" {
+		return errors.New("validateCityReq: CityID cannot be Empty")
+	}
+	return nil
+}
+
+//validateListCitiesReq ...
+func validateListCitiesReq(req *mycabsapi.ListCitiesRequest) error {
+	req.State = strings.ToLower(req.State)
+	if req.State != "" && req.State != cityStateActive && req.State != cityStateInactive {
+		return fmt.Errorf("validateListCitiesReq: Invalid State %v", req.State)
+	}
+	return nil
+}
+
+//validateRenameCityReq ...
+func validateRenameCityReq(req *mycabsapi.RenameCityRequest) error {
+	if req.CityID == "" || req.Name == "" {
+		return errors.New("validateRenameCityReq: CityID/Name cannot be Empty")
+	}
+	return nil
+}
+
+//validateSetCityScheduleReq ...
+func validateSetCityScheduleReq(req *mycabsapi.SetCityScheduleRequest) error {
+	if req.CityID == "" {
+		return errors.New("validateSetCityScheduleReq: CityID cannot be Empty")
+	}
+	schedule := req.Schedule
+	if schedule == nil {
+		return nil
+	}
+	if schedule.FromHour < 0 || schedule.FromHour > 23 || schedule.ToHour < 0 || schedule.ToHour > 23 {
+		return errors.New("validateSetCityScheduleReq: FromHour/ToHour must be from 0 to 23")
+	}
+	if _, err := time.LoadLocation(schedule.TimeZone); err != nil {
+		return fmt.Errorf("validateSetCityScheduleReq: Unknown TimeZone %q", schedule.TimeZone)
+	}
+	for _, holiday := range schedule.Holidays {
+		if _, err := time.Parse(holidayFormat, holiday); err != nil {
+			return fmt.Errorf("validateSetCityScheduleReq: Holiday %q must be YYYY-MM-DD", holiday)
+		}
+	}
+	return nil
+}
+
+//validateSetCityZonesReq ...
+func validateSetCityZonesReq(req *mycabsapi.SetCityZonesRequest) error {
+	if req.CityID == "" {
+		return errors.New("validateSetCityZonesReq: CityID cannot be Empty")
+	}
+	names := map[string]bool{}
+	for _, zone := range req.Zones {
+		if zone == nil || zone.Name == "" {
+			return errors.New("validateSetCityZonesReq: Zone Name cannot be Empty")
+		}
+		if names[zone.Name] {
+			return fmt.Errorf("validateSetCityZonesReq: Zone %v given twice", zone.Name)
+		}
+		names[zone.Name] = true
+		if len(zone.Polygon) < 3 {
+			return fmt.Errorf("validateSetCityZonesReq: Zone %v needs at least 3 vertices", zone.Name)
+		}
+		for _, vertex := range zone.Polygon {
+			if vertex == nil || !geo.ValidPoint(vertex.Lat, vertex.Lon) {
+				return fmt.Errorf("validateSetCityZonesReq: Zone %v has an Invalid vertex", zone.Name)
+			}
+		}
+	}
+	return nil
+}
+
+//validateServiceAvailableReq ...
+func validateServiceAvailableReq(req *mycabsapi.ServiceAvailableRequest) error {
+	if req.CityID == "" {
+		return errors.New("validateServiceAvailableReq: CityID cannot be Empty")
+	}
+	if req.Pickup != nil && !geo.ValidPoint(req.Pickup.Lat, req.Pickup.Lon) {
+		return errors.New("validateServiceAvailableReq: Invalid Pickup")
+	}
+	if req.At != "" {
+		if _, err := time.Parse(time.RFC3339, req.At); err != nil {
+			return fmt.Errorf("validateServiceAvailableReq: At must be RFC3339. Err: %v", err)
+		}
+	}
+	return nil
+}
+
+//validateRecommendRebalanceReq ...
+func validateRecommendRebalanceReq(req *mycabsapi.RecommendRebalanceRequest) error {
+	req.CabType = strings.ToLower(req.CabType)
+	if req.WindowMinutes < 0 || req.Ratio < 0 || req.MaxMoves < 0 {
+		return errors.New("validateRecommendRebalanceReq: WindowMinutes/Ratio/MaxMoves cannot be Negative")
+	}
+	if req.MaxMoves > maxRebalanceMoves {
+		return fmt.Errorf("validateRecommendRebalanceReq: At most %v MaxMoves", maxRebalanceMoves)
+	}
+	if req.WindowMinutes > maxRebalanceWindow {
+		return fmt.Errorf("validateRecommendRebalanceReq: At most %v WindowMinutes", maxRebalanceWindow)
+	}
+	if req.WindowMinutes == 0 {
+		req.WindowMinutes = rebalanceWindow
+	}
+	if req.Ratio == 0 {
+		req.Ratio = rebalance.Default.Ratio
+	}
+	if req.MaxMoves == 0 {
+		req.MaxMoves = rebalance.Default.MaxMoves
+	}
+	return nil
+}
+
+//validateRebalancePlanReq ...
+func validateRebalancePlanReq(req *mycabsapi.RebalancePlanRequest) error {
+	if req.PlanID == "" {
+		return errors.New("validateRebalancePlanReq: PlanID cannot be Empty")
+	}
+	return nil
+}
+
+//validateDemandAnalyticsReq ...
+func validateDemandAnalyticsReq(req *mycabsapi.DemandAnalyticsRequest) error {
+	to := time.Now().UTC()
+	if req.To != "" {
+		var err error
+		to, err = time.Parse(time.RFC3339, req.To)
+		if err != nil {
+			return fmt.Errorf("validateDemandAnalyticsReq: To must be RFC3339. Err: %v", err)
+		}
+	}
+	from := to.Add(-24 * time.Hour)
+	if req.From != "" {
+		var err error
+		from, err = time.Parse(time.RFC3339, req.From)
+		if err != nil {
+			return fmt.Errorf("validateDemandAnalyticsReq: From must be RFC3339. Err: %v", err)
+		}
+	}
+	if !from.Before(to) || to.Sub(from) > maxAnalyticsWindow {
+		return errors.New("validateDemandAnalyticsReq: From/To must be in order and at most 92 days apart")
+	}
+	req.From, req.To = from.Format(time.RFC3339), to.Format(time.RFC3339)
+
+	if req.Top < 0 || req.Top > maxAnalyticsTop {
+		return fmt.Errorf("validateDemandAnalyticsReq: Top must be from 0 to %v", maxAnalyticsTop)
+	}
+	if req.Top == 0 {
+		req.Top = analyticsTop
+	}
+	req.CabType = strings.ToLower(req.CabType)
+	req.Bucket = strings.ToLower(req.Bucket)
+	if req.Bucket == "" {
+		req.Bucket = analyticsBucketHour
+	}
+	if req.Bucket != analyticsBucketHour && req.Bucket != analyticsBucketDay {
+		return errors.New("validateDemandAnalyticsReq: Bucket must be hour or day")
+	}
+	if _, err := time.LoadLocation(req.TimeZone); err != nil {
+		return fmt.Errorf("validateDemandAnalyticsReq: Unknown TimeZone %q", req.TimeZone)
+	}
 	return nil
 }
 
@@ -33,21 +225,519 @@ func validateRegisterCabReq(req *mycabsapi.RegisterCabRequest) error {
 	if req.Name == "" || req.Type == "" || req.CityID == "" {
 		return errors.New("validateRegisterCabReq: Name/Type/CityID Cannot be Empty")
 	}
+	req.Type = strings.ToLower(req.Type)
+
+	if req.Location != nil && !geo.ValidPoint(req.Location.Lat, req.Location.Lon) {
+		return errors.New("validateRegisterCabReq: Invalid Location")
+	}
+	if req.Seats < 0 {
+		return errors.New("validateRegisterCabReq: Seats cannot be Negative")
+	}
+	req.Plate = strings.ToUpper(req.Plate)
+	return nil
+}
 
-	//Improvements TODO: Validate for proper cityId and type
+//validateUpdateCabReq ...
+func validateUpdateCabReq(req *mycabsapi.UpdateCabRequest) error {
+	if req.CabID == "" {
+		return errors.New("validateUpdateCabReq: CabID cannot be Empty")
+	}
+	if req.Seats < 0 {
+		return errors.New("validateUpdateCabReq: Seats cannot be Negative")
+	}
+	req.Type = strings.ToLower(req.Type)
+	req.Plate = strings.ToUpper(req.Plate)
+	return nil
+}
 
+//validateDecommissionCabReq ...
+func validateDecommissionCabReq(req *mycabsapi.DecommissionCabRequest) error {
+	if req.CabID == "" {
+		return errors.New("validateDecommissionCabReq: CabID cannot be Empty")
+	}
+	return nil
+}
+
+//validateSearchCabsReq ...
+func validateSearchCabsReq(req *mycabsapi.SearchCabsRequest) error {
+	req.Type = strings.ToLower(req.Type)
+	req.State = strings.ToUpper(req.State)
+	if req.IdleMinutes < 0 {
+		return errors.New("validateSearchCabsReq: IdleMinutes cannot be Negative")
+	}
+	if req.IdleMinutes > 0 && req.State != "" && req.State != stateIdle {
+		return fmt.Errorf("validateSearchCabsReq: IdleMinutes cannot be used with State %v", req.State)
+	}
+
+	req.Sort = strings.ToLower(req.Sort)
+	if req.Sort == "" {
+		req.Sort = fleetSortID
+	}
+	if req.Sort != fleetSortID && req.Sort != fleetSortName && req.Sort != fleetSortIdleSince {
+		return fmt.Errorf("validateSearchCabsReq: Invalid Sort %v", req.Sort)
+	}
+	if req.Sort == fleetSortIdleSince && req.State != "" && req.State != stateIdle {
+		return fmt.Errorf("validateSearchCabsReq: Sort %v lists IDLE cabs only", req.Sort)
+	}
+	if req.Limit < 0 || req.Limit > fleetMaxPageSize {
+		return fmt.Errorf("validateSearchCabsReq: Limit must be from 1 to %v", fleetMaxPageSize)
+	}
+	if req.Limit == 0 {
+		req.Limit = fleetPageSize
+	}
+	for idx, field := range req.Fields {
+		req.Fields[idx] = strings.ToLower(field)
+		if _, ok := fleetFields[req.Fields[idx]]; !ok {
+			return fmt.Errorf("validateSearchCabsReq: Unknown Field %v", field)
+		}
+	}
 	return nil
 }
 
 //validateRegisterCabReq ...
 func validateBookingReq(req *mycabsapi.BookingRequest) error {
+	if req.RiderID == "" {
+		return errors.New("validateBookingReq: RiderID cannot be Empty")
+	}
 	if req.From == "" || req.To == "" || req.CabType == "" {
 		return errors.New("validateBookingReq: From/To/Type cannot be Empty")
 	}
+	req.CabType = strings.ToLower(req.CabType)
+
+	if req.Pickup != nil && !geo.ValidPoint(req.Pickup.Lat, req.Pickup.Lon) {
+		return errors.New("validateBookingReq: Invalid Pickup")
+	}
+	return nil
+}
+
+//validateBookingTicketReq ...
+func validateBookingTicketReq(req *mycabsapi.BookingTicketRequest) error {
+	if req.TicketID == "" {
+		return errors.New("validateBookingTicketReq: TicketID cannot be Empty")
+	}
+	return nil
+}
+
+//validateReserveCabReq ...
+func validateReserveCabReq(req *mycabsapi.ReserveCabRequest) error {
+	if req.RiderID == "" || req.From == "" || req.To == "" || req.CabType == "" || req.PickupTime == "" {
+		return errors.New("validateReserveCabReq: RiderID/From/To/Type/PickupTime cannot be Empty")
+	}
+	req.CabType = strings.ToLower(req.CabType)
+	if req.Pickup != nil && !geo.ValidPoint(req.Pickup.Lat, req.Pickup.Lon) {
+		return errors.New("validateReserveCabReq: Invalid Pickup")
+	}
+	return validatePickupTime(req.PickupTime)
+}
+
+//validateListReservationsReq ...
+func validateListReservationsReq(req *mycabsapi.ListReservationsRequest) error {
+	if req.RiderID == "" {
+		return errors.New("validateListReservationsReq: RiderID cannot be Empty")
+	}
+	req.State = strings.ToUpper(req.State)
+	return nil
+}
+
+//validateModifyReservationReq ...
+func validateModifyReservationReq(req *mycabsapi.ModifyReservationRequest) error {
+	if req.ID == "" {
+		return errors.New("validateModifyReservationReq: ID cannot be Empty")
+	}
+	req.CabType = strings.ToLower(req.CabType)
+	if req.Pickup != nil && !geo.ValidPoint(req.Pickup.Lat, req.Pickup.Lon) {
+		return errors.New("validateModifyReservationReq: Invalid Pickup")
+	}
+	if req.PickupTime != "" {
+		return validatePickupTime(req.PickupTime)
+	}
+	return nil
+}
+
+//validateCancelReservationReq ...
+func validateCancelReservationReq(req *mycabsapi.CancelReservationRequest) error {
+	if req.ID == "" {
+		return errors.New("validateCancelReservationReq: ID cannot be Empty")
+	}
+	return nil
+}
+
+//validatePickupTime ...
+func validatePickupTime(pickupTime string) error {
+	t, err := time.Parse(time.RFC3339, pickupTime)
+	if err != nil {
+		return fmt.Errorf("validatePickupTime: PickupTime must be RFC3339. Err: %v", err)
+	}
+	if !t.After(time.Now()) {
+		return errors.New("validatePickupTime: PickupTime must be in the future")
+	}
+	return nil
+}
 
-	//Improvements TODO: Validate for proper cityId in From and To fileds
-	//Improvements TODO: Validate for proper Type
+//validateUpdateCabLocationReq ...
+func validateUpdateCabLocationReq(req *mycabsapi.UpdateCabLocationRequest) error {
+	if req.CabID == "" {
+		return errors.New("validateUpdateCabLocationReq: CabID cannot be Empty")
+	}
+	if !geo.ValidPoint(req.Lat, req.Lon) {
+		return errors.New("validateUpdateCabLocationReq: Invalid Lat/Lon")
+	}
+	return nil
+}
 
+//validateCabLocationsReq ...
+func validateCabLocationsReq(req *mycabsapi.CabLocationsRequest) error {
+	if len(req.Pings) == 0 {
+		return errors.New("validateCabLocationsReq: Pings cannot be Empty")
+	}
+	if len(req.Pings) > maxPingBatch {
+		return fmt.Errorf("validateCabLocationsReq: At most %v Pings per request", maxPingBatch)
+	}
+	return nil
+}
+
+//validateTripTrailReq ...
+func validateTripTrailReq(req *mycabsapi.TripTrailRequest) error {
+	if req.TripID == "" {
+		return errors.New("validateTripTrailReq: TripID cannot be Empty")
+	}
+	return nil
+}
+
+//validateRegisterRiderReq ...
+func validateRegisterRiderReq(req *mycabsapi.RegisterRiderRequest) error {
+	if req.Name == "" || req.Phone == "" {
+		return errors.New("validateRegisterRiderReq: Name/Phone cannot be Empty")
+	}
+	if !paymentMethods[req.DefaultPayment] {
+		return fmt.Errorf("validateRegisterRiderReq: Unknown DefaultPayment %q", req.DefaultPayment)
+	}
+	return nil
+}
+
+//validateRiderReq ...
+func validateRiderReq(req *mycabsapi.RiderRequest) error {
+	if req.RiderID == "" {
+		return errors.New("validateRiderReq: RiderID cannot be Empty")
+	}
+	return nil
+}
+
+//validateUpdateRiderReq ...
+func validateUpdateRiderReq(req *mycabsapi.UpdateRiderRequest) error {
+	if req.RiderID == "" {
+		return errors.New("validateUpdateRiderReq: RiderID cannot be Empty")
+	}
+	if req.DefaultPayment != "" && !paymentMethods[req.DefaultPayment] {
+		return fmt.Errorf("validateUpdateRiderReq: Unknown DefaultPayment %q", req.DefaultPayment)
+	}
+	return nil
+}
+
+//validateRiderTripsReq ...
+func validateRiderTripsReq(req *mycabsapi.RiderTripsRequest) error {
+	if req.RiderID == "" {
+		return errors.New("validateRiderTripsReq: RiderID cannot be Empty")
+	}
+	if req.State != "" && req.State != "active" && req.State != "past" {
+		return errors.New("validateRiderTripsReq: State must be active or past")
+	}
+	return nil
+}
+
+//validateRegisterDriverReq ...
+func validateRegisterDriverReq(req *mycabsapi.RegisterDriverRequest) error {
+	if req.Name == "" || req.Phone == "" || req.CityID == "" || req.LicenseNo == "" {
+		return errors.New("validateRegisterDriverReq: Name/Phone/CityID/LicenseNo cannot be Empty")
+	}
+	if _, err := time.Parse(licenseDateLayout, req.LicenseExpiry); err != nil {
+		return fmt.Errorf("validateRegisterDriverReq: LicenseExpiry must be YYYY-MM-DD. Err: %v", err)
+	}
+	return nil
+}
+
+//validateDriverReq ...
+func validateDriverReq(req *mycabsapi.DriverRequest) error {
+	if req.DriverID == "" {
+		return errors.New("validateDriverReq: DriverID cannot be Empty")
+	}
+	return nil
+}
+
+//validateClockInReq ...
+func validateClockInReq(req *mycabsapi.ClockInRequest) error {
+	if req.DriverID == "" || req.CabID == "" {
+		return errors.New("validateClockInReq: DriverID/CabID cannot be Empty")
+	}
+	return nil
+}
+
+//validateClockOutReq ...
+func validateClockOutReq(req *mycabsapi.ClockOutRequest) error {
+	if req.DriverID == "" {
+		return errors.New("validateClockOutReq: DriverID cannot be Empty")
+	}
+	return nil
+}
+
+//validateDriverReportReq ...
+func validateDriverReportReq(req *mycabsapi.DriverReportRequest) error {
+	if req.DriverID == "" {
+		return errors.New("validateDriverReportReq: DriverID cannot be Empty")
+	}
+	from, err := time.Parse(licenseDateLayout, req.From)
+	if err != nil {
+		return fmt.Errorf("validateDriverReportReq: From must be YYYY-MM-DD. Err: %v", err)
+	}
+	to, err := time.Parse(licenseDateLayout, req.To)
+	if err != nil {
+		return fmt.Errorf("validateDriverReportReq: To must be YYYY-MM-DD. Err: %v", err)
+	}
+	if to.Before(from) || to.Sub(from) > 92*24*time.Hour {
+		return errors.New("validateDriverReportReq: From/To must be in order and at most 92 days apart")
+	}
+	return nil
+}
+
+//validateRateDriverReq ...
+func validateRateDriverReq(req *mycabsapi.RateDriverRequest) error {
+	if req.TripID == "" || req.RiderID == "" {
+		return errors.New("validateRateDriverReq: TripID/RiderID cannot be Empty")
+	}
+	return validateStars(req.Stars, req.Comment)
+}
+
+//validateRateRiderReq ...
+func validateRateRiderReq(req *mycabsapi.RateRiderRequest) error {
+	if req.TripID == "" || req.DriverID == "" {
+		return errors.New("validateRateRiderReq: TripID/DriverID cannot be Empty")
+	}
+	return validateStars(req.Stars, req.Comment)
+}
+
+//validateStars ...
+func validateStars(stars int, comment string) error {
+	if stars < 1 || stars > 5 {
+		return errors.New("validateStars: Stars must be between 1 and 5")
+	}
+	if len(comment) > 500 {
+		return errors.New("validateStars: Comment cannot be longer than 500 bytes")
+	}
+	return nil
+}
+
+//validateCabRatingReq ...
+func validateCabRatingReq(req *mycabsapi.CabRatingRequest) error {
+	if req.CabID == "" {
+		return errors.New("validateCabRatingReq: CabID cannot be Empty")
+	}
+	return nil
+}
+
+//validateCityRatingReq ...
+func validateCityRatingReq(req *mycabsapi.CityRatingRequest) error {
+	if req.CityID == "" {
+		return errors.New("validateCityRatingReq: CityID cannot be Empty")
+	}
+	if req.Deprioritize < 0 || req.Deprioritize > 5 || req.Suspend < 0 || req.Suspend > 5 {
+		return errors.New("validateCityRatingReq: Thresholds must be between 0 and 5")
+	}
+	if req.Suspend > req.Deprioritize && req.Deprioritize != 0 {
+		return errors.New("validateCityRatingReq: Suspend cannot be above Deprioritize")
+	}
+	if req.MinRatings < 0 {
+		return errors.New("validateCityRatingReq: MinRatings cannot be negative")
+	}
+	return nil
+}
+
+//validateSettleTripReq ...
+func validateSettleTripReq(req *mycabsapi.SettleTripRequest) error {
+	if req.TripID == "" {
+		return errors.New("validateSettleTripReq: TripID cannot be Empty")
+	}
+	return nil
+}
+
+//validateRefundTripReq ...
+func validateRefundTripReq(req *mycabsapi.RefundTripRequest) error {
+	if req.TripID == "" {
+		return errors.New("validateRefundTripReq: TripID cannot be Empty")
+	}
+	if req.Amount < 0 {
+		return errors.New("validateRefundTripReq: Amount cannot be negative")
+	}
+	if strings.Contains(req.Key, "/") {
+		return errors.New("validateRefundTripReq: Key cannot have a /")
+	}
+	return nil
+}
+
+//validateBalanceReq ...
+func validateBalanceReq(req *mycabsapi.BalanceRequest) error {
+	if req.Account == ledger.CommissionAccount {
+		return nil
+	}
+	for _, prefix := range []string{"rider/", "driver/", "provider/"} {
+		if strings.HasPrefix(req.Account, prefix) && len(req.Account) > len(prefix) {
+			return nil
+		}
+	}
+	return fmt.Errorf("validateBalanceReq: Unknown account %v", req.Account)
+}
+
+//validateReceiptReq ...
+func validateReceiptReq(req *mycabsapi.ReceiptRequest) error {
+	if req.TripID == "" {
+		return errors.New("validateReceiptReq: TripID cannot be Empty")
+	}
+	return validateDocumentFormat(req.Format)
+}
+
+//validateInvoiceReq ...
+func validateInvoiceReq(req *mycabsapi.InvoiceRequest) error {
+	if req.RiderID == "" {
+		return errors.New("validateInvoiceReq: RiderID cannot be Empty")
+	}
+	if _, err := time.Parse(invoiceMonthLayout, req.Month); err != nil {
+		return fmt.Errorf("validateInvoiceReq: Month must be YYYY-MM. Err: %v", err)
+	}
+	return validateDocumentFormat(req.Format)
+}
+
+//validateDocumentFormat ...
+func validateDocumentFormat(format string) error {
+	switch format {
+	case "", "json", "html", "pdf":
+		return nil
+	}
+	return fmt.Errorf("validateDocumentFormat: Unknown format %v", format)
+}
+
+//validateCreatePromoReq ...
+func validateCreatePromoReq(req *mycabsapi.Promo) error {
+	if !promoCodePattern.MatchString(req.Code) {
+		return errors.New("validateCreatePromoReq: Code must be 3 to 20 letters or digits")
+	}
+	if (req.Percent > 0) == (req.Flat > 0) {
+		return errors.New("validateCreatePromoReq: Either Percent or Flat must be given")
+	}
+	if req.Percent < 0 || req.Percent > 100 || req.Flat < 0 || req.MaxOff < 0 {
+		return errors.New("validateCreatePromoReq: Percent must be up to 100, Flat/MaxOff cannot be Negative")
+	}
+	if req.MaxUses < 0 || req.MaxUsesPerRider < 0 {
+		return errors.New("validateCreatePromoReq: MaxUses/MaxUsesPerRider cannot be Negative")
+	}
+	validFrom, err := time.Parse(time.RFC3339, req.ValidFrom)
+	if err != nil {
+		return fmt.Errorf("validateCreatePromoReq: Invalid ValidFrom. Err: %v", err)
+	}
+	validTo, err := time.Parse(time.RFC3339, req.ValidTo)
+	if err != nil {
+		return fmt.Errorf("validateCreatePromoReq: Invalid ValidTo. Err: %v", err)
+	}
+	if !validTo.After(validFrom) {
+		return errors.New("validateCreatePromoReq: ValidTo must be after ValidFrom")
+	}
+	return nil
+}
+
+//validatePromoReq ...
+func validatePromoReq(req *mycabsapi.PromoRequest) error {
+	if req.Code == "" {
+		return errors.New("validatePromoReq: Code cannot be Empty")
+	}
+	return nil
+}
+
+//validateCreateCorporateReq ...
+func validateCreateCorporateReq(req *mycabsapi.CreateCorporateRequest) error {
+	if req.Name == "" {
+		return errors.New("validateCreateCorporateReq: Name cannot be Empty")
+	}
+	if req.Policy != nil {
+		return validateCorporatePolicy(req.Policy)
+	}
+	return nil
+}
+
+//validateCorporateReq ...
+func validateCorporateReq(req *mycabsapi.CorporateRequest) error {
+	if req.CorporateID == "" {
+		return errors.New("validateCorporateReq: CorporateID cannot be Empty")
+	}
+	return nil
+}
+
+//validateSetCorporatePolicyReq ...
+func validateSetCorporatePolicyReq(req *mycabsapi.SetCorporatePolicyRequest) error {
+	if req.CorporateID == "" || req.Policy == nil {
+		return errors.New("validateSetCorporatePolicyReq: CorporateID/Policy cannot be Empty")
+	}
+	return validateCorporatePolicy(req.Policy)
+}
+
+//validateCorporatePolicy ...
+func validateCorporatePolicy(policy *mycabsapi.CorporatePolicy) error {
+	if policy.FromHour < 0 || policy.FromHour > 23 || policy.ToHour < 0 || policy.ToHour > 23 {
+		return errors.New("validateCorporatePolicy: FromHour/ToHour must be from 0 to 23")
+	}
+	if _, err := time.LoadLocation(policy.TimeZone); err != nil {
+		return fmt.Errorf("validateCorporatePolicy: Unknown TimeZone %q", policy.TimeZone)
+	}
+	if policy.MonthlyCap < 0 {
+		return errors.New("validateCorporatePolicy: MonthlyCap cannot be Negative")
+	}
+	return nil
+}
+
+//validateCorporateMemberReq ...
+func validateCorporateMemberReq(req *mycabsapi.CorporateMemberRequest) error {
+	if req.CorporateID == "" || req.RiderID == "" {
+		return errors.New("validateCorporateMemberReq: CorporateID/RiderID cannot be Empty")
+	}
+	return nil
+}
+
+//validateStatementReq ...
+func validateStatementReq(req *mycabsapi.StatementRequest) error {
+	if req.CorporateID == "" {
+		return errors.New("validateStatementReq: CorporateID cannot be Empty")
+	}
+	if _, err := time.Parse(invoiceMonthLayout, req.Month); err != nil {
+		return fmt.Errorf("validateStatementReq: Month must be YYYY-MM. Err: %v", err)
+	}
+	return validateDocumentFormat(req.Format)
+}
+
+//validateSetCabTypeReq ...
+func validateSetCabTypeReq(req *mycabsapi.CabType) error {
+	if !cabTypePattern.MatchString(req.Name) {
+		return errors.New("validateSetCabTypeReq: Name must be lower case letters, digits or '-'")
+	}
+	if req.Seats <= 0 {
+		return errors.New("validateSetCabTypeReq: Seats must be Positive")
+	}
+	if req.Luggage < 0 || req.Class < 0 {
+		return errors.New("validateSetCabTypeReq: Luggage/Class cannot be Negative")
+	}
+	return nil
+}
+
+//validateCabTypeReq ...
+func validateCabTypeReq(req *mycabsapi.CabTypeRequest) error {
+	if req.Name == "" {
+		return errors.New("validateCabTypeReq: Name cannot be Empty")
+	}
+	req.Name = strings.ToLower(req.Name)
+	return nil
+}
+
+//validateListCabTypesReq ...
+func validateListCabTypesReq(req *mycabsapi.ListCabTypesRequest) error {
+	if req.Seats < 0 || req.Luggage < 0 {
+		return errors.New("validateListCabTypesReq: Seats/Luggage cannot be Negative")
+	}
 	return nil
 }
 
@@ -56,6 +746,135 @@ func validateEndTripReq(req *mycabsapi.EndTripRequest) error {
 	if req.CabID == "" {
 		return errors.New("validateEndTripReq: CabID cannot be Empty")
 	}
+	if req.Distance < 0 {
+		return errors.New("validateEndTripReq: Distance cannot be Negative")
+	}
+	return nil
+}
+
+//validateTripReq ...
+func validateTripReq(req *mycabsapi.TripRequest) error {
+	if req.TripID == "" {
+		return errors.New("validateTripReq: TripID cannot be Empty")
+	}
+	return nil
+}
+
+//validateSetTariffReq ...
+func validateSetTariffReq(req *mycabsapi.Tariff) error {
+	if req.CityID == "" || req.CabType == "" || req.Currency == "" {
+		return errors.New("validateSetTariffReq: CityID/CabType/Currency cannot be Empty")
+	}
+	req.CabType = strings.ToLower(req.CabType)
+	if req.BaseFare < 0 || req.PerKm < 0 || req.PerMinute < 0 || req.MinimumFare < 0 || req.InterCity < 0 || req.CancelFee < 0 {
+		return errors.New("validateSetTariffReq: Amounts cannot be Negative")
+	}
+	for cityID, surcharge := range req.InterCityTo {
+		if cityID == "" || surcharge < 0 {
+			return fmt.Errorf("validateSetTariffReq: Invalid InterCityTo surcharge %v for %q", surcharge, cityID)
+		}
+	}
+	if req.TaxRate < 0 || req.TaxRate >= 1 || (req.TaxRate > 0 && req.TaxName == "") {
+		return errors.New("validateSetTariffReq: TaxRate must be between 0 and 1, with a TaxName")
+	}
+	return nil
+}
+
+//validateTariffReq ...
+func validateTariffReq(req *mycabsapi.TariffRequest) error {
+	if req.CityID == "" || req.CabType == "" {
+		return errors.New("validateTariffReq: CityID/CabType cannot be Empty")
+	}
+	req.CabType = strings.ToLower(req.CabType)
+	return nil
+}
+
+//validateSurgeReq ...
+func validateSurgeReq(req *mycabsapi.SurgeRequest) error {
+	if req.CityID == "" || req.CabType == "" {
+		return errors.New("validateSurgeReq: CityID/CabType cannot be Empty")
+	}
+	return nil
+}
+
+//validateCitySurgeReq ...
+func validateCitySurgeReq(req *mycabsapi.CitySurgeRequest) error {
+	if req.CityID == "" {
+		return errors.New("validateCitySurgeReq: CityID cannot be Empty")
+	}
+	if req.Cap < 1 || req.Cap > maxSurgeCap {
+		return fmt.Errorf("validateCitySurgeReq: Cap must be between 1 and %v", maxSurgeCap)
+	}
+	return nil
+}
+
+//validateFareEstimateReq ...
+func validateFareEstimateReq(req *mycabsapi.FareEstimateRequest) error {
+	if req.From == "" || req.To == "" || req.CabType == "" {
+		return errors.New("validateFareEstimateReq: From/To/Type cannot be Empty")
+	}
+	req.CabType = strings.ToLower(req.CabType)
+	if req.Distance < 0 || req.Duration < 0 {
+		return errors.New("validateFareEstimateReq: Distance/Duration cannot be Negative")
+	}
+	if req.Distance == 0 && (req.Pickup == nil || req.Drop == nil) {
+		return errors.New("validateFareEstimateReq: Distance or Pickup and Drop needed")
+	}
+	if req.Pickup != nil && !geo.ValidPoint(req.Pickup.Lat, req.Pickup.Lon) {
+		return errors.New("validateFareEstimateReq: Invalid Pickup")
+	}
+	if req.Drop != nil && !geo.ValidPoint(req.Drop.Lat, req.Drop.Lon) {
+		return errors.New("validateFareEstimateReq: Invalid Drop")
+	}
+	return nil
+}
+
+//validateCabArrivingReq ...
+func validateCabArrivingReq(req *mycabsapi.CabArrivingRequest) error {
+	if req.CabID == "" {
+		return errors.New("validateCabArrivingReq: CabID cannot be Empty")
+	}
+	return nil
+}
+
+//validatePickupReq ...
+func validatePickupReq(req *mycabsapi.PickupRequest) error {
+	if req.CabID == "" {
+		return errors.New("validatePickupReq: CabID cannot be Empty")
+	}
+	return nil
+}
+
+//validateMaintenanceReq ...
+func validateMaintenanceReq(req *mycabsapi.MaintenanceRequest) error {
+	if req.CabID == "" {
+		return errors.New("validateMaintenanceReq: CabID cannot be Empty")
+	}
+	return nil
+}
+
+//validateReturnFromMaintenanceReq ...
+func validateReturnFromMaintenanceReq(req *mycabsapi.ReturnFromMaintenanceRequest) error {
+	if req.CabID == "" {
+		return errors.New("validateReturnFromMaintenanceReq: CabID cannot be Empty")
+	}
+	if req.State == "" {
+		req.State = stateIdle
+	}
+	if req.State != stateIdle && req.State != stateInActive && req.State != stateRetired {
+		return errors.New("validateReturnFromMaintenanceReq: State must be IDLE, IN_ACTIVE or RETIRED")
+	}
+	return nil
+}
+
+//validateCancelTripReq ...
+func validateCancelTripReq(req *mycabsapi.CancelTripRequest) error {
+	if req.CabID == "" {
+		return errors.New("validateCancelTripReq: CabID cannot be Empty")
+	}
+	if req.Party != partyRider && req.Party != partyDriver {
+		return errors.New("validateCancelTripReq: Party must be rider or driver")
+	}
 	return nil
 }
 
@@ -82,3 +901,14 @@ func validateChangeCityReq(req *mycabsapi.ChangeCityRequest) error {
 	}
 	return nil
 }
+
+//validateCityDispatchReq ...
+func validateCityDispatchReq(req *mycabsapi.CityDispatchRequest) error {
+	if req.CityID == "" || req.Strategy == "" {
+		return errors.New("validateCityDispatchReq: CityID/Strategy cannot be Empty")
+	}
+	if _, ok := dispatchStrategies[req.Strategy]; !ok {
+		return fmt.Errorf("validateCityDispatchReq: Unknown Strategy %v", req.Strategy)
+	}
+	return nil
+}
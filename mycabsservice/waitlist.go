@@ -0,0 +1,324 @@
+package mycabsservice
+
+import (
+	"fmt"
+	"mycabs/db"
+	"mycabs/mycabsapi"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+//A booking which finds no idle cab is queued on the waitlist of its city and
+//cab type. Every ticket has a record under hkeyValTickets, and while it waits
+//an entry in the waitlist partition of the city and cab type, keyed so that
+//the oldest ticket comes first. Whoever deletes the waitlist entry owns the
+//ticket: the cab which serves it or the rider who cancels it.
+
+const (
+	hkeyValTickets  = "tickets/"
+	hkeyValWaitlist = "waitlist/"
+)
+
+const (
+	ticketWaiting   = "WAITING"
+	ticketAssigned  = "ASSIGNED"
+	ticketCancelled = "CANCELLED"
+)
+
+//JoinWaitlist queues the booking request and returns its ticket ID.
+func JoinWaitlist(req *mycabsapi.BookingRequest) (ticketID string, err error) {
+	_, err = checkPromo(req.PromoCode, req.RiderID, req.From, req.CabType, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	seq, err := db.Increment(tableName, counterKeys("ticket"), "Counter", 1)
+	if err != nil {
+		fmt.Printf("JoinWaitlist: db.Increment Failed. Err: %v\n", err)
+		return "", err
+	}
+	ticketID = "ticket_" + strconv.Itoa(seq)
+	queueKey := fmt.Sprintf("%012d", seq)
+
+	err = claimRider(req.RiderID, ticketID)
+	if err != nil {
+		return "", err
+	}
+
+	ticketRecord := make(map[string]*dynamodb.AttributeValue)
+	ticketRecord[db.HKeyName] = db.StrToAttr(hkeyValTickets)
+	ticketRecord[db.RKeyName] = db.StrToAttr(ticketID)
+	ticketRecord["Id"] = db.StrToAttr(ticketID)
+	ticketRecord["State"] = db.StrToAttr(ticketWaiting)
+	ticketRecord["RiderID"] = db.StrToAttr(req.RiderID)
+	ticketRecord["From"] = db.StrToAttr(req.From)
+	ticketRecord["To"] = db.StrToAttr(req.To)
+	ticketRecord["CabType"] = db.StrToAttr(req.CabType)
+	ticketRecord["QueueKey"] = db.StrToAttr(queueKey)
+	ticketRecord["CreatedAt"] = db.Num64ToAttr(time.Now().Unix())
+	for attr, attrVal := range pickupAttrs(req.Pickup) {
+		ticketRecord[attr] = attrVal
+	}
+	for attr, attrVal := range promoAttrs(req.PromoCode) {
+		ticketRecord[attr] = attrVal
+	}
+	for attr, attrVal := range corporateAttrs(req.CorporateID) {
+		ticketRecord[attr] = attrVal
+	}
+
+	queueRecord := map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(waitlistHKey(req.From, req.CabType)),
+		db.RKeyName: db.StrToAttr(queueKey),
+		"TicketID":  db.StrToAttr(ticketID),
+	}
+
+	//The ticket and its waitlist entry are written together, a ticket never
+	//waits without being queued.
+	err = db.Transact(tableName, []*db.TxWrite{
+		{Put: ticketRecord, New: true},
+		{Put: queueRecord, New: true},
+	})
+	if err != nil {
+		fmt.Printf("JoinWaitlist: db.Transact of ticket and waitlist entry Failed. Err: %v\n", err)
+		releaseRider(req.RiderID, ticketID)
+		return "", err
+	}
+
+	return ticketID, nil
+}
+
+//BookingTicket ...
+func BookingTicket(req *mycabsapi.BookingTicketRequest) (*mycabsapi.BookingTicketResponse, error) {
+	ticketRec, err := loadTicket(req.TicketID)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket := &mycabsapi.BookingTicketResponse{
+		TicketID: req.TicketID,
+		State:    db.AttrToStr(ticketRec["State"]),
+	}
+	if attrVal, ok := ticketRec["CabID"]; ok {
+		ticket.CabID = db.AttrToStr(attrVal)
+		ticket.CabName = db.AttrToStr(ticketRec["CabName"])
+		ticket.TripID = db.AttrToStr(ticketRec["TripID"])
+	}
+	if attrVal, ok := ticketRec["Reason"]; ok {
+		ticket.Reason = db.AttrToStr(attrVal)
+	}
+	return ticket, nil
+}
+
+//CancelBookingTicket takes a waiting ticket off the waitlist.
+func CancelBookingTicket(req *mycabsapi.BookingTicketRequest) error {
+	ticketRec, err := loadTicket(req.TicketID)
+	if err != nil {
+		return err
+	}
+	state := db.AttrToStr(ticketRec["State"])
+	if state != ticketWaiting {
+		return &invalidTransitionError{from: state, to: ticketCancelled}
+	}
+
+	queueKeys := map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(waitlistHKey(db.AttrToStr(ticketRec["From"]), db.AttrToStr(ticketRec["CabType"]))),
+		db.RKeyName: ticketRec["QueueKey"],
+	}
+	cond := map[string]*dynamodb.AttributeValue{
+		"TicketID": db.StrToAttr(req.TicketID),
+	}
+	err = db.Delete(tableName, queueKeys, cond)
+	if db.IsConditionFailed(err) {
+		//A cab has picked the ticket meanwhile.
+		return &invalidTransitionError{from: ticketAssigned, to: ticketCancelled}
+	}
+	if err != nil {
+		fmt.Printf("CancelBookingTicket: db.Delete Failed. Err: %v\n", err)
+		return err
+	}
+
+	updateInfo := map[string]*dynamodb.AttributeValue{
+		"State": db.StrToAttr(ticketCancelled),
+	}
+	err = db.Update(tableName, ticketKeys(req.TicketID), updateInfo)
+	if err != nil {
+		return err
+	}
+	releaseRider(ticketRiderID(ticketRec), req.TicketID)
+	return nil
+}
+
+//serveWaitlist offers a cab which just became IDLE to the oldest ticket
+//waiting in its city for its cab type.
+func serveWaitlist(cabRec map[string]*dynamodb.AttributeValue) {
+	if cabDriverID(cabRec) == "" {
+		//Nobody to drive it, the next driver clocking in serves the waitlist.
+		return
+	}
+	cityID := db.AttrToStr(cabRec["CityID"])
+	cabType := db.AttrToStr(cabRec["Type"])
+	rating, count := attrsToRating(cabRec)
+	if cityRatingPolicy(cityID).status(rating, count) == ratingSuspended {
+		return
+	}
+
+	queueRecords, err := db.Query(tableName, waitlistHKey(cityID, cabType), nil)
+	if err != nil {
+		fmt.Printf("serveWaitlist: db.Query Failed. Err: %v\n", err)
+		return
+	}
+
+	for _, queueRec := range queueRecords {
+		ticketID := db.AttrToStr(queueRec["TicketID"])
+		queueKeys := map[string]*dynamodb.AttributeValue{
+			db.HKeyName: queueRec[db.HKeyName],
+			db.RKeyName: queueRec[db.RKeyName],
+		}
+		cond := map[string]*dynamodb.AttributeValue{
+			"TicketID": queueRec["TicketID"],
+		}
+		err = db.Delete(tableName, queueKeys, cond)
+		if db.IsConditionFailed(err) {
+			//Claimed by another cab or cancelled, go for the next one.
+			continue
+		}
+		if err != nil {
+			//Not known to be claimed, leave it to the next cab rather than risk
+			//serving it twice.
+			fmt.Printf("serveWaitlist: db.Delete of the entry of %v Failed. Err: %v\n", ticketID, err)
+			return
+		}
+
+		ticketRec, err := loadTicket(ticketID)
+		if err != nil {
+			fmt.Printf("serveWaitlist: loadTicket of %v Failed. Err: %v\n", ticketID, err)
+			continue
+		}
+		if state := db.AttrToStr(ticketRec["State"]); state != ticketWaiting {
+			//A stale entry of a ticket served or cancelled already.
+			fmt.Printf("serveWaitlist: Ticket %v is %v, skipping it\n", ticketID, state)
+			continue
+		}
+
+		req := &mycabsapi.BookingRequest{
+			RiderID:     ticketRiderID(ticketRec),
+			From:        db.AttrToStr(ticketRec["From"]),
+			To:          db.AttrToStr(ticketRec["To"]),
+			CabType:     db.AttrToStr(ticketRec["CabType"]),
+			Pickup:      attrsToPickup(ticketRec),
+			PromoCode:   attrsToPromoCode(ticketRec),
+			CorporateID: attrsToCorporateID(ticketRec),
+		}
+		candidate := newCandidateCab(cabRec, time.Now())
+		tripID := ""
+		//The city may be closed by now, or the policy may not allow the
+		//booking any more.
+		err = checkWaitlistedBooking(req, time.Now())
+		if err == nil {
+			tripID, err = assignCab(req, candidate)
+		}
+		if _, rejected := err.(*rejectedError); rejected {
+			cancelTicket(ticketID, req.RiderID, err.Error())
+			continue
+		}
+		if err != nil {
+			//The cab is gone or the booking could not be checked, put the
+			//ticket back in its place for the next cab.
+			fmt.Printf("serveWaitlist: assignCab of %v Failed. Err: %v\n", candidate.ID, err)
+			err = db.Put(tableName, queueRec)
+			if err != nil {
+				fmt.Printf("serveWaitlist: Requeue of %v Failed. Err: %v\n", ticketID, err)
+			}
+			return
+		}
+
+		updateInfo := map[string]*dynamodb.AttributeValue{
+			"State":   db.StrToAttr(ticketAssigned),
+			"CabID":   db.StrToAttr(candidate.ID),
+			"CabName": db.StrToAttr(candidate.Name),
+			"TripID":  db.StrToAttr(tripID),
+		}
+		stateCond := map[string]*dynamodb.AttributeValue{
+			"State": db.StrToAttr(ticketWaiting),
+		}
+		err = db.UpdateExclusive(tableName, ticketKeys(ticketID), updateInfo, stateCond)
+		if err != nil {
+			fmt.Printf("serveWaitlist: db.UpdateExclusive of ticket %v Failed. Err: %v\n", ticketID, err)
+		}
+		if req.RiderID != "" {
+			handOverRider(req.RiderID, ticketID, tripID)
+		}
+		fmt.Printf("serveWaitlist: Ticket %v served by %v\n", ticketID, candidate.ID)
+		return
+	}
+}
+
+//checkWaitlistedBooking makes the checks of BookCab again for a ticket which
+//has waited for a cab.
+func checkWaitlistedBooking(req *mycabsapi.BookingRequest, at time.Time) error {
+	err := checkTripCities(req.From, req.To)
+	if err != nil {
+		return err
+	}
+	_, err = checkService(req.From, req.Pickup, at)
+	if err != nil || req.CorporateID == "" {
+		return err
+	}
+	return checkCorporateBooking(req, at)
+}
+
+//cancelTicket cancels a waiting ticket which can't be served, its waitlist
+//entry being gone already.
+func cancelTicket(ticketID, riderID, reason string) {
+	fmt.Printf("serveWaitlist: Cancelling ticket %v: %v\n", ticketID, reason)
+	updateInfo := map[string]*dynamodb.AttributeValue{
+		"State":  db.StrToAttr(ticketCancelled),
+		"Reason": db.StrToAttr(reason),
+	}
+	stateCond := map[string]*dynamodb.AttributeValue{
+		"State": db.StrToAttr(ticketWaiting),
+	}
+	err := db.UpdateExclusive(tableName, ticketKeys(ticketID), updateInfo, stateCond)
+	if err != nil {
+		fmt.Printf("cancelTicket: db.UpdateExclusive of %v Failed. Err: %v\n", ticketID, err)
+		return
+	}
+	releaseRider(riderID, ticketID)
+}
+
+//loadTicket ...
+func loadTicket(ticketID string) (map[string]*dynamodb.AttributeValue, error) {
+	ticketRec, err := db.Get(tableName, ticketKeys(ticketID))
+	if err != nil {
+		fmt.Printf("loadTicket: db.Get Failed. Err: %v\n", err)
+		return nil, err
+	}
+	if len(ticketRec) == 0 {
+		return nil, &notFoundError{kind: "ticket", id: ticketID}
+	}
+	return ticketRec, nil
+}
+
+//ticketRiderID is the rider of the ticket, empty for tickets from before riders.
+func ticketRiderID(ticketRec map[string]*dynamodb.AttributeValue) string {
+	if attrVal, ok := ticketRec["RiderID"]; ok {
+		return db.AttrToStr(attrVal)
+	}
+	return ""
+}
+
+//ticketKeys ...
+func ticketKeys(ticketID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		db.HKeyName: db.StrToAttr(hkeyValTickets),
+		db.RKeyName: db.StrToAttr(ticketID),
+	}
+}
+
+//waitlistHKey is the partition holding the waiting tickets of a city and cab type.
+func waitlistHKey(cityID, cabType string) string {
+	return hkeyValWaitlist + strings.Join([]string{cityID, cabType}, "/") + "/"
+}
@@ -0,0 +1,141 @@
+/*
+ * package rebalance plans moves of spare cabs from the cities which can spare
+ * them to the cities short of cabs for their recent bookings (demand).
+ */
+
+package rebalance
+
+import (
+	"math"
+	"sort"
+)
+
+//Cell is a cab type in a city.
+type Cell struct {
+	CityID  string
+	CabType string
+	Demand  int      //Bookings in the window.
+	Supply  int      //Cabs taking bookings.
+	Spare   []string //Cabs not taking bookings, which can be moved.
+}
+
+//Params ...
+type Params struct {
+	Ratio    float64 //Cabs wanted per booking in the window.
+	MaxMoves int     //Most cabs moved by a plan.
+}
+
+//Default ...
+var Default = Params{
+	Ratio:    1,
+	MaxMoves: 20,
+}
+
+//Move ...
+type Move struct {
+	CabID   string
+	CabType string
+	From    string
+	To      string
+}
+
+//Impact is what a plan changes in a cell. Cabs moved in are expected to take
+//bookings once there.
+type Impact struct {
+	CityID        string
+	CabType       string
+	Demand        int
+	Supply        int
+	Wanted        int
+	Shortage      int
+	In            int
+	Out           int
+	ShortageAfter int
+}
+
+//Plan moves spare cabs one at a time to the cell with the largest shortage,
+//from the cell of the same cab type with the most cabs over what it wants.
+//Cells short of cabs don't give theirs away.
+func (p *Params) Plan(cells []*Cell) ([]*Move, []*Impact) {
+	impacts := make([]*Impact, len(cells))
+	spare := make([][]string, len(cells))
+	for idx, cell := range cells {
+		wanted := int(math.Ceil(float64(cell.Demand) * p.Ratio))
+		impacts[idx] = &Impact{
+			CityID:   cell.CityID,
+			CabType:  cell.CabType,
+			Demand:   cell.Demand,
+			Supply:   cell.Supply,
+			Wanted:   wanted,
+			Shortage: shortage(wanted, cell.Supply),
+		}
+		if impacts[idx].Shortage == 0 {
+			spare[idx] = cell.Spare
+		}
+	}
+
+	//Sources first by surplus, then by city for a stable plan.
+	sources := make([]int, len(cells))
+	for idx := range sources {
+		sources[idx] = idx
+	}
+	sort.SliceStable(sources, func(i, j int) bool {
+		a, b := impacts[sources[i]], impacts[sources[j]]
+		if a.Supply-a.Wanted != b.Supply-b.Wanted {
+			return a.Supply-a.Wanted > b.Supply-b.Wanted
+		}
+		return a.CityID < b.CityID
+	})
+
+	moves := []*Move{}
+	for len(moves) < p.MaxMoves {
+		to, from := -1, -1
+		for idx, impact := range impacts {
+			if impact.ShortageAfter = shortage(impact.Wanted, impact.Supply+impact.In); impact.ShortageAfter == 0 {
+				continue
+			}
+			source := p.source(cells[idx].CabType, cells, sources, spare)
+			if source < 0 {
+				continue
+			}
+			if to < 0 || impact.ShortageAfter > impacts[to].ShortageAfter ||
+				impact.ShortageAfter == impacts[to].ShortageAfter && impact.CityID < impacts[to].CityID {
+				to, from = idx, source
+			}
+		}
+		if to < 0 {
+			break
+		}
+
+		moves = append(moves, &Move{
+			CabID:   spare[from][0],
+			CabType: cells[to].CabType,
+			From:    cells[from].CityID,
+			To:      cells[to].CityID,
+		})
+		spare[from] = spare[from][1:]
+		impacts[from].Out++
+		impacts[to].In++
+	}
+	for _, impact := range impacts {
+		impact.ShortageAfter = shortage(impact.Wanted, impact.Supply+impact.In)
+	}
+	return moves, impacts
+}
+
+//source is the first of sources with a spare cab of the type, -1 if none has.
+func (p *Params) source(cabType string, cells []*Cell, sources []int, spare [][]string) int {
+	for _, idx := range sources {
+		if cells[idx].CabType == cabType && len(spare[idx]) > 0 {
+			return idx
+		}
+	}
+	return -1
+}
+
+func shortage(wanted, supply int) int {
+	if wanted > supply {
+		return wanted - supply
+	}
+	return 0
+}
@@ -0,0 +1,67 @@
+package rebalance
+
+import (
+	"testing"
+)
+
+func TestPlan(t *testing.T) {
+	t.Log("TestPlan")
+
+	p := Default
+	cells := []*Cell{
+		{CityID: "city_1", CabType: "sedan", Demand: 5, Supply: 1},
+		{CityID: "city_2", CabType: "sedan", Demand: 0, Supply: 3, Spare: []string{"cab_1", "cab_2"}},
+		{CityID: "city_3", CabType: "sedan", Demand: 1, Supply: 1, Spare: []string{"cab_3"}},
+		{CityID: "city_3", CabType: "suv", Demand: 0, Supply: 0, Spare: []string{"cab_4"}},
+	}
+	moves, impacts := p.Plan(cells)
+
+	//city_1 wants 4 more sedans, city_2 has the most surplus, then city_3. The suv stays.
+	expected := []string{"cab_1", "cab_2", "cab_3"}
+	if len(moves) != len(expected) {
+		t.Fatalf("TestPlan Expected %v moves. Actual: %v", len(expected), len(moves))
+		return
+	}
+	for idx, move := range moves {
+		if move.CabID != expected[idx] || move.To != "city_1" {
+			t.Fatalf("TestPlan Expected %v to city_1. Actual: %v to %v", expected[idx], move.CabID, move.To)
+			return
+		}
+	}
+	if impacts[0].Shortage != 4 || impacts[0].In != 3 || impacts[0].ShortageAfter != 1 {
+		t.Fatalf("TestPlan Expected city_1 short of 4, 3 in, short of 1 after. Actual: %+v", impacts[0])
+		return
+	}
+	if impacts[1].Out != 2 || impacts[2].Out != 1 || impacts[3].Out != 0 {
+		t.Fatalf("TestPlan Unexpected moves out: %v %v %v", impacts[1].Out, impacts[2].Out, impacts[3].Out)
+		return
+	}
+}
+
+func TestPlanLimits(t *testing.T) {
+	t.Log("TestPlanLimits")
+
+	p := Params{Ratio: 1, MaxMoves: 2}
+	cells := []*Cell{
+		{CityID: "city_1", CabType: "sedan", Demand: 3, Supply: 0, Spare: []string{"cab_1"}},
+		{CityID: "city_2", CabType: "sedan", Demand: 2, Supply: 0},
+		{CityID: "city_3", CabType: "sedan", Demand: 0, Supply: 0, Spare: []string{"cab_2", "cab_3", "cab_4"}},
+	}
+	moves, _ := p.Plan(cells)
+
+	//At most 2 moves, the largest shortage first, and city_1 keeps its spare cab.
+	if len(moves) != 2 {
+		t.Fatalf("TestPlanLimits Expected 2 moves. Actual: %v", len(moves))
+		return
+	}
+	if moves[0].To != "city_1" || moves[1].To != "city_1" && moves[1].To != "city_2" {
+		t.Fatalf("TestPlanLimits Unexpected targets: %v, %v", moves[0].To, moves[1].To)
+		return
+	}
+	for _, move := range moves {
+		if move.From != "city_3" {
+			t.Fatalf("TestPlanLimits Expected moves from city_3. Actual: %v", move.From)
+			return
+		}
+	}
+}
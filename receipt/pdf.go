@@ -0,0 +1,90 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+//A4 page in points, with the text in Courier so that the columns line up.
+const (
+	pageWidth    = 595
+	pageHeight   = 842
+	margin       = 50
+	fontSize     = 10
+	lineHeight   = 14
+	linesPerPage = (pageHeight - 2*margin) / lineHeight
+)
+
+//textPDF lays out the lines on as many pages as needed. Only the characters
+//of the standard fonts are kept, others are printed as '?'.
+func textPDF(lines []string) []byte {
+	var pages [][]string
+	for len(lines) > linesPerPage {
+		pages = append(pages, lines[:linesPerPage])
+		lines = lines[linesPerPage:]
+	}
+	pages = append(pages, lines)
+
+	//Objects: 1 catalog, 2 page tree, 3 font, then a page and its content per page.
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier >>",
+	}
+	kids := make([]string, 0, len(pages))
+	for _, pageLines := range pages {
+		pageObj := len(objects) + 1
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageObj))
+		content := pageContent(pageLines)
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+				pageWidth, pageHeight, pageObj+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for idx, obj := range objects {
+		offsets[idx] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", idx+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+//pageContent is the content stream writing the lines from the top of the page.
+func pageContent(lines []string) string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", fontSize, lineHeight, margin, pageHeight-margin)
+	for _, line := range lines {
+		fmt.Fprintf(&buf, "(%s) '\n", pdfString(line))
+	}
+	buf.WriteString("ET")
+	return buf.String()
+}
+
+//pdfString escapes the line for a PDF string literal.
+func pdfString(line string) string {
+	var buf strings.Builder
+	for _, r := range line {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			buf.WriteRune('\\')
+			buf.WriteRune(r)
+		case r < 32 || r > 126:
+			buf.WriteRune('?')
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	return buf.String()
+}
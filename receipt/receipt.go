@@ -0,0 +1,197 @@
+/*
+ * package receipt renders the receipts of trips, the monthly invoices of
+ * riders and statements of corporate accounts as printable HTML pages and PDF
+ * documents.
+ */
+
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"mycabs/mycabsapi"
+	"sort"
+	"strings"
+)
+
+//Money formats an amount in minor units, ex: 20600 INR is "206.00 INR".
+func Money(amount int64, currency string) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return fmt.Sprintf("%v%d.%02d %v", sign, amount/100, amount%100, currency)
+}
+
+//Lines are the text lines of the receipt, the same on the page and in the PDF.
+func Lines(r *mycabsapi.Receipt) []string {
+	lines := []string{
+		"Receipt " + r.ReceiptNo,
+		"",
+		"Trip:      " + r.TripID,
+		"Rider:     " + strings.TrimSpace(r.RiderName+" ("+r.RiderID+")"),
+		"Cab:       " + r.CabName + " (" + r.CabType + ")",
+		"From:      " + r.FromName,
+		"To:        " + r.ToName,
+		"Booked:    " + r.BookedAt,
+		"Picked up: " + r.PickedUpAt,
+		"Ended:     " + r.EndedAt,
+		fmt.Sprintf("Distance:  %.1f km", r.Distance/1000),
+		fmt.Sprintf("Duration:  %d min", r.Duration/60),
+		"",
+	}
+	if r.Fare == nil {
+		return append(lines, "No fare was charged for this trip.")
+	}
+	f := r.Fare
+	charge := func(name string, amount int64) {
+		if amount != 0 {
+			lines = append(lines, fmt.Sprintf("%-22v %16v", name, Money(amount, f.Currency)))
+		}
+	}
+	charge("Base fare", f.Base)
+	charge("Distance", f.Distance)
+	charge("Time", f.Time)
+	charge("Minimum fare", f.Minimum)
+	if f.Surge != 0 {
+		charge(fmt.Sprintf("Surge (x%.1f)", f.Multiplier), f.Surge)
+	}
+	charge("Inter-city surcharge", f.InterCity)
+	charge("Promo "+f.PromoCode, -f.Discount)
+	lines = append(lines, strings.Repeat("-", 39))
+	charge("Total", f.Total)
+	if r.Tax != 0 {
+		charge(fmt.Sprintf("incl. %v %.1f%%", r.TaxName, r.TaxRate*100), r.Tax)
+	}
+	charge("Refunded", -r.Refunded)
+	return lines
+}
+
+//InvoiceLines are the text lines of the invoice.
+func InvoiceLines(inv *mycabsapi.Invoice) []string {
+	lines := []string{
+		"Invoice " + inv.InvoiceNo,
+		"",
+		"Rider:  " + strings.TrimSpace(inv.RiderName+" ("+inv.RiderID+")"),
+		"Month:  " + inv.Month,
+		"",
+	}
+	for _, r := range inv.Receipts {
+		total := "-"
+		if r.Fare != nil {
+			total = Money(r.Fare.Total-r.Refunded, r.Fare.Currency)
+		}
+		lines = append(lines, fmt.Sprintf("%-12v %-25v %-20v %16v", r.TripID, r.EndedAt, r.FromName+" - "+r.ToName, total))
+	}
+	return append(lines, totalLines(inv.Totals)...)
+}
+
+//StatementLines are the text lines of the statement of a corporate account.
+func StatementLines(stmt *mycabsapi.Statement) []string {
+	lines := []string{
+		"Statement " + stmt.StatementNo,
+		"",
+		"Account: " + strings.TrimSpace(stmt.CorporateName+" ("+stmt.CorporateID+")"),
+		"Month:   " + stmt.Month,
+		"",
+	}
+	for _, r := range stmt.Receipts {
+		total := "-"
+		if r.Fare != nil {
+			total = Money(r.Fare.Total-r.Refunded, r.Fare.Currency)
+		}
+		lines = append(lines, fmt.Sprintf("%-12v %-16v %-25v %-20v %16v", r.TripID, r.RiderName, r.EndedAt, r.FromName+" - "+r.ToName, total))
+	}
+	lines = append(lines, "", "Members:")
+	for _, m := range stmt.Members {
+		currencies := make([]string, 0, len(m.Due))
+		for currency := range m.Due {
+			currencies = append(currencies, currency)
+		}
+		sort.Strings(currencies)
+		due := make([]string, 0, len(currencies))
+		for _, currency := range currencies {
+			due = append(due, Money(m.Due[currency], currency))
+		}
+		lines = append(lines, fmt.Sprintf("  %-30v %4d trips  %v", strings.TrimSpace(m.RiderName+" ("+m.RiderID+")"), m.Trips, strings.Join(due, ", ")))
+	}
+	return append(lines, totalLines(stmt.Totals)...)
+}
+
+//totalLines are the lines of the totals per currency closing an invoice or statement.
+func totalLines(totals []*mycabsapi.InvoiceTotal) []string {
+	lines := []string{""}
+	for _, t := range totals {
+		lines = append(lines,
+			fmt.Sprintf("%v trips: %v", t.Currency, t.Trips),
+			fmt.Sprintf("  Fares     %16v", Money(t.Fare, t.Currency)),
+			fmt.Sprintf("  incl. tax %16v", Money(t.Tax, t.Currency)),
+			fmt.Sprintf("  Refunded  %16v", Money(-t.Refunded, t.Currency)),
+			fmt.Sprintf("  Due       %16v", Money(t.Due, t.Currency)),
+		)
+	}
+	return lines
+}
+
+var page = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: monospace; margin: 2em; }
+h1 { font-size: 1.2em; }
+@media print { body { margin: 0; } }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<pre>{{range .Lines}}{{.}}
+{{end}}</pre>
+</body>
+</html>
+`))
+
+//HTML renders the receipt as a printable page.
+func HTML(r *mycabsapi.Receipt) ([]byte, error) {
+	return render("Receipt "+r.ReceiptNo, Lines(r)[2:])
+}
+
+//InvoiceHTML renders the invoice as a printable page.
+func InvoiceHTML(inv *mycabsapi.Invoice) ([]byte, error) {
+	return render("Invoice "+inv.InvoiceNo, InvoiceLines(inv)[2:])
+}
+
+//StatementHTML renders the statement as a printable page.
+func StatementHTML(stmt *mycabsapi.Statement) ([]byte, error) {
+	return render("Statement "+stmt.StatementNo, StatementLines(stmt)[2:])
+}
+
+//PDF renders the receipt as a PDF document.
+func PDF(r *mycabsapi.Receipt) []byte {
+	return textPDF(Lines(r))
+}
+
+//InvoicePDF renders the invoice as a PDF document.
+func InvoicePDF(inv *mycabsapi.Invoice) []byte {
+	return textPDF(InvoiceLines(inv))
+}
+
+//StatementPDF renders the statement as a PDF document.
+func StatementPDF(stmt *mycabsapi.Statement) []byte {
+	return textPDF(StatementLines(stmt))
+}
+
+func render(title string, lines []string) ([]byte, error) {
+	var buf bytes.Buffer
+	err := page.Execute(&buf, struct {
+		Title string
+		Lines []string
+	}{title, lines})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
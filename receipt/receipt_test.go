@@ -0,0 +1,124 @@
+package receipt
+
+import (
+	"bytes"
+	"mycabs/mycabsapi"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func testReceipt() *mycabsapi.Receipt {
+	return &mycabsapi.Receipt{
+		ReceiptNo: "RCPT-trip_1",
+		TripID:    "trip_1",
+		RiderID:   "rider_1",
+		RiderName: "asha <a>",
+		CabName:   "KA01 (blue)",
+		CabType:   "sedan",
+		FromName:  "Bangalore",
+		ToName:    "Mysore",
+		Distance:  10500,
+		Duration:  1200,
+		Fare: &mycabsapi.Fare{
+			Currency: "INR", Base: 5000, Distance: 12600, Time: 3000, InterCity: 30000, Total: 50600, Multiplier: 1,
+		},
+		TaxName: "GST",
+		TaxRate: 0.05,
+		Tax:     2410,
+	}
+}
+
+func TestMoney(t *testing.T) {
+	t.Log("TestMoney")
+
+	if m := Money(20605, "INR"); m != "206.05 INR" {
+		t.Fatalf("TestMoney Expected: 206.05 INR. Actual: %v", m)
+		return
+	}
+	if m := Money(-5, "USD"); m != "-0.05 USD" {
+		t.Fatalf("TestMoney Expected: -0.05 USD. Actual: %v", m)
+		return
+	}
+}
+
+func TestHTML(t *testing.T) {
+	t.Log("TestHTML")
+
+	page, err := HTML(testReceipt())
+	if err != nil {
+		t.Fatalf("TestHTML Failed. Err: %v", err)
+		return
+	}
+	for _, want := range []string{"Receipt RCPT-trip_1", "506.00 INR", "incl. GST 5.0%", "asha &lt;a&gt;"} {
+		if !bytes.Contains(page, []byte(want)) {
+			t.Fatalf("TestHTML Expected %q in the page:\n%s", want, page)
+			return
+		}
+	}
+}
+
+func TestPDF(t *testing.T) {
+	t.Log("TestPDF")
+
+	doc := PDF(testReceipt())
+	if !bytes.HasPrefix(doc, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(doc, []byte("%%EOF\n")) {
+		t.Fatalf("TestPDF Not a PDF document:\n%s", doc)
+		return
+	}
+	if !bytes.Contains(doc, []byte(`(Cab:       KA01 \(blue\) \(sedan\)) '`)) {
+		t.Fatalf("TestPDF Expected the escaped cab line:\n%s", doc)
+		return
+	}
+
+	//The xref must point at the objects.
+	text := string(doc)
+	xref := strings.Index(text, "\nxref\n") + 1
+	xrefLines := strings.Split(text[xref:], "\n")
+	offset, err := strconv.Atoi(xrefLines[3][:10])
+	if xrefLines[1] != "0 6" || err != nil || !strings.HasPrefix(text[offset:], "1 0 obj") {
+		t.Fatalf("TestPDF Invalid xref: %q", xrefLines[:4])
+		return
+	}
+}
+
+func TestPDFPages(t *testing.T) {
+	t.Log("TestPDFPages")
+
+	inv := &mycabsapi.Invoice{InvoiceNo: "INV-rider_1-202401", RiderID: "rider_1", Month: "2024-01"}
+	for i := 0; i < 120; i++ {
+		inv.Receipts = append(inv.Receipts, testReceipt())
+	}
+	doc := InvoicePDF(inv)
+	if !bytes.Contains(doc, []byte("/Count 3")) {
+		t.Fatalf("TestPDFPages Expected 3 pages")
+		return
+	}
+}
+
+func TestStatementLines(t *testing.T) {
+	t.Log("TestStatementLines")
+
+	rcpt := testReceipt()
+	rcpt.Refunded = 600
+	stmt := &mycabsapi.Statement{
+		StatementNo:   "STMT-corporate_1-202401",
+		CorporateID:   "corporate_1",
+		CorporateName: "Acme",
+		Month:         "2024-01",
+		Receipts:      []*mycabsapi.Receipt{rcpt},
+		Members: []*mycabsapi.StatementMember{
+			{RiderID: "rider_1", RiderName: "asha", Trips: 1, Due: map[string]int64{"INR": 50000}},
+		},
+		Totals: []*mycabsapi.InvoiceTotal{
+			{Currency: "INR", Trips: 1, Fare: 50600, Tax: 2410, Refunded: 600, Due: 50000},
+		},
+	}
+	text := strings.Join(StatementLines(stmt), "\n")
+	for _, want := range []string{"Account: Acme (corporate_1)", "asha (rider_1)", "1 trips  500.00 INR", "  Due             500.00 INR"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("TestStatementLines Expected %q in:\n%s", want, text)
+			return
+		}
+	}
+}
@@ -0,0 +1,61 @@
+/*
+ * package surge computes the price multiplier of a city and cab type from its
+ * recent bookings (demand) and its idle cabs (supply).
+ */
+
+package surge
+
+import (
+	"math"
+)
+
+//Params ...
+type Params struct {
+	Threshold   float64 //Bookings per idle cab in the window before surge starts.
+	Sensitivity float64 //Multiplier added per booking per idle cab above Threshold.
+	Cap         float64 //Highest multiplier.
+	Smoothing   float64 //Weight of the new target against the previous multiplier, 0 to 1.
+}
+
+//Default ...
+var Default = Params{
+	Threshold:   1,
+	Sensitivity: 0.5,
+	Cap:         3,
+	Smoothing:   0.5,
+}
+
+//step is the resolution of the multipliers shown to riders.
+const step = 0.1
+
+//Target is the multiplier for demand bookings on supply idle cabs, without smoothing.
+func (p *Params) Target(demand, supply int) float64 {
+	if supply < 1 {
+		supply = 1
+	}
+	ratio := float64(demand) / float64(supply)
+	return p.clamp(1 + p.Sensitivity*(ratio-p.Threshold))
+}
+
+//Next moves the previous multiplier towards the target for demand and supply,
+//so that the multiplier does not jump with every booking.
+func (p *Params) Next(prev float64, demand, supply int) float64 {
+	prev = p.clamp(prev)
+	target := p.Target(demand, supply)
+	next := prev + p.Smoothing*(target-prev)
+	if math.Abs(target-next) < step {
+		//Close enough, settle instead of creeping towards it forever.
+		next = target
+	}
+	return p.clamp(math.Round(next/step) * step)
+}
+
+func (p *Params) clamp(multiplier float64) float64 {
+	if multiplier < 1 {
+		return 1
+	}
+	if multiplier > p.Cap {
+		return p.Cap
+	}
+	return multiplier
+}
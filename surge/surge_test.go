@@ -0,0 +1,50 @@
+package surge
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTarget(t *testing.T) {
+	t.Log("TestTarget")
+
+	p := Default
+	if m := p.Target(5, 10); m != 1 {
+		t.Fatalf("TestTarget Expected no surge below threshold. Actual: %v", m)
+		return
+	}
+	//3 bookings per idle cab: 1 + 0.5 * (3 - 1)
+	if m := p.Target(30, 10); m != 2 {
+		t.Fatalf("TestTarget Expected: 2. Actual: %v", m)
+		return
+	}
+	if m := p.Target(100, 0); m != p.Cap {
+		t.Fatalf("TestTarget Expected cap %v with no idle cab. Actual: %v", p.Cap, m)
+		return
+	}
+}
+
+func TestNext(t *testing.T) {
+	t.Log("TestNext")
+
+	p := Default
+	m := p.Next(1, 30, 10)
+	if math.Abs(m-1.5) > 1e-9 {
+		t.Fatalf("TestNext Expected half way to 2: 1.5. Actual: %v", m)
+		return
+	}
+	for i := 0; i < 10; i++ {
+		m = p.Next(m, 30, 10)
+	}
+	if math.Abs(m-2) > 1e-9 {
+		t.Fatalf("TestNext Expected to settle at 2. Actual: %v", m)
+		return
+	}
+	for i := 0; i < 10; i++ {
+		m = p.Next(m, 0, 10)
+	}
+	if m != 1 {
+		t.Fatalf("TestNext Expected to settle back at 1. Actual: %v", m)
+		return
+	}
+}
@@ -16,18 +16,94 @@ func port() string {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		os.Exit(runImport(os.Args[2:]))
+	}
+
 	fmt.Println("MyCabs Webserver running....")
 	fmt.Printf("Port: %v", port())
 
 	http.HandleFunc("/api/OnboardCity", mycabsservice.OnboardCityHandler)
+	http.HandleFunc("/api/CityDispatch", mycabsservice.CityDispatchHandler)
+	http.HandleFunc("/api/SetTariff", mycabsservice.SetTariffHandler)
+	http.HandleFunc("/api/Tariff", mycabsservice.TariffHandler)
+	http.HandleFunc("/api/FareEstimate", mycabsservice.FareEstimateHandler)
+	http.HandleFunc("/api/Surge", mycabsservice.SurgeHandler)
+	http.HandleFunc("/api/CitySurge", mycabsservice.CitySurgeHandler)
 	http.HandleFunc("/api/RegisterCab", mycabsservice.RegisterCabHandler)
+	http.HandleFunc("/api/UpdateCabLocation", mycabsservice.UpdateCabLocationHandler)
+	http.HandleFunc("/api/RegisterRider", mycabsservice.RegisterRiderHandler)
+	http.HandleFunc("/api/Rider", mycabsservice.RiderHandler)
+	http.HandleFunc("/api/UpdateRider", mycabsservice.UpdateRiderHandler)
+	http.HandleFunc("/api/RiderTrips", mycabsservice.RiderTripsHandler)
+	http.HandleFunc("/api/RegisterDriver", mycabsservice.RegisterDriverHandler)
+	http.HandleFunc("/api/Driver", mycabsservice.DriverHandler)
+	http.HandleFunc("/api/ClockIn", mycabsservice.ClockInHandler)
+	http.HandleFunc("/api/ClockOut", mycabsservice.ClockOutHandler)
+	http.HandleFunc("/api/DriverReport", mycabsservice.DriverReportHandler)
+	http.HandleFunc("/api/RateDriver", mycabsservice.RateDriverHandler)
+	http.HandleFunc("/api/RateRider", mycabsservice.RateRiderHandler)
+	http.HandleFunc("/api/CabRating", mycabsservice.CabRatingHandler)
+	http.HandleFunc("/api/CityRating", mycabsservice.CityRatingHandler)
+	http.HandleFunc("/api/SettleTrip", mycabsservice.SettleTripHandler)
+	http.HandleFunc("/api/RefundTrip", mycabsservice.RefundTripHandler)
+	http.HandleFunc("/api/Balance", mycabsservice.BalanceHandler)
+	http.HandleFunc("/api/Receipt", mycabsservice.ReceiptHandler)
+	http.HandleFunc("/api/Invoice", mycabsservice.InvoiceHandler)
+	http.HandleFunc("/api/CreatePromo", mycabsservice.CreatePromoHandler)
+	http.HandleFunc("/api/Promo", mycabsservice.PromoHandler)
+	http.HandleFunc("/api/EndPromo", mycabsservice.EndPromoHandler)
+	http.HandleFunc("/api/CreateCorporate", mycabsservice.CreateCorporateHandler)
+	http.HandleFunc("/api/Corporate", mycabsservice.CorporateHandler)
+	http.HandleFunc("/api/SetCorporatePolicy", mycabsservice.SetCorporatePolicyHandler)
+	http.HandleFunc("/api/AddCorporateMember", mycabsservice.AddCorporateMemberHandler)
+	http.HandleFunc("/api/RemoveCorporateMember", mycabsservice.RemoveCorporateMemberHandler)
+	http.HandleFunc("/api/CorporateStatement", mycabsservice.CorporateStatementHandler)
+	http.HandleFunc("/api/SetCabType", mycabsservice.SetCabTypeHandler)
+	http.HandleFunc("/api/CabType", mycabsservice.CabTypeHandler)
+	http.HandleFunc("/api/ListCabTypes", mycabsservice.ListCabTypesHandler)
+	http.HandleFunc("/api/DeleteCabType", mycabsservice.DeleteCabTypeHandler)
+	http.HandleFunc("/api/ListCities", mycabsservice.ListCitiesHandler)
+	http.HandleFunc("/api/City", mycabsservice.CityHandler)
+	http.HandleFunc("/api/RenameCity", mycabsservice.RenameCityHandler)
+	http.HandleFunc("/api/DeactivateCity", mycabsservice.DeactivateCityHandler)
+	http.HandleFunc("/api/ActivateCity", mycabsservice.ActivateCityHandler)
+	http.HandleFunc("/api/SetCitySchedule", mycabsservice.SetCityScheduleHandler)
+	http.HandleFunc("/api/SetCityZones", mycabsservice.SetCityZonesHandler)
+	http.HandleFunc("/api/ServiceAvailable", mycabsservice.ServiceAvailableHandler)
+	http.HandleFunc("/api/SearchCabs", mycabsservice.SearchCabsHandler)
+	http.HandleFunc("/api/UpdateCab", mycabsservice.UpdateCabHandler)
+	http.HandleFunc("/api/DecommissionCab", mycabsservice.DecommissionCabHandler)
+	http.HandleFunc("/api/ImportFleet", mycabsservice.ImportFleetHandler)
+	http.HandleFunc("/api/RecommendRebalance", mycabsservice.RecommendRebalanceHandler)
+	http.HandleFunc("/api/RebalancePlan", mycabsservice.RebalancePlanHandler)
+	http.HandleFunc("/api/ApproveRebalance", mycabsservice.ApproveRebalanceHandler)
+	http.HandleFunc("/api/DemandAnalytics", mycabsservice.DemandAnalyticsHandler)
+	http.HandleFunc("/api/CabLocations", mycabsservice.CabLocationsHandler)
+	http.HandleFunc("/api/CabLocationStream", mycabsservice.CabLocationStreamHandler)
+	http.HandleFunc("/api/TripTrail", mycabsservice.TripTrailHandler)
 	http.HandleFunc("/api/BookCab", mycabsservice.BookCabHandler)
+	http.HandleFunc("/api/BookingTicket", mycabsservice.BookingTicketHandler)
+	http.HandleFunc("/api/CancelBookingTicket", mycabsservice.CancelBookingTicketHandler)
+	http.HandleFunc("/api/ReserveCab", mycabsservice.ReserveCabHandler)
+	http.HandleFunc("/api/ListReservations", mycabsservice.ListReservationsHandler)
+	http.HandleFunc("/api/ModifyReservation", mycabsservice.ModifyReservationHandler)
+	http.HandleFunc("/api/CancelReservation", mycabsservice.CancelReservationHandler)
+	http.HandleFunc("/api/CabArriving", mycabsservice.CabArrivingHandler)
+	http.HandleFunc("/api/PickupRider", mycabsservice.PickupRiderHandler)
 	http.HandleFunc("/api/EndTrip", mycabsservice.EndTripHandler)
+	http.HandleFunc("/api/Trip", mycabsservice.TripHandler)
+	http.HandleFunc("/api/CancelTrip", mycabsservice.CancelTripHandler)
 	http.HandleFunc("/api/DeActivateCab", mycabsservice.DeActivateCabHandler)
 	http.HandleFunc("/api/ActivateCab", mycabsservice.ActivateCabHandler)
 	http.HandleFunc("/api/ChangeCity", mycabsservice.ChangeCityHandler)
+	http.HandleFunc("/api/SendToMaintenance", mycabsservice.SendToMaintenanceHandler)
+	http.HandleFunc("/api/ReturnFromMaintenance", mycabsservice.ReturnFromMaintenanceHandler)
 	http.HandleFunc("/api/DemandedCity", mycabsservice.DemandCityHandler)
 	http.HandleFunc("/api/CabHistory", mycabsservice.CabHistoryHandler)
 
+	mycabsservice.RunMigrations()
+	go mycabsservice.RunReservationScheduler()
+
 	http.ListenAndServe(port(), nil)
 }